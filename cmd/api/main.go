@@ -3,8 +3,11 @@ package main
 import (
 	"fmt"
 	"go-blog/internal/server"
+	"log"
 	"os"
+	"os/signal"
 	"strconv"
+	"syscall"
 
 	_ "github.com/joho/godotenv/autoload"
 )
@@ -15,9 +18,25 @@ func main() {
 
 	server.RegisterFiberRoutes()
 	server.RegisterFiberMiddlewares()
+
+	// Shut down gracefully so buffered work such as post views gets flushed. Listen
+	// returns as soon as the server stops listening, before the shutdown hooks have run,
+	// so main waits for done.
+	done := make(chan struct{})
+	go func() {
+		quit := make(chan os.Signal, 1)
+		signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+		<-quit
+		if err := server.Shutdown(); err != nil {
+			log.Printf("error shutting down server: %v", err)
+		}
+		close(done)
+	}()
+
 	port, _ := strconv.Atoi(os.Getenv("PORT"))
 	err := server.Listen(fmt.Sprintf(":%d", port))
 	if err != nil {
 		panic(fmt.Sprintf("cannot start server: %s", err))
 	}
+	<-done
 }
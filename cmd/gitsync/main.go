@@ -0,0 +1,83 @@
+// Command gitsync syncs the posts with the Markdown files of a branch of a local git
+// repository, as the /api/sync/git webhook does.
+//
+//	go run cmd/gitsync/main.go -branch main -author admin@example.com ../blog-content
+//
+// The repository defaults to GIT_SYNC_REPO. Nothing is fetched, pull the repository
+// first to sync with a remote.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go-blog/internal/database"
+	"go-blog/internal/repository"
+	"go-blog/internal/service"
+	"go-blog/internal/types"
+	"log"
+	"os"
+
+	_ "github.com/joho/godotenv/autoload"
+)
+
+func main() {
+	branch := flag.String("branch", envOr("GIT_SYNC_BRANCH", "main"), "branch to sync")
+	authorEmail := flag.String("author", os.Getenv("GIT_SYNC_AUTHOR"), "email of the user credited with new posts whose author isn't a user")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [repository]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	repoPath := os.Getenv("GIT_SYNC_REPO")
+	if flag.NArg() == 1 {
+		repoPath = flag.Arg(0)
+	}
+	if repoPath == "" || flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	db := database.New().GetInstance()
+	userRepository := repository.NewUserRepository(db)
+
+	var fallbackAuthor *types.User
+	if *authorEmail != "" {
+		var err error
+		fallbackAuthor, err = userRepository.FindByEmail(*authorEmail)
+		if err != nil {
+			log.Fatalf("cannot find author %s: %v", *authorEmail, err)
+		}
+	}
+
+	syncService := service.NewGitSyncService(
+		repository.NewGitSyncRepository(db),
+		repository.NewPostRepository(db),
+		repository.NewCategoryRepository(db),
+		userRepository,
+		service.NewSlugService(),
+	)
+
+	report, err := syncService.Sync(repoPath, *branch, fallbackAuthor)
+	if err != nil {
+		log.Fatalf("sync failed: %v", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatal(err)
+	}
+
+	if len(report.Errors) > 0 {
+		os.Exit(1)
+	}
+}
+
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,85 @@
+// Command wpimport imports a WordPress WXR export into the blog database.
+//
+//	go run cmd/wpimport/main.go -uploads ./wp-content/uploads -author admin@example.com export.xml
+//
+// Run it from the project directory, imported attachments are saved under its uploads
+// directory. Running it again with the same export only imports what's new or failed.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go-blog/internal/database"
+	"go-blog/internal/repository"
+	"go-blog/internal/service"
+	"go-blog/internal/types"
+	"log"
+	"os"
+
+	_ "github.com/joho/godotenv/autoload"
+)
+
+func main() {
+	uploadsDir := flag.String("uploads", envOr("WORDPRESS_UPLOADS_DIR", "wp-content/uploads"), "copy of the wp-content/uploads directory of the site")
+	authorEmail := flag.String("author", "", "email of the user credited with posts whose author can't be imported")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] export.xml\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	wxr, err := os.Open(flag.Arg(0))
+	if err != nil {
+		log.Fatalf("cannot open export: %v", err)
+	}
+	defer wxr.Close()
+
+	db := database.New().GetInstance()
+	userRepository := repository.NewUserRepository(db)
+
+	var fallbackAuthor *types.User
+	if *authorEmail != "" {
+		fallbackAuthor, err = userRepository.FindByEmail(*authorEmail)
+		if err != nil {
+			log.Fatalf("cannot find author %s: %v", *authorEmail, err)
+		}
+	}
+
+	importService := service.NewWordPressImportService(
+		repository.NewWordPressImportRepository(db),
+		userRepository,
+		repository.NewCategoryRepository(db),
+		repository.NewPostRepository(db),
+		repository.NewFileRepository(db),
+		service.NewFileService(),
+		service.NewSlugService(),
+	)
+
+	report, err := importService.Import(wxr, *uploadsDir, fallbackAuthor)
+	if err != nil {
+		log.Fatalf("import failed: %v", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatal(err)
+	}
+
+	if len(report.Errors) > 0 {
+		os.Exit(1)
+	}
+}
+
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
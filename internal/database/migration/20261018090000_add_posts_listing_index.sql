@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX idx_posts_created_at_id ON posts (created_at DESC, id DESC);
+CREATE INDEX idx_post_categories_category_id ON post_categories (category_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_post_categories_category_id;
+DROP INDEX idx_posts_created_at_id;
+-- +goose StatementEnd
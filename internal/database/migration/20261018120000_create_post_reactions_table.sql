@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE post_reactions (
+    post_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    type VARCHAR(20) NOT NULL
+        CHECK (type IN ('like', 'heart', 'laugh', 'wow', 'sad', 'clap')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (post_id, user_id, type),
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_post_reactions_user_id ON post_reactions (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE post_reactions;
+-- +goose StatementEnd
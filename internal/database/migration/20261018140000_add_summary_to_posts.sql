@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE posts
+    ADD COLUMN excerpt TEXT NOT NULL DEFAULT '',
+    ADD COLUMN word_count INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN reading_time_minutes INTEGER NOT NULL DEFAULT 0;
+
+-- Rough backfill, the exact values are computed by the application on the next save
+UPDATE posts SET
+    word_count = COALESCE(array_length(regexp_split_to_array(btrim(content), '\s+'), 1), 0),
+    excerpt = LEFT(btrim(regexp_replace(split_part(btrim(content), E'\n\n', 1), '[#*_`>\[\]]', '', 'g')), 200);
+
+UPDATE posts SET reading_time_minutes = CEIL(word_count / 200.0) WHERE word_count > 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE posts
+    DROP COLUMN excerpt,
+    DROP COLUMN word_count,
+    DROP COLUMN reading_time_minutes;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE posts
+    ADD COLUMN meta_title VARCHAR(70) NOT NULL DEFAULT '',
+    ADD COLUMN meta_description VARCHAR(160) NOT NULL DEFAULT '',
+    ADD COLUMN canonical_url TEXT NOT NULL DEFAULT '',
+    ADD COLUMN og_image TEXT NOT NULL DEFAULT '',
+    ADD COLUMN noindex BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE posts
+    DROP COLUMN meta_title,
+    DROP COLUMN meta_description,
+    DROP COLUMN canonical_url,
+    DROP COLUMN og_image,
+    DROP COLUMN noindex;
+-- +goose StatementEnd
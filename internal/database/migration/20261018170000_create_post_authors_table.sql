@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE post_authors (
+    post_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    role VARCHAR(20) NOT NULL DEFAULT 'author'
+        CHECK (role IN ('author', 'contributor', 'editor')),
+    position INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (post_id, user_id),
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_post_authors_user_id ON post_authors (user_id);
+
+-- posts.user_id stays the owner, who is also the first author
+INSERT INTO post_authors (post_id, user_id, role, position)
+SELECT id, user_id, 'author', 0 FROM posts;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE post_authors;
+-- +goose StatementEnd
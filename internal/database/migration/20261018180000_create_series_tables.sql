@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE series (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    title VARCHAR(200) NOT NULL,
+    slug VARCHAR(250) NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- A post belongs to at most one series
+CREATE TABLE series_posts (
+    series_id UUID NOT NULL REFERENCES series(id) ON DELETE CASCADE,
+    post_id UUID NOT NULL UNIQUE REFERENCES posts(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    PRIMARY KEY (series_id, post_id)
+);
+
+CREATE INDEX idx_series_posts_position ON series_posts (series_id, position);
+
+CREATE TABLE series_slug_history (
+    slug VARCHAR(250) PRIMARY KEY,
+    series_id UUID NOT NULL REFERENCES series(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_series_slug_history_series_id ON series_slug_history(series_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS series_slug_history;
+DROP TABLE IF EXISTS series_posts;
+DROP TABLE IF EXISTS series;
+-- +goose StatementEnd
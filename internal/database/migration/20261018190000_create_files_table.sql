@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE files (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    filename VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL DEFAULT '',
+    size BIGINT NOT NULL DEFAULT 0,
+    width INT NOT NULL DEFAULT 0,
+    height INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, filename)
+);
+
+-- A file can't be deleted while a post uses it as its cover
+ALTER TABLE posts
+    ADD COLUMN cover_image_id UUID REFERENCES files(id) ON DELETE RESTRICT,
+    ADD COLUMN cover_image_alt VARCHAR(250) NOT NULL DEFAULT '';
+
+CREATE INDEX idx_posts_cover_image_id ON posts (cover_image_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE posts
+    DROP COLUMN IF EXISTS cover_image_alt,
+    DROP COLUMN IF EXISTS cover_image_id;
+
+DROP TABLE IF EXISTS files;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE posts ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE categories ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_posts_deleted_at ON posts (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_categories_deleted_at ON categories (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Purging a post or a category from the trash drops its assignments
+ALTER TABLE post_categories
+    DROP CONSTRAINT post_categories_post_id_fkey,
+    DROP CONSTRAINT post_categories_category_id_fkey,
+    ADD CONSTRAINT post_categories_post_id_fkey FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    ADD CONSTRAINT post_categories_category_id_fkey FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE post_categories
+    DROP CONSTRAINT post_categories_post_id_fkey,
+    DROP CONSTRAINT post_categories_category_id_fkey,
+    ADD CONSTRAINT post_categories_post_id_fkey FOREIGN KEY (post_id) REFERENCES posts(id),
+    ADD CONSTRAINT post_categories_category_id_fkey FOREIGN KEY (category_id) REFERENCES categories(id);
+
+DROP INDEX IF EXISTS idx_categories_deleted_at;
+DROP INDEX IF EXISTS idx_posts_deleted_at;
+
+ALTER TABLE categories DROP COLUMN deleted_at;
+ALTER TABLE posts DROP COLUMN deleted_at;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Trigram similarity of titles is part of the related posts score
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_posts_title_trgm ON posts USING GIN (title gin_trgm_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_posts_title_trgm;
+-- +goose StatementEnd
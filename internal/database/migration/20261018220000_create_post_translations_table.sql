@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The language the post itself is written in
+ALTER TABLE posts ADD COLUMN locale VARCHAR(10) NOT NULL DEFAULT 'en';
+
+-- Slugs only have to be unique within a language
+ALTER TABLE posts DROP CONSTRAINT posts_slug_key;
+ALTER TABLE posts ADD CONSTRAINT posts_locale_slug_key UNIQUE (locale, slug);
+
+CREATE TABLE post_translations (
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    locale VARCHAR(10) NOT NULL,
+    title VARCHAR(200) NOT NULL,
+    slug VARCHAR(250) NOT NULL,
+    content TEXT NOT NULL,
+    excerpt TEXT NOT NULL DEFAULT '',
+    word_count INTEGER NOT NULL DEFAULT 0,
+    reading_time_minutes INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (post_id, locale),
+    UNIQUE (locale, slug)
+);
+
+CREATE INDEX idx_post_translations_slug ON post_translations (slug);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS post_translations;
+
+ALTER TABLE posts DROP CONSTRAINT IF EXISTS posts_locale_slug_key;
+ALTER TABLE posts ADD CONSTRAINT posts_slug_key UNIQUE (slug);
+ALTER TABLE posts DROP COLUMN IF EXISTS locale;
+-- +goose StatementEnd
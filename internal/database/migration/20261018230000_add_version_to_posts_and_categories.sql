@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The version is bumped by every change, updates only apply to the version the client read
+ALTER TABLE posts
+    ADD COLUMN version INTEGER NOT NULL DEFAULT 1,
+    ADD COLUMN updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP;
+
+UPDATE posts SET updated_at = created_at;
+
+ALTER TABLE categories
+    ADD COLUMN version INTEGER NOT NULL DEFAULT 1,
+    ADD COLUMN updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP;
+
+UPDATE categories SET updated_at = created_at;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE categories
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS version;
+
+ALTER TABLE posts
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS version;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Posts written before statuses existed were all public
+ALTER TABLE posts
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'published'
+        CONSTRAINT posts_status_check CHECK (status IN ('draft', 'published'));
+
+CREATE INDEX idx_posts_status_created_at ON posts (status, created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_posts_status_created_at;
+
+ALTER TABLE posts
+    DROP COLUMN IF EXISTS status;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Categories may be nested, as they are in WordPress
+ALTER TABLE categories
+    ADD COLUMN parent_id UUID REFERENCES categories(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_categories_parent_id ON categories (parent_id);
+
+-- What each WordPress id was imported as, so running an import again skips it
+CREATE TABLE wordpress_imports (
+    kind VARCHAR(20) NOT NULL,
+    wp_id BIGINT NOT NULL,
+    local_id UUID NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (kind, wp_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS wordpress_imports;
+
+DROP INDEX IF EXISTS idx_categories_parent_id;
+
+ALTER TABLE categories
+    DROP COLUMN IF EXISTS parent_id;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Posts whose file is removed from the synced git repository are archived
+ALTER TABLE posts
+    DROP CONSTRAINT posts_status_check,
+    ADD CONSTRAINT posts_status_check CHECK (status IN ('draft', 'published', 'archived'));
+
+-- The post each Markdown file of the synced git repository was imported as
+CREATE TABLE git_synced_posts (
+    path TEXT PRIMARY KEY,
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    blob_sha VARCHAR(64) NOT NULL,
+    commit_sha VARCHAR(64) NOT NULL,
+    synced_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_git_synced_posts_post_id ON git_synced_posts (post_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS git_synced_posts;
+
+UPDATE posts SET status = 'draft' WHERE status = 'archived';
+
+ALTER TABLE posts
+    DROP CONSTRAINT posts_status_check,
+    ADD CONSTRAINT posts_status_check CHECK (status IN ('draft', 'published'));
+-- +goose StatementEnd
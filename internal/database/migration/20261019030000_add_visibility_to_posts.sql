@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Who may read a post besides its authors and editors, password protected posts keep
+-- the bcrypt hash of their password
+ALTER TABLE posts
+    ADD COLUMN visibility VARCHAR(20) NOT NULL DEFAULT 'public'
+        CONSTRAINT posts_visibility_check CHECK (visibility IN ('public', 'unlisted', 'password', 'members')),
+    ADD COLUMN password_hash VARCHAR(255),
+    ADD CONSTRAINT posts_password_hash_check CHECK (visibility <> 'password' OR password_hash IS NOT NULL);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE posts
+    DROP CONSTRAINT IF EXISTS posts_password_hash_check,
+    DROP COLUMN IF EXISTS password_hash,
+    DROP COLUMN IF EXISTS visibility;
+-- +goose StatementEnd
@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Posts of authors go through review before being published, the reviewer is the
+-- editor assigned to it
+ALTER TABLE posts
+    DROP CONSTRAINT posts_status_check,
+    ADD CONSTRAINT posts_status_check
+        CHECK (status IN ('draft', 'in_review', 'changes_requested', 'approved', 'published', 'archived')),
+    ADD COLUMN reviewer_id UUID REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_posts_status_updated_at ON posts (status, updated_at);
+
+-- The review history of a post, the status its authors and reviewers moved it to or left
+-- it at, with their comments
+CREATE TABLE post_reviews (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id),
+    status VARCHAR(20) NOT NULL,
+    comment TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_post_reviews_post_id_created_at ON post_reviews (post_id, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS post_reviews;
+
+DROP INDEX IF EXISTS idx_posts_status_updated_at;
+
+UPDATE posts SET status = 'draft' WHERE status IN ('in_review', 'changes_requested', 'approved');
+
+ALTER TABLE posts
+    DROP COLUMN IF EXISTS reviewer_id,
+    DROP CONSTRAINT posts_status_check,
+    ADD CONSTRAINT posts_status_check CHECK (status IN ('draft', 'published', 'archived'));
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Links sharing a post before it is published, revoked by deleting them
+CREATE TABLE post_preview_links (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_post_preview_links_post_id ON post_preview_links (post_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS post_preview_links;
+-- +goose StatementEnd
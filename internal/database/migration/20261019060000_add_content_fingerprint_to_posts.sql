@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- SimHash of the normalised content of a post, near duplicates differ in few bits.
+-- Posts saved before are fingerprinted the first time duplicates are looked for.
+ALTER TABLE posts
+    ADD COLUMN content_fingerprint BIGINT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE posts
+    DROP COLUMN IF EXISTS content_fingerprint;
+-- +goose StatementEnd
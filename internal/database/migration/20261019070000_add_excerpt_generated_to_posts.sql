@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Generated excerpts follow the content when it changes, given ones are kept. Posts saved
+-- before keep their excerpt as if it was given.
+ALTER TABLE posts
+    ADD COLUMN excerpt_generated BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE posts
+    DROP COLUMN IF EXISTS excerpt_generated;
+-- +goose StatementEnd
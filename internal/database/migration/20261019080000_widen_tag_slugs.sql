@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Transliterated names of 50 characters make slugs of up to service.MaxSlugLength, as
+-- wide as the slugs of posts and categories.
+ALTER TABLE tags
+    ALTER COLUMN slug TYPE VARCHAR(250);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE tags
+    ALTER COLUMN slug TYPE VARCHAR(60);
+-- +goose StatementEnd
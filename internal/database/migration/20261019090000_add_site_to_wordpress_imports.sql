@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Every WordPress site numbers its objects from 1, so imports are told apart by site.
+-- What was imported before is kept under an unknown site.
+ALTER TABLE wordpress_imports
+    ADD COLUMN site VARCHAR(2048) NOT NULL DEFAULT '';
+
+ALTER TABLE wordpress_imports
+    DROP CONSTRAINT wordpress_imports_pkey,
+    ADD PRIMARY KEY (site, kind, wp_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM wordpress_imports
+    WHERE site <> '';
+
+ALTER TABLE wordpress_imports
+    DROP CONSTRAINT wordpress_imports_pkey,
+    ADD PRIMARY KEY (kind, wp_id);
+
+ALTER TABLE wordpress_imports
+    DROP COLUMN IF EXISTS site;
+-- +goose StatementEnd
@@ -1,11 +1,11 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"go-blog/internal/repository"
+	"go-blog/internal/service"
 	"go-blog/internal/types"
-	"regexp"
-	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -15,15 +15,18 @@ type CategoryHandler interface {
 	GetCategoryHandler(c *fiber.Ctx) error
 	CreateCategoryHandler(c *fiber.Ctx) error
 	UpdateCategoryHandler(c *fiber.Ctx) error
+	PatchCategoryHandler(c *fiber.Ctx) error
 	DeleteCategoryHandler(c *fiber.Ctx) error
 }
 
 type categoryHandler struct {
 	categoryRepository repository.CategoryRepository
+	slugService        service.SlugService
+	relatedPostService service.RelatedPostService
 }
 
-func NewCategoryHandler(categoryRepository repository.CategoryRepository) CategoryHandler {
-	return &categoryHandler{categoryRepository}
+func NewCategoryHandler(categoryRepository repository.CategoryRepository, slugService service.SlugService, relatedPostService service.RelatedPostService) CategoryHandler {
+	return &categoryHandler{categoryRepository, slugService, relatedPostService}
 }
 
 func (h *categoryHandler) GetCategoryHandler(c *fiber.Ctx) error {
@@ -51,6 +54,14 @@ func (h *categoryHandler) GetCategoryHandler(c *fiber.Ctx) error {
 			}
 		}
 
+		if category.Slug != slugOrId && category.Id != slugOrId {
+			return redirectToSlug(c, slugOrId, category.Slug)
+		}
+
+		if notModified(c, entityTag(category.Version)) {
+			return c.SendStatus(fiber.StatusNotModified)
+		}
+
 		return c.JSON(category)
 	}
 
@@ -81,11 +92,14 @@ func (h *categoryHandler) CreateCategoryHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	// Generate slug
-	slug := strings.ToLower(category.Title)
-	reg, _ := regexp.Compile("[^a-z0-9]+")
-	slug = reg.ReplaceAllString(slug, "-")
-	category.Slug = strings.Trim(slug, "-")
+	slug, err := resolveSlug(h.slugService, category.Slug, "", category.Title, "", "")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": fiber.Map{"Slug": err.Error()},
+		})
+	}
+	category.Slug = slug
 
 	createdCategory, err := h.categoryRepository.Create(category)
 	if err != nil {
@@ -116,7 +130,7 @@ func (h *categoryHandler) UpdateCategoryHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	_, err := h.categoryRepository.FindById(id)
+	existingCategory, err := h.categoryRepository.FindById(id)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error":   "Category not found",
@@ -124,12 +138,30 @@ func (h *categoryHandler) UpdateCategoryHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	slug := strings.ToLower(category.Title)
-	reg, _ := regexp.Compile("[^a-z0-9]+")
-	slug = reg.ReplaceAllString(slug, "-")
-	category.Slug = strings.Trim(slug, "-")
+	if ok, err := checkIfMatch(c, entityTag(existingCategory.Version)); !ok {
+		return err
+	}
+	category.Version = existingCategory.Version
+
+	// Former slugs keep redirecting to the category
+	category.Slug, err = resolveSlug(h.slugService, category.Slug, existingCategory.Slug, category.Title, existingCategory.Title, "")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": fiber.Map{"Slug": err.Error()},
+		})
+	}
 
 	updatedCategory, err := h.categoryRepository.Update(id, category)
+	if errors.Is(err, repository.ErrVersionConflict) {
+		return preconditionFailed(c)
+	}
+	if errors.Is(err, repository.ErrCategoryCycle) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": fiber.Map{"ParentId": "cycle"},
+		})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error":   "Failed to update category",
@@ -137,6 +169,65 @@ func (h *categoryHandler) UpdateCategoryHandler(c *fiber.Ctx) error {
 		})
 	}
 
+	c.Set(fiber.HeaderETag, entityTag(updatedCategory.Version))
+	return c.JSON(updatedCategory)
+}
+
+// PatchCategoryHandler applies a JSON merge patch to the title, slug or parent of the category.
+func (h *categoryHandler) PatchCategoryHandler(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	existingCategory, err := h.categoryRepository.FindById(id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "Category not found",
+			"message": fmt.Sprintf("No category found with ID: %s", id),
+		})
+	}
+
+	if ok, err := checkIfMatch(c, entityTag(existingCategory.Version)); !ok {
+		return err
+	}
+
+	var category types.Category
+	if _, ok, err := mergePatch(c, existingCategory, []string{"title", "slug", "parentId"}, &category); !ok {
+		return err
+	}
+
+	if err := category.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": err,
+		})
+	}
+	category.Version = existingCategory.Version
+
+	category.Slug, err = resolveSlug(h.slugService, category.Slug, existingCategory.Slug, category.Title, existingCategory.Title, "")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": fiber.Map{"Slug": err.Error()},
+		})
+	}
+
+	updatedCategory, err := h.categoryRepository.Patch(id, category)
+	if errors.Is(err, repository.ErrVersionConflict) {
+		return preconditionFailed(c)
+	}
+	if errors.Is(err, repository.ErrCategoryCycle) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": fiber.Map{"ParentId": "cycle"},
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to update category",
+			"message": fmt.Sprintf("Error occurred while updating category: %v", err),
+		})
+	}
+
+	c.Set(fiber.HeaderETag, entityTag(updatedCategory.Version))
 	return c.JSON(updatedCategory)
 }
 
@@ -158,5 +249,8 @@ func (h *categoryHandler) DeleteCategoryHandler(c *fiber.Ctx) error {
 		})
 	}
 
+	// Posts no longer share the trashed category
+	h.relatedPostService.Invalidate()
+
 	return c.SendStatus(fiber.StatusNoContent)
 }
@@ -0,0 +1,335 @@
+package handler
+
+import (
+	"fmt"
+	"go-blog/internal/repository"
+	"go-blog/internal/service"
+	"go-blog/internal/types"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const defaultCommentEditWindow = 15 * time.Minute
+
+type CommentHandler interface {
+	GetCommentsHandler(c *fiber.Ctx) error
+	CreateCommentHandler(c *fiber.Ctx) error
+	UpdateCommentHandler(c *fiber.Ctx) error
+	DeleteCommentHandler(c *fiber.Ctx) error
+	GetModerationQueueHandler(c *fiber.Ctx) error
+	ModerateCommentHandler(c *fiber.Ctx) error
+}
+
+type commentHandler struct {
+	commentRepository repository.CommentRepository
+	postRepository    repository.PostRepository
+	postUnlockService service.PostUnlockService
+	editWindow        time.Duration
+}
+
+func NewCommentHandler(commentRepository repository.CommentRepository, postRepository repository.PostRepository, postUnlockService service.PostUnlockService) CommentHandler {
+	editWindow, err := time.ParseDuration(os.Getenv("COMMENT_EDIT_WINDOW"))
+	if err != nil || editWindow <= 0 {
+		editWindow = defaultCommentEditWindow
+	}
+
+	return &commentHandler{
+		commentRepository: commentRepository,
+		postRepository:    postRepository,
+		postUnlockService: postUnlockService,
+		editWindow:        editWindow,
+	}
+}
+
+// buildCommentTree nests replies under their parents. Deleted comments are kept as
+// empty placeholders while they still have visible replies, and dropped otherwise.
+func buildCommentTree(comments []types.Comment) []types.Comment {
+	children := make(map[string][]types.Comment)
+	for _, comment := range comments {
+		children[comment.ParentId] = append(children[comment.ParentId], comment)
+	}
+
+	var build func(parentId string) []types.Comment
+	build = func(parentId string) []types.Comment {
+		thread := []types.Comment{}
+		for _, comment := range children[parentId] {
+			comment.Replies = build(comment.Id)
+			if comment.Status == types.CommentDeleted {
+				if len(comment.Replies) == 0 {
+					continue
+				}
+				comment.Content = ""
+				comment.Author = types.User{}
+			}
+			thread = append(thread, comment)
+		}
+		return thread
+	}
+
+	return build("")
+}
+
+func (h *commentHandler) GetCommentsHandler(c *fiber.Ctx) error {
+	postId := c.Params("postId")
+
+	if _, ok, err := findReadablePost(c, h.postRepository, h.postUnlockService, postId); !ok {
+		return err
+	}
+
+	comments, err := h.commentRepository.FindByPost(postId, []string{types.CommentApproved, types.CommentDeleted})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to retrieve comments",
+			"message": fmt.Sprintf("Error occurred while fetching comments: %v", err),
+		})
+	}
+
+	return c.JSON(buildCommentTree(comments))
+}
+
+func (h *commentHandler) CreateCommentHandler(c *fiber.Ctx) error {
+	postId := c.Params("postId")
+	var comment types.Comment
+
+	if err := c.BodyParser(&comment); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request payload",
+			"message": fmt.Sprintf("Error parsing comment data: %v", err),
+		})
+	}
+
+	if err := comment.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": err,
+		})
+	}
+
+	user, ok := c.Locals("user").(types.User)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	if _, ok, err := findReadablePost(c, h.postRepository, h.postUnlockService, postId); !ok {
+		return err
+	}
+
+	if comment.ParentId != "" {
+		parent, err := h.commentRepository.FindById(comment.ParentId)
+		if err != nil || parent.PostId != postId || parent.Status != types.CommentApproved {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   "Invalid parent comment",
+				"message": fmt.Sprintf("No approved comment found with ID %s on this post", comment.ParentId),
+			})
+		}
+	}
+
+	comment.PostId = postId
+	comment.Author = user
+	comment.Status = types.CommentPending
+	if user.HasRole(types.RoleEditor) {
+		comment.Status = types.CommentApproved
+	}
+
+	createdComment, err := h.commentRepository.Create(comment)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to create comment",
+			"message": fmt.Sprintf("Error occurred while creating comment: %v", err),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(createdComment)
+}
+
+func (h *commentHandler) UpdateCommentHandler(c *fiber.Ctx) error {
+	id := c.Params("id")
+	var comment types.Comment
+
+	if err := c.BodyParser(&comment); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request payload",
+			"message": fmt.Sprintf("Error parsing comment data: %v", err),
+		})
+	}
+
+	if err := comment.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": err,
+		})
+	}
+
+	user, ok := c.Locals("user").(types.User)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	existingComment, err := h.commentRepository.FindById(id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "Comment not found",
+			"message": fmt.Sprintf("No comment found with ID: %s", id),
+		})
+	}
+
+	if existingComment.Author.Id != user.Id || existingComment.Status == types.CommentDeleted || existingComment.Status == types.CommentSpam {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "You don't have permission to update this comment",
+		})
+	}
+
+	if time.Since(existingComment.CreatedAt) > h.editWindow {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error":   "Edit window has expired",
+			"message": fmt.Sprintf("Comments can only be edited within %s of posting", h.editWindow),
+		})
+	}
+
+	// Edits are moderated like new comments, an approved comment could otherwise be
+	// changed into anything
+	status := existingComment.Status
+	if !user.HasRole(types.RoleEditor) {
+		status = types.CommentPending
+	}
+
+	updatedComment, err := h.commentRepository.Update(id, comment.Content, status)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to update comment",
+			"message": fmt.Sprintf("Error occurred while updating comment: %v", err),
+		})
+	}
+
+	return c.JSON(updatedComment)
+}
+
+func (h *commentHandler) DeleteCommentHandler(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	user, ok := c.Locals("user").(types.User)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	existingComment, err := h.commentRepository.FindById(id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "Comment not found",
+			"message": fmt.Sprintf("No comment found with ID: %s", id),
+		})
+	}
+
+	// Editors can remove any comment, authors only their own within the edit window
+	if !user.HasRole(types.RoleEditor) {
+		if existingComment.Author.Id != user.Id {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "You don't have permission to delete this comment",
+			})
+		}
+
+		if time.Since(existingComment.CreatedAt) > h.editWindow {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error":   "Edit window has expired",
+				"message": fmt.Sprintf("Comments can only be deleted within %s of posting", h.editWindow),
+			})
+		}
+	}
+
+	if _, err := h.commentRepository.UpdateStatus(id, types.CommentDeleted); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to delete comment",
+			"message": fmt.Sprintf("Error occurred while deleting comment: %v", err),
+		})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *commentHandler) GetModerationQueueHandler(c *fiber.Ctx) error {
+	status := c.Query("status", types.CommentPending)
+	if !types.IsCommentStatus(status) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid status",
+			"message": fmt.Sprintf("Unknown comment status: %s", status),
+		})
+	}
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+
+	comments, totalCount, err := h.commentRepository.FindByStatus(status, page, limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to retrieve comments",
+			"message": fmt.Sprintf("Error occurred while fetching comments: %v", err),
+		})
+	}
+
+	totalPages := (totalCount + limit - 1) / limit
+
+	return c.JSON(fiber.Map{
+		"meta": fiber.Map{
+			"page":       page,
+			"limit":      limit,
+			"totalCount": totalCount,
+			"totalPages": totalPages,
+		},
+		"data": comments,
+	})
+}
+
+func (h *commentHandler) ModerateCommentHandler(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var request struct {
+		Status string `json:"status"`
+	}
+
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request payload",
+			"message": fmt.Sprintf("Error parsing request body: %v", err),
+		})
+	}
+
+	if !types.IsCommentStatus(request.Status) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid status",
+			"message": fmt.Sprintf("Unknown comment status: %s", request.Status),
+		})
+	}
+
+	if _, err := h.commentRepository.FindById(id); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "Comment not found",
+			"message": fmt.Sprintf("No comment found with ID: %s", id),
+		})
+	}
+
+	updatedComment, err := h.commentRepository.UpdateStatus(id, request.Status)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to moderate comment",
+			"message": fmt.Sprintf("Error occurred while moderating comment: %v", err),
+		})
+	}
+
+	return c.JSON(updatedComment)
+}
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"fmt"
+	"go-blog/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type DuplicateHandler interface {
+	GetDuplicatesHandler(c *fiber.Ctx) error
+}
+
+type duplicateHandler struct {
+	duplicateService service.DuplicateService
+}
+
+func NewDuplicateHandler(duplicateService service.DuplicateService) DuplicateHandler {
+	return &duplicateHandler{duplicateService}
+}
+
+// GetDuplicatesHandler lists the clusters of posts whose content nearly duplicates one
+// another, such as an article syndicated twice.
+func (h *duplicateHandler) GetDuplicatesHandler(c *fiber.Ctx) error {
+	clusters, err := h.duplicateService.FindClusters()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to retrieve duplicates",
+			"message": fmt.Sprintf("Error occurred while looking for duplicate posts: %v", err),
+		})
+	}
+
+	return c.JSON(clusters)
+}
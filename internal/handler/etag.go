@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// entityTag formats the ETag of a resource version. Parts are appended for
+// representations of the same version that differ, such as the language of a post.
+func entityTag(version int, parts ...string) string {
+	return `"` + strings.Join(append([]string{strconv.Itoa(version)}, parts...), "-") + `"`
+}
+
+// matchesETag reports whether an If-Match or If-None-Match header lists etag. Weak tags
+// are compared as strong ones, since proxies may weaken the tags of compressed responses.
+func matchesETag(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesVersion is matchesETag also matching the tags of the other representations of
+// the same version, which append parts to its tag. They are as fresh for updates.
+func matchesVersion(header, etag string) bool {
+	if matchesETag(header, etag) {
+		return true
+	}
+
+	prefix := strings.TrimSuffix(etag, `"`) + "-"
+	for _, candidate := range strings.Split(header, ",") {
+		if strings.HasPrefix(strings.TrimPrefix(strings.TrimSpace(candidate), "W/"), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified sets the ETag of the response and reports whether the client already has
+// that representation, in which case it should be answered with 304 Not Modified.
+func notModified(c *fiber.Ctx, etag string) bool {
+	c.Set(fiber.HeaderETag, etag)
+
+	header := c.Get(fiber.HeaderIfNoneMatch)
+	return header != "" && matchesETag(header, etag)
+}
+
+// checkIfMatch answers updates whose If-Match header doesn't list the current ETag of
+// the resource with 412 Precondition Failed. Updates without the header go through
+// unless REQUIRE_IF_MATCH is true, then they get 428 Precondition Required. It reports
+// whether the update may go on, when it may not the response has already been sent.
+func checkIfMatch(c *fiber.Ctx, etag string) (ok bool, err error) {
+	header := c.Get(fiber.HeaderIfMatch)
+
+	if header == "" {
+		if os.Getenv("REQUIRE_IF_MATCH") != "true" {
+			return true, nil
+		}
+		return false, c.Status(fiber.StatusPreconditionRequired).JSON(fiber.Map{
+			"error":   "Precondition required",
+			"message": "Send the ETag of the version you are updating in the If-Match header",
+		})
+	}
+
+	if !matchesVersion(header, etag) {
+		c.Set(fiber.HeaderETag, etag)
+		return false, preconditionFailed(c)
+	}
+
+	return true, nil
+}
+
+// preconditionFailed tells the client the version it is updating is outdated.
+func preconditionFailed(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusPreconditionFailed).JSON(fiber.Map{
+		"error":   "Precondition failed",
+		"message": "The resource was changed by someone else, fetch it again and retry",
+	})
+}
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"fmt"
+	"go-blog/internal/repository"
 	"go-blog/internal/service"
 	"go-blog/internal/types"
 	"path/filepath"
@@ -9,7 +11,8 @@ import (
 )
 
 type fileHandler struct {
-	fileService service.FileService
+	fileService    service.FileService
+	fileRepository repository.FileRepository
 }
 
 type FileHandler interface {
@@ -17,8 +20,8 @@ type FileHandler interface {
 	DeleteFileHandler(c *fiber.Ctx) error
 }
 
-func NewFileHandler(fileService service.FileService) FileHandler {
-	return &fileHandler{fileService}
+func NewFileHandler(fileService service.FileService, fileRepository repository.FileRepository) FileHandler {
+	return &fileHandler{fileService, fileRepository}
 }
 
 func (h *fileHandler) UploadFileHandler(c *fiber.Ctx) error {
@@ -46,9 +49,26 @@ func (h *fileHandler) UploadFileHandler(c *fiber.Ctx) error {
 		})
 	}
 
+	width, height := h.fileService.ImageSize(file)
+	savedFile, err := h.fileRepository.Create(types.File{
+		UserId:      user.Id,
+		Filename:    uniqueFilename,
+		ContentType: file.Header.Get(fiber.HeaderContentType),
+		Size:        file.Size,
+		Width:       width,
+		Height:      height,
+	})
+	if err != nil {
+		h.fileService.DeleteFile(uniqueFilename, user)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to save file",
+		})
+	}
+
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message":  "File uploaded successfully",
 		"filename": uniqueFilename,
+		"file":     savedFile,
 	})
 }
 
@@ -62,7 +82,32 @@ func (h *fileHandler) DeleteFileHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	err := h.fileService.DeleteFile(filename, user)
+	// Files uploaded before they were recorded have no row, and no post can use them
+	file, err := h.fileRepository.FindByFilename(user.Id, filename)
+	if err == nil {
+		usedBy, err := h.fileRepository.CountPostsUsing(file.Id)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to delete file",
+			})
+		}
+
+		if usedBy > 0 {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error":   "File is in use",
+				"message": fmt.Sprintf("The file is the cover image of %d post(s)", usedBy),
+			})
+		}
+
+		if err := h.fileRepository.Delete(file.Id); err != nil {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error":   "File is in use",
+				"message": fmt.Sprintf("Error occurred while deleting file: %v", err),
+			})
+		}
+	}
+
+	err = h.fileService.DeleteFile(filename, user)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to delete file",
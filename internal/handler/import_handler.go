@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go-blog/internal/service"
+	"go-blog/internal/types"
+	"os"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const defaultWordPressUploadsDir = "wp-content/uploads"
+
+type ImportHandler interface {
+	ImportWordPressHandler(c *fiber.Ctx) error
+	ExportMarkdownHandler(c *fiber.Ctx) error
+	ImportMarkdownHandler(c *fiber.Ctx) error
+}
+
+type importHandler struct {
+	wordPressImportService service.WordPressImportService
+	markdownService        service.MarkdownService
+	relatedPostService     service.RelatedPostService
+	wordPressUploadsDir    string
+}
+
+// NewImportHandler imports WordPress attachments from WORDPRESS_UPLOADS_DIR, a copy of
+// the wp-content/uploads directory of the site.
+func NewImportHandler(wordPressImportService service.WordPressImportService, markdownService service.MarkdownService, relatedPostService service.RelatedPostService) ImportHandler {
+	uploadsDir := os.Getenv("WORDPRESS_UPLOADS_DIR")
+	if uploadsDir == "" {
+		uploadsDir = defaultWordPressUploadsDir
+	}
+
+	return &importHandler{
+		wordPressImportService: wordPressImportService,
+		markdownService:        markdownService,
+		relatedPostService:     relatedPostService,
+		wordPressUploadsDir:    uploadsDir,
+	}
+}
+
+// ImportWordPressHandler imports the WXR export uploaded as file. Posts whose author
+// can't be imported are credited to the signed in admin.
+func (h *importHandler) ImportWordPressHandler(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(types.User)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request payload",
+			"message": "Upload the WXR export as file",
+		})
+	}
+
+	wxr, err := file.Open()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to read the export",
+			"message": fmt.Sprintf("Error opening uploaded file: %v", err),
+		})
+	}
+	defer wxr.Close()
+
+	report, err := h.wordPressImportService.Import(wxr, h.wordPressUploadsDir, &user)
+	if errors.Is(err, service.ErrInvalidExport) {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error":   "Invalid export",
+			"message": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to import the export",
+			"message": fmt.Sprintf("Error occurred while importing: %v", err),
+		})
+	}
+
+	if report.Posts.Created > 0 {
+		h.relatedPostService.Invalidate()
+	}
+
+	return c.JSON(report)
+}
+
+// ExportMarkdownHandler downloads every post as a zip of Markdown files with YAML front
+// matter, which Hugo and Jekyll can read and ImportMarkdownHandler reads back.
+func (h *importHandler) ExportMarkdownHandler(c *fiber.Ctx) error {
+	var archive bytes.Buffer
+	if err := h.markdownService.Export(&archive); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to export posts",
+			"message": fmt.Sprintf("Error occurred while exporting: %v", err),
+		})
+	}
+
+	c.Attachment(fmt.Sprintf("posts-%s.zip", time.Now().Format("20060102")))
+	c.Set(fiber.HeaderContentType, "application/zip")
+	return c.Send(archive.Bytes())
+}
+
+// ImportMarkdownHandler imports the zip of Markdown files uploaded as file, updating the
+// posts with the same slug and creating the others. New posts whose author isn't a user
+// are credited to the signed in admin.
+func (h *importHandler) ImportMarkdownHandler(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(types.User)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request payload",
+			"message": "Upload the zip archive as file",
+		})
+	}
+
+	archive, err := file.Open()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to read the archive",
+			"message": fmt.Sprintf("Error opening uploaded file: %v", err),
+		})
+	}
+	defer archive.Close()
+
+	report, err := h.markdownService.Import(archive, file.Size, user)
+	if errors.Is(err, service.ErrInvalidArchive) {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error":   "Invalid archive",
+			"message": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to import the archive",
+			"message": fmt.Sprintf("Error occurred while importing: %v", err),
+		})
+	}
+
+	if report.Created > 0 || report.Updated > 0 {
+		h.relatedPostService.Invalidate()
+	}
+
+	return c.JSON(report)
+}
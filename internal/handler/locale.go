@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"go-blog/internal/types"
+	"slices"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/text/language"
+)
+
+// negotiateLocale picks the language to show the post in, among the ones it is available
+// in: the lang query parameter wins, then the language of a translation slug, then the
+// Accept-Language header. The slug and id of the post itself don't pin a language, and
+// the post's own language is the fallback.
+func negotiateLocale(c *fiber.Ctx, post *types.Post, slugOrId string) string {
+	locales := post.Locales()
+
+	if lang := strings.ToLower(c.Query("lang")); slices.Contains(locales, lang) {
+		return lang
+	}
+
+	if locale := post.SlugLocale(slugOrId); locale != "" {
+		return locale
+	}
+
+	if locale := acceptedLocale(c.Get(fiber.HeaderAcceptLanguage), locales); locale != "" {
+		return locale
+	}
+
+	return post.Locale
+}
+
+// acceptedLocale returns the available locale the Accept-Language header prefers, matching
+// regional variants such as tr-TR to their language, or an empty string.
+func acceptedLocale(header string, locales []string) string {
+	tags, _, err := language.ParseAcceptLanguage(header)
+	if err != nil {
+		return ""
+	}
+
+	for _, tag := range tags {
+		base, _ := tag.Base()
+		if slices.Contains(locales, base.String()) {
+			return base.String()
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const mergePatchContentType = "application/merge-patch+json"
+
+// applyMergePatch applies an RFC 7396 merge patch to a JSON document. Objects are merged
+// member by member, null removes a member and any other value replaces the target.
+func applyMergePatch(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, _ := target.(map[string]interface{})
+	result := make(map[string]interface{}, len(targetObject))
+	for name, value := range targetObject {
+		result[name] = value
+	}
+
+	for name, value := range patchObject {
+		if value == nil {
+			delete(result, name)
+			continue
+		}
+		result[name] = applyMergePatch(result[name], value)
+	}
+
+	return result
+}
+
+// mergePatch applies the merge patch in the request body to the JSON representation of
+// current and decodes the writable members of the result into patched, which should be
+// empty. Members removed by the patch are left at their zero value. It returns the
+// top-level members the patch changed, and fails it when one of them isn't writable. It
+// reports whether the handler may go on, when it may not the response has already been sent.
+func mergePatch(c *fiber.Ctx, current interface{}, writable []string, patched interface{}) (changed []string, ok bool, err error) {
+	contentType := strings.ToLower(strings.TrimSpace(strings.Split(c.Get(fiber.HeaderContentType), ";")[0]))
+	if contentType != mergePatchContentType && contentType != fiber.MIMEApplicationJSON {
+		return nil, false, c.Status(fiber.StatusUnsupportedMediaType).JSON(fiber.Map{
+			"error":   "Unsupported media type",
+			"message": fmt.Sprintf("Send the patch as %s", mergePatchContentType),
+		})
+	}
+
+	var patch interface{}
+	if err := json.Unmarshal(c.Body(), &patch); err != nil {
+		return nil, false, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request payload",
+			"message": fmt.Sprintf("Error parsing merge patch: %v", err),
+		})
+	}
+	if _, isObject := patch.(map[string]interface{}); !isObject {
+		return nil, false, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request payload",
+			"message": "The merge patch must be a JSON object",
+		})
+	}
+
+	var document map[string]interface{}
+	encoded, err := json.Marshal(current)
+	if err == nil {
+		err = json.Unmarshal(encoded, &document)
+	}
+	if err != nil {
+		return nil, false, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to apply merge patch",
+			"message": fmt.Sprintf("Error encoding the current document: %v", err),
+		})
+	}
+
+	result := applyMergePatch(document, patch).(map[string]interface{})
+
+	fails := make(map[string]string)
+	for name := range patch.(map[string]interface{}) {
+		if reflect.DeepEqual(document[name], result[name]) {
+			continue
+		}
+		if !slices.Contains(writable, name) {
+			fails[name] = "readonly"
+			continue
+		}
+		changed = append(changed, name)
+	}
+	if len(fails) > 0 {
+		return nil, false, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": fails,
+		})
+	}
+
+	members := make(map[string]interface{})
+	for _, name := range writable {
+		if value, present := result[name]; present {
+			members[name] = value
+		}
+	}
+
+	encoded, err = json.Marshal(members)
+	if err == nil {
+		err = json.Unmarshal(encoded, patched)
+	}
+	if err != nil {
+		return nil, false, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request payload",
+			"message": fmt.Sprintf("Error decoding the patched document: %v", err),
+		})
+	}
+
+	slices.Sort(changed)
+	return changed, true, nil
+}
@@ -1,12 +1,18 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"go-blog/internal/repository"
+	"go-blog/internal/service"
 	"go-blog/internal/types"
-	"regexp"
+	"hash/fnv"
+	"log"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -14,62 +20,1093 @@ import (
 
 type PostHandler interface {
 	GetPostHandler(c *fiber.Ctx) error
+	GetPostJsonLdHandler(c *fiber.Ctx) error
+	GetRelatedPostsHandler(c *fiber.Ctx) error
 	CreatePostHandler(c *fiber.Ctx) error
 	UpdatePostHandler(c *fiber.Ctx) error
+	PatchPostHandler(c *fiber.Ctx) error
 	DeletePostHandler(c *fiber.Ctx) error
+	BulkPostsHandler(c *fiber.Ctx) error
 	AssignCategoryToPostHandler(c *fiber.Ctx) error
 	UnassignCategoryFromPostHandler(c *fiber.Ctx) error
 	GetCategoriesForPostHandler(c *fiber.Ctx) error
 	UpdatePostCategoriesHandler(c *fiber.Ctx) error
+	UpdatePostAuthorsHandler(c *fiber.Ctx) error
+	SavePostTranslationHandler(c *fiber.Ctx) error
+	DeletePostTranslationHandler(c *fiber.Ctx) error
+	UnlockPostHandler(c *fiber.Ctx) error
+	CreatePreviewLinkHandler(c *fiber.Ctx) error
+	GetPreviewLinksHandler(c *fiber.Ctx) error
+	RevokePreviewLinkHandler(c *fiber.Ctx) error
+	GetPreviewHandler(c *fiber.Ctx) error
 }
 
 type postHandler struct {
-	postRepository repository.PostRepository
+	postRepository     repository.PostRepository
+	reactionRepository repository.ReactionRepository
+	seriesRepository   repository.SeriesRepository
+	fileRepository     repository.FileRepository
+	viewService        service.ViewService
+	slugService        service.SlugService
+	relatedPostService service.RelatedPostService
+	postUnlockService  service.PostUnlockService
+	previewLinkService service.PreviewLinkService
+	duplicateService   service.DuplicateService
 }
 
-func NewPostHandler(postRepository repository.PostRepository) PostHandler {
-	return &postHandler{postRepository}
+func NewPostHandler(postRepository repository.PostRepository, reactionRepository repository.ReactionRepository, seriesRepository repository.SeriesRepository, fileRepository repository.FileRepository, viewService service.ViewService, slugService service.SlugService, relatedPostService service.RelatedPostService, postUnlockService service.PostUnlockService, previewLinkService service.PreviewLinkService, duplicateService service.DuplicateService) PostHandler {
+	return &postHandler{postRepository, reactionRepository, seriesRepository, fileRepository, viewService, slugService, relatedPostService, postUnlockService, previewLinkService, duplicateService}
+}
+
+// omitContent drops the content of listed posts, which carry their excerpt instead,
+// unless the caller opted back in with ?include=content.
+func omitContent(c *fiber.Ctx, posts []types.Post) {
+	for _, include := range strings.Split(c.Query("include"), ",") {
+		if strings.TrimSpace(include) == "content" {
+			return
+		}
+	}
+
+	for i := range posts {
+		posts[i].Content = ""
+	}
+}
+
+// warnOfDuplicates lists the posts whose content the saved post nearly duplicates in
+// its response. The post is saved either way, so failing to look for them only leaves
+// the warning out. Only the posts the user may read are listed, so that the drafts of
+// other users don't leak.
+func (h *postHandler) warnOfDuplicates(c *fiber.Ctx, post *types.Post) {
+	user, ok := c.Locals("user").(types.User)
+	if !ok {
+		return
+	}
+	readerId := user.Id
+	if user.HasRole(types.RoleEditor) {
+		readerId = ""
+	}
+
+	duplicates, err := h.duplicateService.FindNearDuplicates(post.Id, post.Content, readerId)
+	if err != nil {
+		log.Printf("error looking for duplicates of post %s: %v", post.Id, err)
+		return
+	}
+
+	post.NearDuplicates = duplicates
+}
+
+// resolveCoverImage checks that the cover image of the post is an uploaded image owned
+// by one of its authors, and fills in its URL and dimensions.
+func (h *postHandler) resolveCoverImage(post *types.Post) error {
+	if post.CoverImage == nil {
+		return nil
+	}
+
+	file, err := h.fileRepository.FindById(post.CoverImage.Id)
+	if err != nil || !post.IsAuthor(file.UserId) {
+		return fmt.Errorf("no uploaded file found with id %s", post.CoverImage.Id)
+	}
+
+	if !file.IsImage() {
+		return fmt.Errorf("file %s is not an image", file.Filename)
+	}
+
+	post.CoverImage.Url = file.Url
+	post.CoverImage.Width = file.Width
+	post.CoverImage.Height = file.Height
+
+	return nil
+}
+
+// canRead reports whether the user of the request may read the post. Drafts are only
+// shown to their authors and editors.
+func canRead(c *fiber.Ctx, post *types.Post) bool {
+	if post.IsPublished() {
+		return true
+	}
+
+	user, ok := c.Locals("user").(types.User)
+	return ok && (post.IsAuthor(user.Id) || user.HasRole(types.RoleEditor))
+}
+
+// lockPost reduces the post to a teaser, its excerpt, when the user of the request may
+// not read it. Members only posts need a signed in user and password protected ones
+// the cookie of UnlockPostHandler, but authors and editors may always read them.
+func lockPost(c *fiber.Ctx, postUnlockService service.PostUnlockService, post *types.Post) {
+	user, signedIn := c.Locals("user").(types.User)
+	if signedIn && (post.IsAuthor(user.Id) || user.HasRole(types.RoleEditor)) {
+		return
+	}
+
+	switch {
+	case post.Visibility == types.VisibilityMembers && !signedIn,
+		post.Visibility == types.VisibilityPassword && !postUnlockService.IsUnlocked(c, *post):
+		post.Content = ""
+		post.Locked = true
+	}
+}
+
+func lockPosts(c *fiber.Ctx, postUnlockService service.PostUnlockService, posts []types.Post) {
+	for i := range posts {
+		lockPost(c, postUnlockService, &posts[i])
+	}
+}
+
+// resubmitsForReview reports whether saving the post submits it for review again. An
+// approval is for the post as reviewed, so authors changing what readers read of an
+// approved post can neither keep it approved nor publish it, even in the same request.
+func resubmitsForReview(user types.User, post types.Post, existingPost *types.Post) bool {
+	if existingPost.Status != types.PostStatusApproved || user.HasRole(types.RoleEditor) {
+		return false
+	}
+	if post.Status != types.PostStatusApproved && post.Status != types.PostStatusPublished {
+		return false
+	}
+
+	coverImageId := func(post types.Post) string {
+		if post.CoverImage == nil {
+			return ""
+		}
+		return post.CoverImage.Id
+	}
+
+	return post.Title != existingPost.Title ||
+		post.Content != existingPost.Content ||
+		post.Excerpt != "" && strings.TrimSpace(post.Excerpt) != existingPost.Excerpt ||
+		coverImageId(post) != coverImageId(*existingPost)
+}
+
+// regenerateExcerpt drops the excerpt of the post when it is still the one generated for
+// the existing post, as PUT echoes it and PATCH keeps it, so that Summarize generates it
+// again from the content, which may have changed.
+func regenerateExcerpt(post *types.Post, existingPost *types.Post) {
+	if existingPost.ExcerptGenerated && strings.TrimSpace(post.Excerpt) == existingPost.Excerpt {
+		post.Excerpt = ""
+	}
+}
+
+// findReadablePost returns the post with the id when the user of the request may read
+// it in full, and so its comments and reactions. Otherwise it answers the request, with
+// 404 as GetPostHandler does or 403 when the post is locked, and reports false.
+func findReadablePost(c *fiber.Ctx, postRepository repository.PostRepository, postUnlockService service.PostUnlockService, id string) (*types.Post, bool, error) {
+	post, err := postRepository.FindById(id)
+	if err != nil || !canRead(c, post) {
+		return nil, false, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "Post not found",
+			"message": fmt.Sprintf("No post found with ID: %s", id),
+		})
+	}
+
+	lockPost(c, postUnlockService, post)
+	if post.Locked {
+		return nil, false, c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error":   "Post locked",
+			"message": "Sign in or unlock the post with its password to take part in it",
+		})
+	}
+
+	return post, true, nil
+}
+
+// findPostToCategorize returns the post with the id when the user of the request may
+// change its categories, as its authors and editors may. Otherwise it answers the
+// request and reports false.
+func (h *postHandler) findPostToCategorize(c *fiber.Ctx, id string) (*types.Post, bool, error) {
+	user, ok := c.Locals("user").(types.User)
+	if !ok {
+		return nil, false, c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	post, err := h.postRepository.FindById(id)
+	if err != nil {
+		return nil, false, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "Post not found",
+			"message": fmt.Sprintf("No post found with ID: %s", id),
+		})
+	}
+
+	if !post.IsAuthor(user.Id) && !user.HasRole(types.RoleEditor) {
+		return nil, false, c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "You don't have permission to change the categories of this post",
+		})
+	}
+
+	return post, true, nil
+}
+
+// missingPassword reports whether the post is password protected without a password,
+// neither a new one nor the one existingPost already has.
+func missingPassword(post types.Post, existingPost *types.Post) bool {
+	if post.Visibility != types.VisibilityPassword || post.Password != "" {
+		return false
+	}
+
+	return existingPost == nil || existingPost.PasswordHash == ""
+}
+
+// statusChangeForbidden answers a status change the user may not make, see
+// types.Post.CanChangeStatus.
+func statusChangeForbidden(c *fiber.Ctx, from string, to string) error {
+	return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+		"error":   "Status change not allowed",
+		"message": fmt.Sprintf("You can't move a post from %s to %s, posts are published once approved by an editor", from, to),
+	})
+}
+
+// findPost looks a post up by id when given a UUID, and by slug otherwise. Since slugs
+// are unique per language, a post known by the slug in locale is preferred.
+func (h *postHandler) findPost(slugOrId string, locale string) (*types.Post, error) {
+	if _, err := uuid.Parse(slugOrId); err == nil {
+		post, err := h.postRepository.FindById(slugOrId)
+		if err != nil {
+			return nil, fmt.Errorf("No post found with id: %s", slugOrId)
+		}
+		return post, nil
+	}
+
+	post, err := h.postRepository.FindBySlug(slugOrId, locale)
+	if err != nil {
+		return nil, fmt.Errorf("No post found with slug: %s", slugOrId)
+	}
+	return post, nil
+}
+
+// localize returns the post in the language negotiated for the request, see
+// negotiateLocale, and tells caches the response depends on Accept-Language.
+func (h *postHandler) localize(c *fiber.Ctx, post *types.Post, slugOrId string) (*types.Post, error) {
+	locale := negotiateLocale(c, post, slugOrId)
+
+	c.Set(fiber.HeaderContentLanguage, locale)
+	c.Vary(fiber.HeaderAcceptLanguage)
+
+	if locale == post.Locale {
+		return post, nil
+	}
+
+	translation, err := h.postRepository.FindPostTranslation(post.Id, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	localized := post.Localized(*translation)
+	return &localized, nil
 }
 
 func (h *postHandler) GetPostHandler(c *fiber.Ctx) error {
 	slugOrId := c.Params("slugOrId")
 
 	if slugOrId != "" {
-		var post *types.Post
-
-		_, err := uuid.Parse(slugOrId)
-		if err == nil {
-			post, err = h.postRepository.FindById(slugOrId)
-			if err != nil {
-				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-					"error":   "Post not found",
-					"message": fmt.Sprintf("No post found with id: %s", slugOrId),
-				})
-			}
-		} else {
-			post, err = h.postRepository.FindBySlug(slugOrId)
-			if err != nil {
-				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-					"error":   "Post not found",
-					"message": fmt.Sprintf("No post found with slug: %s", slugOrId),
-				})
-			}
+		post, err := h.findPost(slugOrId, c.Query("lang"))
+		if err == nil && !canRead(c, post) {
+			err = fmt.Errorf("No post found with slug: %s", slugOrId)
+		}
+		if err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error":   "Post not found",
+				"message": err.Error(),
+			})
+		}
+
+		post, err = h.localize(c, post, slugOrId)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error":   "Failed to retrieve translation",
+				"message": fmt.Sprintf("Error occurred while fetching translation: %v", err),
+			})
+		}
+
+		// Former slugs redirect to the slug of the language the post is shown in
+		if !post.HasSlug(slugOrId) && post.Id != slugOrId {
+			return redirectToSlug(c, slugOrId, post.Slug)
 		}
 
+		// Readers who may not read the post get a teaser, another representation of it
+		lockPost(c, h.postUnlockService, post)
+		if ok, err := h.completePost(c, post); !ok {
+			return err
+		}
+
+		// The version changes whenever the post or its translations are saved, but not
+		// when the categories, tags or authors it shows are renamed or trashed, nor its
+		// counters, series or the reactions of the reader
+		parts := []string{post.Locale, embeddedTag(post)}
+		if post.Locked {
+			parts = append(parts, "locked")
+		}
+		if notModified(c, entityTag(post.Version, parts...)) {
+			h.viewService.Record(post.Id, c.IP(), c.Get(fiber.HeaderUserAgent), c.Get(fiber.HeaderReferer))
+			return c.SendStatus(fiber.StatusNotModified)
+		}
+
+		h.viewService.Record(post.Id, c.IP(), c.Get(fiber.HeaderUserAgent), c.Get(fiber.HeaderReferer))
+
 		return c.JSON(post)
 	}
 
-	page, _ := strconv.Atoi(c.Query("page", "1"))
-	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+
+	if c.Query("after") != "" || c.Query("before") != "" {
+		return h.getPostsByCursor(c, limit)
+	}
+
+	posts, totalCount, err := h.postRepository.FindAllPaginated(page, limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to retrieve posts",
+			"message": fmt.Sprintf("Error occurred while fetching posts: %v", err),
+		})
+	}
+	lockPosts(c, h.postUnlockService, posts)
+
+	if err := markViewerReactions(c, h.reactionRepository, posts); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to retrieve reactions",
+			"message": fmt.Sprintf("Error occurred while fetching reactions: %v", err),
+		})
+	}
+
+	omitContent(c, posts)
+
+	totalPages := (totalCount + limit - 1) / limit
+
+	var nextCursor *string
+	if page < totalPages && len(posts) > 0 {
+		cursor := types.NewCursor(posts[len(posts)-1]).Encode()
+		nextCursor = &cursor
+	}
+
+	return c.JSON(fiber.Map{
+		"meta": fiber.Map{
+			"page":       page,
+			"limit":      limit,
+			"totalCount": totalCount,
+			"totalPages": totalPages,
+			"nextCursor": nextCursor,
+		},
+		"data": posts,
+	})
+}
+
+// completePost adds its resolved SEO metadata, where it stands in its series and the
+// reactions of the user to a single post. When that fails, it answers the request and
+// reports false.
+func (h *postHandler) completePost(c *fiber.Ctx, post *types.Post) (bool, error) {
+	post.Seo = post.ResolvedSeo()
+	if post.Seo.Noindex {
+		c.Set("X-Robots-Tag", "noindex")
+	}
+
+	series, err := h.seriesRepository.FindByPost(post.Id)
+	if err != nil {
+		return false, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to retrieve series",
+			"message": fmt.Sprintf("Error occurred while fetching series: %v", err),
+		})
+	}
+	if series != nil {
+		post.Series = series.Navigation(post.Id)
+	}
+
+	posts := []types.Post{*post}
+	if err := markViewerReactions(c, h.reactionRepository, posts); err != nil {
+		return false, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to retrieve reactions",
+			"message": fmt.Sprintf("Error occurred while fetching reactions: %v", err),
+		})
+	}
+	*post = posts[0]
+
+	return true, nil
+}
+
+// embeddedTag hashes what a post shows of other resources, which change without its
+// version: its categories, tags and authors, its comment count, its reactions along with
+// the ones of the reader, and its series navigation.
+func embeddedTag(post *types.Post) string {
+	embedded, _ := json.Marshal(struct {
+		Author       types.User
+		Authors      []types.PostAuthor
+		Categories   []types.Category
+		Tags         []types.Tag
+		CommentCount int
+		Reactions    []types.Reaction
+		Series       *types.PostSeries
+	}{post.Author, post.Authors, post.Categories, post.Tags, post.CommentCount, post.Reactions, post.Series})
+
+	hash := fnv.New32a()
+	hash.Write(embedded)
+	return strconv.FormatUint(uint64(hash.Sum32()), 36)
+}
+
+func (h *postHandler) getPostsByCursor(c *fiber.Ctx, limit int) error {
+	after, before := c.Query("after"), c.Query("before")
+	if after != "" && before != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid pagination parameters",
+			"message": "Only one of after or before can be provided",
+		})
+	}
+
+	var posts []types.Post
+	var hasNext, hasPrev bool
+
+	if after != "" {
+		cursor, err := types.DecodeCursor(after)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   "Invalid cursor",
+				"message": err.Error(),
+			})
+		}
+
+		posts, hasNext, err = h.postRepository.FindAllAfter(*cursor, limit)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error":   "Failed to retrieve posts",
+				"message": fmt.Sprintf("Error occurred while fetching posts: %v", err),
+			})
+		}
+		hasPrev = true
+	} else {
+		cursor, err := types.DecodeCursor(before)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   "Invalid cursor",
+				"message": err.Error(),
+			})
+		}
+
+		posts, hasPrev, err = h.postRepository.FindAllBefore(*cursor, limit)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error":   "Failed to retrieve posts",
+				"message": fmt.Sprintf("Error occurred while fetching posts: %v", err),
+			})
+		}
+		hasNext = true
+	}
+	lockPosts(c, h.postUnlockService, posts)
+
+	if err := markViewerReactions(c, h.reactionRepository, posts); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to retrieve reactions",
+			"message": fmt.Sprintf("Error occurred while fetching reactions: %v", err),
+		})
+	}
+
+	omitContent(c, posts)
+
+	var nextCursor, prevCursor *string
+	if len(posts) > 0 {
+		if hasNext {
+			cursor := types.NewCursor(posts[len(posts)-1]).Encode()
+			nextCursor = &cursor
+		}
+		if hasPrev {
+			cursor := types.NewCursor(posts[0]).Encode()
+			prevCursor = &cursor
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"meta": fiber.Map{
+			"limit":      limit,
+			"nextCursor": nextCursor,
+			"prevCursor": prevCursor,
+		},
+		"data": posts,
+	})
+}
+
+func (h *postHandler) GetPostJsonLdHandler(c *fiber.Ctx) error {
+	slugOrId := c.Params("slugOrId")
+
+	post, err := h.findPost(slugOrId, c.Query("lang"))
+	if err == nil && !canRead(c, post) {
+		err = fmt.Errorf("No post found with slug: %s", slugOrId)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "Post not found",
+			"message": err.Error(),
+		})
+	}
+
+	post, err = h.localize(c, post, slugOrId)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to retrieve translation",
+			"message": fmt.Sprintf("Error occurred while fetching translation: %v", err),
+		})
+	}
+
+	if !post.HasSlug(slugOrId) && post.Id != slugOrId {
+		return redirectToSlug(c, slugOrId, post.Slug)
+	}
+
+	return c.JSON(post.BlogPosting(), "application/ld+json")
+}
+
+// GetRelatedPostsHandler lists the posts most related to the post by shared categories,
+// shared tags and similar titles.
+func (h *postHandler) GetRelatedPostsHandler(c *fiber.Ctx) error {
+	slugOrId := c.Params("slugOrId")
+
+	post, err := h.findPost(slugOrId, c.Query("lang"))
+	if err == nil && !canRead(c, post) {
+		err = fmt.Errorf("No post found with slug: %s", slugOrId)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "Post not found",
+			"message": err.Error(),
+		})
+	}
+
+	if !post.HasSlug(slugOrId) && post.Id != slugOrId {
+		return redirectToSlug(c, slugOrId, post.Slug)
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit", "5"))
+	if limit < 1 {
+		limit = 5
+	}
+	if limit > 20 {
+		limit = 20
+	}
+
+	posts, err := h.relatedPostService.FindRelated(post.Id, limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to retrieve related posts",
+			"message": fmt.Sprintf("Error occurred while fetching related posts: %v", err),
+		})
+	}
+
+	if posts == nil {
+		posts = []types.Post{}
+	}
+	lockPosts(c, h.postUnlockService, posts)
+	omitContent(c, posts)
+
+	return c.JSON(posts)
+}
+
+func (h *postHandler) CreatePostHandler(c *fiber.Ctx) error {
+	var post types.Post
+
+	if err := c.BodyParser(&post); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request payload",
+			"message": fmt.Sprintf("Error parsing post data: %v", err),
+		})
+	}
+
+	if err := post.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": err,
+		})
+	}
+
+	user, ok := c.Locals("user").(types.User)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	tags, fails := normalizeTags(h.slugService, post.Tags)
+	if fails != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": fiber.Map{"Tags": fails},
+		})
+	}
+
+	slug, err := resolveSlug(h.slugService, post.Slug, "", post.Title, "", post.Locale)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": fiber.Map{"Slug": err.Error()},
+		})
+	}
+
+	post.Author.Id = user.Id
+	post.Slug = slug
+	// Only imports keep a publish date of their own
+	post.CreatedAt = time.Time{}
+	if post.Locale == "" {
+		post.Locale = types.DefaultLocale()
+	}
+	// Posts of authors other than editors start as drafts and go through review
+	if post.Status == "" && user.HasRole(types.RoleEditor) {
+		post.Status = types.PostStatusPublished
+	}
+	if post.Status == "" {
+		post.Status = types.PostStatusDraft
+	}
+	if draft := (types.Post{Status: types.PostStatusDraft}); !draft.CanChangeStatus(user, post.Status) {
+		return statusChangeForbidden(c, draft.Status, post.Status)
+	}
+	if post.Visibility == "" {
+		post.Visibility = types.VisibilityPublic
+	}
+	if missingPassword(post, nil) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": fiber.Map{"Password": "required"},
+		})
+	}
+	post.Summarize()
+
+	if err := h.resolveCoverImage(&post); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": fiber.Map{"CoverImage": err.Error()},
+		})
+	}
+
+	createdPost, err := h.postRepository.Create(post)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to create post",
+			"message": fmt.Sprintf("Error occurred while creating post: %v", err),
+		})
+	}
+
+	createdPost.Tags = []types.Tag{}
+	if len(tags) > 0 {
+		createdPost.Tags, err = h.postRepository.UpdatePostTags(createdPost.Id, tags)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error":   "Failed to save post tags",
+				"message": fmt.Sprintf("Error occurred while saving tags: %v", err),
+			})
+		}
+		// Saving the tags is a change of its own
+		createdPost.Version++
+	}
+
+	h.relatedPostService.Invalidate()
+	h.warnOfDuplicates(c, createdPost)
+
+	c.Set(fiber.HeaderETag, entityTag(createdPost.Version, createdPost.Locale))
+	return c.Status(fiber.StatusCreated).JSON(createdPost)
+}
+
+func (h *postHandler) UpdatePostHandler(c *fiber.Ctx) error {
+	id := c.Params("id")
+	var post types.Post
+
+	if err := c.BodyParser(&post); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request payload",
+			"message": fmt.Sprintf("Error parsing post data: %v", err),
+		})
+	}
+
+	if err := post.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": err,
+		})
+	}
+
+	user, ok := c.Locals("user").(types.User)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	existingPost, err := h.postRepository.FindById(id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "Post not found",
+			"message": fmt.Sprintf("No post found with ID: %s", id),
+		})
+	}
+
+	if !existingPost.IsAuthor(user.Id) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "You don't have permission to update this post",
+		})
+	}
+
+	// Saving over changes the client hasn't seen would silently drop them
+	if ok, err := checkIfMatch(c, entityTag(existingPost.Version, existingPost.Locale)); !ok {
+		return err
+	}
+	post.Version = existingPost.Version
+
+	// Tags are only replaced when the payload contains them
+	tags, fails := normalizeTags(h.slugService, post.Tags)
+	if fails != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": fiber.Map{"Tags": fails},
+		})
+	}
+
+	// Former slugs keep redirecting to the post
+	post.Slug, err = resolveSlug(h.slugService, post.Slug, existingPost.Slug, post.Title, existingPost.Title, post.Locale)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": fiber.Map{"Slug": err.Error()},
+		})
+	}
+
+	if post.Status == "" {
+		post.Status = existingPost.Status
+	}
+	if !existingPost.CanChangeStatus(user, post.Status) {
+		return statusChangeForbidden(c, existingPost.Status, post.Status)
+	}
+	if resubmitsForReview(user, post, existingPost) {
+		post.Status = types.PostStatusInReview
+	}
+	if post.Visibility == "" {
+		post.Visibility = existingPost.Visibility
+	}
+	if missingPassword(post, existingPost) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": fiber.Map{"Password": "required"},
+		})
+	}
+
+	// The post can't move to a language it is already translated to
+	if post.Locale == "" {
+		post.Locale = existingPost.Locale
+	}
+	if post.Locale != existingPost.Locale && slices.Contains(existingPost.Locales(), post.Locale) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": fiber.Map{"Locale": "translated"},
+		})
+	}
+	regenerateExcerpt(&post, existingPost)
+	post.Summarize()
+
+	// The cover image must belong to one of the existing authors
+	post.Author = existingPost.Author
+	post.Authors = existingPost.Authors
+	if err := h.resolveCoverImage(&post); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": fiber.Map{"CoverImage": err.Error()},
+		})
+	}
+
+	updatedPost, err := h.postRepository.Update(id, post)
+	if errors.Is(err, repository.ErrVersionConflict) {
+		return preconditionFailed(c)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to update post",
+			"message": fmt.Sprintf("Error occurred while updating post: %v", err),
+		})
+	}
+
+	updatedPost.Categories = existingPost.Categories
+	updatedPost.Tags = existingPost.Tags
+	if post.Tags != nil {
+		updatedPost.Tags, err = h.postRepository.UpdatePostTags(id, tags)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error":   "Failed to save post tags",
+				"message": fmt.Sprintf("Error occurred while saving tags: %v", err),
+			})
+		}
+		updatedPost.Version++
+	}
+
+	h.relatedPostService.Invalidate()
+	h.warnOfDuplicates(c, updatedPost)
+
+	c.Set(fiber.HeaderETag, entityTag(updatedPost.Version, updatedPost.Locale))
+	return c.JSON(updatedPost)
+}
+
+// patchablePostMembers are the members of a post a merge patch may change.
+var patchablePostMembers = []string{"title", "slug", "locale", "status", "visibility", "password", "content", "excerpt", "seo", "coverImage", "categories", "tags"}
+
+// PatchPostHandler applies a JSON merge patch to the post. Only the patched post has to
+// be valid and only what the patch changes is saved, so a patch may hold nothing but the
+// categories. Removing the excerpt generates it again from the content, as does changing
+// the content of a post whose excerpt was generated.
+func (h *postHandler) PatchPostHandler(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	user, ok := c.Locals("user").(types.User)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	existingPost, err := h.postRepository.FindById(id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "Post not found",
+			"message": fmt.Sprintf("No post found with ID: %s", id),
+		})
+	}
+
+	if !existingPost.IsAuthor(user.Id) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "You don't have permission to update this post",
+		})
+	}
+
+	if ok, err := checkIfMatch(c, entityTag(existingPost.Version, existingPost.Locale)); !ok {
+		return err
+	}
+
+	var post types.Post
+	changed, ok, err := mergePatch(c, existingPost, patchablePostMembers, &post)
+	if !ok {
+		return err
+	}
+
+	if err := post.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": err,
+		})
+	}
+	post.Version = existingPost.Version
+
+	var tags []types.Tag
+	if slices.Contains(changed, "tags") {
+		var fails map[string]string
+		if tags, fails = normalizeTags(h.slugService, post.Tags); fails != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Validation failed",
+				"fails": fiber.Map{"Tags": fails},
+			})
+		}
+	}
+
+	if post.Status == "" {
+		post.Status = existingPost.Status
+	}
+	if !existingPost.CanChangeStatus(user, post.Status) {
+		return statusChangeForbidden(c, existingPost.Status, post.Status)
+	}
+	if resubmitsForReview(user, post, existingPost) {
+		post.Status = types.PostStatusInReview
+	}
+	if post.Visibility == "" {
+		post.Visibility = existingPost.Visibility
+	}
+	if missingPassword(post, existingPost) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": fiber.Map{"Password": "required"},
+		})
+	}
+	if post.Locale == "" {
+		post.Locale = existingPost.Locale
+	}
+	if post.Locale != existingPost.Locale && slices.Contains(existingPost.Locales(), post.Locale) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": fiber.Map{"Locale": "translated"},
+		})
+	}
+
+	post.Slug, err = resolveSlug(h.slugService, post.Slug, existingPost.Slug, post.Title, existingPost.Title, post.Locale)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": fiber.Map{"Slug": err.Error()},
+		})
+	}
+	regenerateExcerpt(&post, existingPost)
+	post.Summarize()
+
+	post.Author = existingPost.Author
+	post.Authors = existingPost.Authors
+	if err := h.resolveCoverImage(&post); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": fiber.Map{"CoverImage": err.Error()},
+		})
+	}
+
+	_, err = h.postRepository.Patch(id, post)
+	if errors.Is(err, repository.ErrVersionConflict) {
+		return preconditionFailed(c)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to update post",
+			"message": fmt.Sprintf("Error occurred while updating post: %v", err),
+		})
+	}
+
+	if slices.Contains(changed, "categories") {
+		categoryIds := make([]string, len(post.Categories))
+		for i, category := range post.Categories {
+			categoryIds[i] = category.Id
+		}
+
+		if err := h.postRepository.UpdatePostCategories(id, categoryIds); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error":   "Failed to update post categories",
+				"message": fmt.Sprintf("Error occurred while updating categories: %v", err),
+			})
+		}
+	}
+
+	if slices.Contains(changed, "tags") {
+		if _, err := h.postRepository.UpdatePostTags(id, tags); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error":   "Failed to save post tags",
+				"message": fmt.Sprintf("Error occurred while saving tags: %v", err),
+			})
+		}
+	}
+
+	if len(changed) > 0 {
+		h.relatedPostService.Invalidate()
+	}
+
+	updatedPost, err := h.postRepository.FindById(id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to retrieve post",
+			"message": fmt.Sprintf("Error retrieving updated post: %v", err),
+		})
+	}
+	h.warnOfDuplicates(c, updatedPost)
+
+	c.Set(fiber.HeaderETag, entityTag(updatedPost.Version, updatedPost.Locale))
+	return c.JSON(updatedPost)
+}
+
+func (h *postHandler) DeletePostHandler(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	user, ok := c.Locals("user").(types.User)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	existingPost, err := h.postRepository.FindById(id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "Post not found",
+			"message": fmt.Sprintf("No post found with ID: %s", id),
+		})
+	}
+
+	if !existingPost.IsOwner(user.Id) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "You don't have permission to delete this post",
+		})
+	}
+
+	if err := h.postRepository.Delete(id); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to delete post",
+			"message": fmt.Sprintf("Error occurred while deleting post: %v", err),
+		})
+	}
+
+	h.relatedPostService.Invalidate()
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// maxBulkPosts caps the posts a single bulk request may change.
+const maxBulkPosts = 500
+
+// mayApplyBulkAction reports whether the user may apply the bulk action to the post.
+// Editors may apply any action to any post, other users what they could do to the post
+// on its own: the owner deletes and hands the post over, any author does the rest.
+func mayApplyBulkAction(user types.User, post types.Post, action string) bool {
+	if user.HasRole(types.RoleEditor) {
+		return true
+	}
+
+	switch action {
+	case types.BulkActionDelete, types.BulkActionChangeAuthor:
+		return post.IsOwner(user.Id)
+	case types.BulkActionPublish:
+		return post.IsAuthor(user.Id) && post.CanChangeStatus(user, types.PostStatusPublished)
+	case types.BulkActionUnpublish:
+		return post.IsAuthor(user.Id) && post.CanChangeStatus(user, types.PostStatusDraft)
+	default:
+		return post.IsAuthor(user.Id)
+	}
+}
+
+// BulkPostsHandler applies one action to the posts listed by id or matched by a filter,
+// in a single transaction. Every post is checked and reported on its own, posts the user
+// may not change or that fail are left as they were while the others are saved.
+func (h *postHandler) BulkPostsHandler(c *fiber.Ctx) error {
+	var request types.BulkRequest
+
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request payload",
+			"message": fmt.Sprintf("Error parsing bulk request: %v", err),
+		})
+	}
+
+	if err := request.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": err,
+		})
+	}
+
+	if len(request.Ids) > maxBulkPosts {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": fiber.Map{"Ids": "max"},
+		})
+	}
 
-	if page < 1 {
-		page = 1
+	// A filter without criteria would apply the action to every post
+	if request.Filter != nil && *request.Filter == (types.PostFilter{}) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": fiber.Map{"Filter": "required"},
+		})
 	}
-	if limit < 1 {
-		limit = 10
+
+	user, ok := c.Locals("user").(types.User)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
 	}
 
-	posts, totalCount, err := h.postRepository.FindAllPaginated(page, limit)
+	var posts []types.Post
+	var err error
+	if request.Filter != nil {
+		posts, err = h.postRepository.FindByFilter(*request.Filter, maxBulkPosts+1)
+	} else {
+		posts, err = h.postRepository.FindByIds(request.Ids)
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error":   "Failed to retrieve posts",
@@ -77,77 +1114,177 @@ func (h *postHandler) GetPostHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	totalPages := (totalCount + limit - 1) / limit
+	if len(posts) > maxBulkPosts {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Too many posts",
+			"message": fmt.Sprintf("The filter matches more than %d posts, narrow it down", maxBulkPosts),
+		})
+	}
+
+	// Listed ids keep their order in the report, filtered posts come newest first
+	ids := request.Ids
+	if request.Filter != nil {
+		ids = make([]string, len(posts))
+		for i, post := range posts {
+			ids[i] = post.Id
+		}
+	}
+
+	postsById := make(map[string]types.Post, len(posts))
+	for _, post := range posts {
+		postsById[post.Id] = post
+	}
+
+	results := make([]types.BulkResult, 0, len(ids))
+	var allowed []string
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		post, found := postsById[id]
+		switch {
+		case !found:
+			results = append(results, types.BulkResult{Id: id, Status: types.BulkResultNotFound})
+		case !mayApplyBulkAction(user, post, request.Action):
+			results = append(results, types.BulkResult{Id: id, Status: types.BulkResultForbidden})
+		default:
+			results = append(results, types.BulkResult{Id: id, Status: types.BulkResultDone})
+			allowed = append(allowed, id)
+		}
+	}
+
+	var failed map[string]error
+	if len(allowed) > 0 {
+		failed, err = h.postRepository.ApplyBulkAction(request, allowed)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error":   "Failed to apply bulk action",
+				"message": fmt.Sprintf("Error occurred while applying %s: %v", request.Action, err),
+			})
+		}
+	}
+
+	summary := make(map[string]int)
+	for i, result := range results {
+		if err, ok := failed[result.Id]; ok {
+			results[i].Status = types.BulkResultFailed
+			results[i].Error = err.Error()
+		}
+		summary[results[i].Status]++
+	}
+
+	if len(allowed) > len(failed) {
+		h.relatedPostService.Invalidate()
+	}
 
 	return c.JSON(fiber.Map{
-		"meta": fiber.Map{
-			"page":       page,
-			"limit":      limit,
-			"totalCount": totalCount,
-			"totalPages": totalPages,
-		},
-		"data": posts,
+		"action":  request.Action,
+		"summary": summary,
+		"results": results,
 	})
 }
 
-func (h *postHandler) CreatePostHandler(c *fiber.Ctx) error {
-	var post types.Post
+func (h *postHandler) AssignCategoryToPostHandler(c *fiber.Ctx) error {
+	postId := c.Params("postId")
+	categoryId := c.Params("categoryId")
 
-	if err := c.BodyParser(&post); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   "Invalid request payload",
-			"message": fmt.Sprintf("Error parsing post data: %v", err),
-		})
+	if _, ok, err := h.findPostToCategorize(c, postId); !ok {
+		return err
 	}
 
-	if err := post.Validate(); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Validation failed",
-			"fails": err,
+	err := h.postRepository.AssignCategoryToPost(postId, categoryId)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to assign category to post",
+			"message": fmt.Sprintf("Error occurred while assigning category: %v", err),
 		})
 	}
 
-	user, ok := c.Locals("user").(types.User)
-	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "User not authenticated",
+	h.relatedPostService.Invalidate()
+
+	return c.SendStatus(fiber.StatusCreated)
+}
+
+func (h *postHandler) UnassignCategoryFromPostHandler(c *fiber.Ctx) error {
+	postId := c.Params("postId")
+	categoryId := c.Params("categoryId")
+
+	if _, ok, err := h.findPostToCategorize(c, postId); !ok {
+		return err
+	}
+
+	err := h.postRepository.UnassignCategoryFromPost(postId, categoryId)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to unassign category from post",
+			"message": fmt.Sprintf("Error occurred while unassigning category: %v", err),
 		})
 	}
 
-	post.Author.Id = user.Id
+	h.relatedPostService.Invalidate()
 
-	// Generate slug
-	slug := strings.ToLower(post.Title)
-	reg, _ := regexp.Compile("[^a-z0-9]+")
-	slug = reg.ReplaceAllString(slug, "-")
-	post.Slug = strings.Trim(slug, "-")
+	return c.SendStatus(fiber.StatusNoContent)
+}
 
-	createdPost, err := h.postRepository.Create(post)
+func (h *postHandler) GetCategoriesForPostHandler(c *fiber.Ctx) error {
+	postId := c.Params("postId")
+
+	categories, err := h.postRepository.GetCategoriesForPost(postId)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "Failed to create post",
-			"message": fmt.Sprintf("Error occurred while creating post: %v", err),
+			"error":   "Failed to get categories for post",
+			"message": fmt.Sprintf("Error occurred while fetching categories: %v", err),
 		})
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(createdPost)
+	return c.JSON(categories)
 }
 
-func (h *postHandler) UpdatePostHandler(c *fiber.Ctx) error {
-	id := c.Params("id")
-	var post types.Post
+func (h *postHandler) UpdatePostCategoriesHandler(c *fiber.Ctx) error {
+	postId := c.Params("postId")
 
-	if err := c.BodyParser(&post); err != nil {
+	var request struct {
+		CategoryIds []string `json:"categoryIds"`
+	}
+
+	if err := c.BodyParser(&request); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error":   "Invalid request payload",
-			"message": fmt.Sprintf("Error parsing post data: %v", err),
+			"message": fmt.Sprintf("Error parsing request body: %v", err),
 		})
 	}
 
-	if err := post.Validate(); err != nil {
+	if _, ok, err := h.findPostToCategorize(c, postId); !ok {
+		return err
+	}
+
+	err := h.postRepository.UpdatePostCategories(postId, request.CategoryIds)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to update post categories",
+			"message": fmt.Sprintf("Error occurred while updating categories: %v", err),
+		})
+	}
+
+	h.relatedPostService.Invalidate()
+
+	return c.SendStatus(fiber.StatusOK)
+}
+
+func (h *postHandler) UpdatePostAuthorsHandler(c *fiber.Ctx) error {
+	postId := c.Params("postId")
+
+	var request struct {
+		Authors []types.PostAuthor `json:"authors"`
+	}
+
+	if err := c.BodyParser(&request); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Validation failed",
-			"fails": err,
+			"error":   "Invalid request payload",
+			"message": fmt.Sprintf("Error parsing request body: %v", err),
 		})
 	}
 
@@ -158,39 +1295,79 @@ func (h *postHandler) UpdatePostHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	existingPost, err := h.postRepository.FindById(id)
+	existingPost, err := h.postRepository.FindById(postId)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error":   "Post not found",
-			"message": fmt.Sprintf("No post found with ID: %s", id),
+			"message": fmt.Sprintf("No post found with ID: %s", postId),
 		})
 	}
 
-	if existingPost.Author.Id != user.Id {
+	if !existingPost.IsOwner(user.Id) {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "You don't have permission to update this post",
+			"error": "You don't have permission to change the authors of this post",
 		})
 	}
 
-	// Generate slug
-	slug := strings.ToLower(post.Title)
-	reg, _ := regexp.Compile("[^a-z0-9]+")
-	slug = reg.ReplaceAllString(slug, "-")
-	post.Slug = strings.Trim(slug, "-")
+	if len(request.Authors) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": fiber.Map{"Authors": "required"},
+		})
+	}
 
-	updatedPost, err := h.postRepository.Update(id, post)
+	fails := make(map[string]map[string]string)
+	seen := make(map[string]bool)
+	for i, author := range request.Authors {
+		if errs := author.Validate(); errs != nil {
+			fails[strconv.Itoa(i)] = errs
+			continue
+		}
+
+		if seen[author.Id] {
+			fails[strconv.Itoa(i)] = map[string]string{"Id": "unique"}
+		}
+		seen[author.Id] = true
+	}
+
+	if len(fails) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": fiber.Map{"Authors": fails},
+		})
+	}
+
+	authors, err := h.postRepository.UpdatePostAuthors(postId, request.Authors)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "Failed to update post",
-			"message": fmt.Sprintf("Error occurred while updating post: %v", err),
+			"error":   "Failed to update post authors",
+			"message": fmt.Sprintf("Error occurred while updating authors: %v", err),
 		})
 	}
 
-	return c.JSON(updatedPost)
+	return c.JSON(authors)
 }
 
-func (h *postHandler) DeletePostHandler(c *fiber.Ctx) error {
-	id := c.Params("id")
+// SavePostTranslationHandler creates or replaces the translation of a post in the
+// language of the path.
+func (h *postHandler) SavePostTranslationHandler(c *fiber.Ctx) error {
+	postId := c.Params("postId")
+	var translation types.PostTranslation
+
+	if err := c.BodyParser(&translation); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request payload",
+			"message": fmt.Sprintf("Error parsing translation data: %v", err),
+		})
+	}
+
+	translation.Locale = strings.ToLower(c.Params("locale"))
+	if err := translation.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": err,
+		})
+	}
 
 	user, ok := c.Locals("user").(types.User)
 	if !ok {
@@ -199,95 +1376,133 @@ func (h *postHandler) DeletePostHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	existingPost, err := h.postRepository.FindById(id)
+	existingPost, err := h.postRepository.FindById(postId)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error":   "Post not found",
-			"message": fmt.Sprintf("No post found with ID: %s", id),
+			"message": fmt.Sprintf("No post found with ID: %s", postId),
 		})
 	}
 
-	if existingPost.Author.Id != user.Id {
+	if !existingPost.IsAuthor(user.Id) {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "You don't have permission to delete this post",
+			"error": "You don't have permission to translate this post",
 		})
 	}
 
-	if err := h.postRepository.Delete(id); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "Failed to delete post",
-			"message": fmt.Sprintf("Error occurred while deleting post: %v", err),
+	if translation.Locale == existingPost.Locale {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": fiber.Map{"Locale": "original"},
 		})
 	}
 
-	return c.SendStatus(fiber.StatusNoContent)
-}
+	var currentSlug, currentTitle string
+	if current, err := h.postRepository.FindPostTranslation(postId, translation.Locale); err == nil {
+		currentSlug, currentTitle = current.Slug, current.Title
+	}
 
-func (h *postHandler) AssignCategoryToPostHandler(c *fiber.Ctx) error {
-	postId := c.Params("postId")
-	categoryId := c.Params("categoryId")
+	translation.Slug, err = resolveSlug(h.slugService, translation.Slug, currentSlug, translation.Title, currentTitle, translation.Locale)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": fiber.Map{"Slug": err.Error()},
+		})
+	}
+	translation.Summarize()
 
-	err := h.postRepository.AssignCategoryToPost(postId, categoryId)
+	savedTranslation, err := h.postRepository.SavePostTranslation(postId, translation)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "Failed to assign category to post",
-			"message": fmt.Sprintf("Error occurred while assigning category: %v", err),
+			"error":   "Failed to save translation",
+			"message": fmt.Sprintf("Error occurred while saving translation: %v", err),
 		})
 	}
 
-	return c.SendStatus(fiber.StatusCreated)
+	return c.JSON(savedTranslation)
 }
 
-func (h *postHandler) UnassignCategoryFromPostHandler(c *fiber.Ctx) error {
+func (h *postHandler) DeletePostTranslationHandler(c *fiber.Ctx) error {
 	postId := c.Params("postId")
-	categoryId := c.Params("categoryId")
+	locale := strings.ToLower(c.Params("locale"))
 
-	err := h.postRepository.UnassignCategoryFromPost(postId, categoryId)
+	user, ok := c.Locals("user").(types.User)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	existingPost, err := h.postRepository.FindById(postId)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "Failed to unassign category from post",
-			"message": fmt.Sprintf("Error occurred while unassigning category: %v", err),
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "Post not found",
+			"message": fmt.Sprintf("No post found with ID: %s", postId),
+		})
+	}
+
+	if !existingPost.IsAuthor(user.Id) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "You don't have permission to translate this post",
+		})
+	}
+
+	if err := h.postRepository.DeletePostTranslation(postId, locale); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "Translation not found",
+			"message": fmt.Sprintf("No %s translation found for post: %s", locale, postId),
 		})
 	}
 
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
-func (h *postHandler) GetCategoriesForPostHandler(c *fiber.Ctx) error {
-	postId := c.Params("postId")
+// UnlockPostHandler checks the password of a password protected post and sets the
+// cookie letting the client read it.
+func (h *postHandler) UnlockPostHandler(c *fiber.Ctx) error {
+	slugOrId := c.Params("slugOrId")
 
-	categories, err := h.postRepository.GetCategoriesForPost(postId)
+	post, err := h.findPost(slugOrId, c.Query("lang"))
+	if err == nil && !canRead(c, post) {
+		err = fmt.Errorf("No post found with slug: %s", slugOrId)
+	}
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "Failed to get categories for post",
-			"message": fmt.Sprintf("Error occurred while fetching categories: %v", err),
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "Post not found",
+			"message": err.Error(),
 		})
 	}
 
-	return c.JSON(categories)
-}
-
-func (h *postHandler) UpdatePostCategoriesHandler(c *fiber.Ctx) error {
-	postId := c.Params("postId")
+	if post.Visibility != types.VisibilityPassword {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Post not password protected",
+			"message": fmt.Sprintf("Post %s can be read without a password", post.Id),
+		})
+	}
 
 	var request struct {
-		CategoryIds []string `json:"categoryIds"`
+		Password string `json:"password"`
 	}
-
 	if err := c.BodyParser(&request); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error":   "Invalid request payload",
-			"message": fmt.Sprintf("Error parsing request body: %v", err),
+			"message": fmt.Sprintf("Error parsing password: %v", err),
 		})
 	}
 
-	err := h.postRepository.UpdatePostCategories(postId, request.CategoryIds)
+	cookie, err := h.postUnlockService.Unlock(*post, request.Password)
+	if errors.Is(err, service.ErrTooManyAttempts) {
+		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+			"error":   "Too many attempts",
+			"message": "Too many wrong passwords, try unlocking the post again later",
+		})
+	}
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "Failed to update post categories",
-			"message": fmt.Sprintf("Error occurred while updating categories: %v", err),
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Wrong password",
 		})
 	}
 
-	return c.SendStatus(fiber.StatusOK)
+	c.Cookie(cookie)
+	return c.SendStatus(fiber.StatusNoContent)
 }
@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"fmt"
+	"go-blog/internal/types"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// findSharedPost returns the post of the request when the user is one of its authors or
+// an editor, who are the only ones sharing it with preview links. Otherwise it answers
+// the request and reports false.
+func (h *postHandler) findSharedPost(c *fiber.Ctx, user types.User) (*types.Post, bool, error) {
+	id := c.Params("id")
+
+	post, err := h.postRepository.FindById(id)
+	if err != nil {
+		return nil, false, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "Post not found",
+			"message": fmt.Sprintf("No post found with ID: %s", id),
+		})
+	}
+
+	if !post.IsAuthor(user.Id) && !user.HasRole(types.RoleEditor) {
+		return nil, false, c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "You don't have permission to share this post",
+		})
+	}
+
+	return post, true, nil
+}
+
+// CreatePreviewLinkHandler creates a link showing the post, whatever its status, to
+// anyone with it. The link lasts expiresIn, a duration such as "48h", or PREVIEW_LINK_TTL.
+func (h *postHandler) CreatePreviewLinkHandler(c *fiber.Ctx) error {
+	var request struct {
+		ExpiresIn string `json:"expiresIn"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   "Invalid request payload",
+				"message": fmt.Sprintf("Error parsing preview link: %v", err),
+			})
+		}
+	}
+
+	var ttl time.Duration
+	if request.ExpiresIn != "" {
+		var err error
+		if ttl, err = time.ParseDuration(request.ExpiresIn); err != nil || ttl <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Validation failed",
+				"fails": fiber.Map{"ExpiresIn": "duration"},
+			})
+		}
+	}
+
+	user, ok := c.Locals("user").(types.User)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	post, ok, err := h.findSharedPost(c, user)
+	if !ok {
+		return err
+	}
+
+	link, err := h.previewLinkService.Create(post.Id, user.Id, ttl)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to create preview link",
+			"message": fmt.Sprintf("Error occurred while creating preview link: %v", err),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(link)
+}
+
+// GetPreviewLinksHandler lists the links to the post which haven't expired.
+func (h *postHandler) GetPreviewLinksHandler(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(types.User)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	post, ok, err := h.findSharedPost(c, user)
+	if !ok {
+		return err
+	}
+
+	links, err := h.previewLinkService.FindActiveByPost(post.Id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to retrieve preview links",
+			"message": fmt.Sprintf("Error occurred while fetching preview links: %v", err),
+		})
+	}
+
+	return c.JSON(links)
+}
+
+// RevokePreviewLinkHandler revokes a link to the post, which stops showing it at once.
+func (h *postHandler) RevokePreviewLinkHandler(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(types.User)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	post, ok, err := h.findSharedPost(c, user)
+	if !ok {
+		return err
+	}
+
+	if err := h.previewLinkService.Revoke(post.Id, c.Params("linkId")); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "Preview link not found",
+			"message": err.Error(),
+		})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GetPreviewHandler shows the post of a preview link as GetPostHandler would, drafts
+// included and without a session. Previews are neither indexed nor counted as views.
+func (h *postHandler) GetPreviewHandler(c *fiber.Ctx) error {
+	link, err := h.previewLinkService.Resolve(c.Params("token"))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "Preview not found",
+			"message": "The preview link is invalid, expired or was revoked",
+		})
+	}
+
+	post, err := h.postRepository.FindById(link.PostId)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "Post not found",
+			"message": fmt.Sprintf("No post found with ID: %s", link.PostId),
+		})
+	}
+
+	post, err = h.localize(c, post, post.Id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to retrieve translation",
+			"message": fmt.Sprintf("Error occurred while fetching translation: %v", err),
+		})
+	}
+
+	if ok, err := h.completePost(c, post); !ok {
+		return err
+	}
+
+	c.Set("X-Robots-Tag", "noindex")
+	return c.JSON(post)
+}
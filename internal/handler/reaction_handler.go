@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"fmt"
+	"go-blog/internal/repository"
+	"go-blog/internal/service"
+	"go-blog/internal/types"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ReactionHandler interface {
+	AddReactionHandler(c *fiber.Ctx) error
+	RemoveReactionHandler(c *fiber.Ctx) error
+}
+
+type reactionHandler struct {
+	reactionRepository repository.ReactionRepository
+	postRepository     repository.PostRepository
+	postUnlockService  service.PostUnlockService
+}
+
+func NewReactionHandler(reactionRepository repository.ReactionRepository, postRepository repository.PostRepository, postUnlockService service.PostUnlockService) ReactionHandler {
+	return &reactionHandler{reactionRepository, postRepository, postUnlockService}
+}
+
+// markViewerReactions flags the reactions the authenticated caller, if any, left on the posts.
+func markViewerReactions(c *fiber.Ctx, reactionRepository repository.ReactionRepository, posts []types.Post) error {
+	user, ok := c.Locals("user").(types.User)
+	if !ok || len(posts) == 0 {
+		return nil
+	}
+
+	postIds := make([]string, len(posts))
+	for i, post := range posts {
+		postIds[i] = post.Id
+	}
+
+	reacted, err := reactionRepository.FindUserReactions(user.Id, postIds)
+	if err != nil {
+		return err
+	}
+
+	for i := range posts {
+		for j := range posts[i].Reactions {
+			posts[i].Reactions[j].Reacted = reacted[posts[i].Id][posts[i].Reactions[j].Type]
+		}
+	}
+
+	return nil
+}
+
+func (h *reactionHandler) AddReactionHandler(c *fiber.Ctx) error {
+	return h.toggleReaction(c, true)
+}
+
+func (h *reactionHandler) RemoveReactionHandler(c *fiber.Ctx) error {
+	return h.toggleReaction(c, false)
+}
+
+func (h *reactionHandler) toggleReaction(c *fiber.Ctx, add bool) error {
+	postId := c.Params("id")
+	reactionType := c.Params("type")
+
+	if !types.IsReactionType(reactionType) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid reaction type",
+			"message": fmt.Sprintf("Reaction type must be one of %v", types.ReactionTypes),
+		})
+	}
+
+	user, ok := c.Locals("user").(types.User)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	if _, ok, err := findReadablePost(c, h.postRepository, h.postUnlockService, postId); !ok {
+		return err
+	}
+
+	var err error
+	if add {
+		err = h.reactionRepository.Add(postId, user.Id, reactionType)
+	} else {
+		err = h.reactionRepository.Remove(postId, user.Id, reactionType)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to update reaction",
+			"message": fmt.Sprintf("Error occurred while updating reaction: %v", err),
+		})
+	}
+
+	reactions, err := h.reactionRepository.CountByPost(postId)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to retrieve reactions",
+			"message": fmt.Sprintf("Error occurred while fetching reactions: %v", err),
+		})
+	}
+
+	posts := []types.Post{{Id: postId, Reactions: reactions}}
+	if err := markViewerReactions(c, h.reactionRepository, posts); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to retrieve reactions",
+			"message": fmt.Sprintf("Error occurred while fetching reactions: %v", err),
+		})
+	}
+
+	return c.JSON(posts[0].Reactions)
+}
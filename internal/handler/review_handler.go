@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"go-blog/internal/repository"
+	"go-blog/internal/service"
+	"go-blog/internal/types"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ReviewHandler interface {
+	GetReviewQueueHandler(c *fiber.Ctx) error
+	GetReviewsHandler(c *fiber.Ctx) error
+	CreateReviewHandler(c *fiber.Ctx) error
+	AssignReviewerHandler(c *fiber.Ctx) error
+}
+
+type reviewHandler struct {
+	reviewRepository   repository.ReviewRepository
+	postRepository     repository.PostRepository
+	userRepository     repository.UserRepository
+	relatedPostService service.RelatedPostService
+}
+
+func NewReviewHandler(reviewRepository repository.ReviewRepository, postRepository repository.PostRepository, userRepository repository.UserRepository, relatedPostService service.RelatedPostService) ReviewHandler {
+	return &reviewHandler{reviewRepository, postRepository, userRepository, relatedPostService}
+}
+
+// GetReviewQueueHandler lists the posts waiting for review, the ones waiting longest
+// first. The reviewer query narrows the queue down to the posts assigned to a reviewer,
+// or to the user with "me".
+func (h *reviewHandler) GetReviewQueueHandler(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(types.User)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	reviewerId := c.Query("reviewer")
+	if reviewerId == "me" {
+		reviewerId = user.Id
+	}
+
+	posts, err := h.postRepository.FindReviewQueue(reviewerId)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to retrieve review queue",
+			"message": fmt.Sprintf("Error occurred while fetching posts to review: %v", err),
+		})
+	}
+
+	if posts == nil {
+		posts = []types.Post{}
+	}
+	omitContent(c, posts)
+
+	return c.JSON(posts)
+}
+
+// findReviewedPost returns the post of the request when the user is one of its authors
+// or an editor, who are the only ones taking part in its review. Otherwise it answers
+// the request and reports false.
+func (h *reviewHandler) findReviewedPost(c *fiber.Ctx, user types.User) (*types.Post, bool, error) {
+	id := c.Params("id")
+
+	post, err := h.postRepository.FindById(id)
+	if err != nil {
+		return nil, false, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "Post not found",
+			"message": fmt.Sprintf("No post found with ID: %s", id),
+		})
+	}
+
+	if !post.IsAuthor(user.Id) && !user.HasRole(types.RoleEditor) {
+		return nil, false, c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "You don't have permission to review this post",
+		})
+	}
+
+	return post, true, nil
+}
+
+// GetReviewsHandler lists the review history of the post, oldest first.
+func (h *reviewHandler) GetReviewsHandler(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(types.User)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	post, ok, err := h.findReviewedPost(c, user)
+	if !ok {
+		return err
+	}
+
+	reviews, err := h.reviewRepository.FindByPost(post.Id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to retrieve reviews",
+			"message": fmt.Sprintf("Error occurred while fetching reviews: %v", err),
+		})
+	}
+
+	return c.JSON(reviews)
+}
+
+// CreateReviewHandler moves the post along the review workflow, leaves a comment on it,
+// or both. Authors submit their posts and publish them once approved, editors request
+// changes or approve them, see types.Post.CanChangeStatus.
+func (h *reviewHandler) CreateReviewHandler(c *fiber.Ctx) error {
+	var review types.Review
+
+	if err := c.BodyParser(&review); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request payload",
+			"message": fmt.Sprintf("Error parsing review: %v", err),
+		})
+	}
+
+	if err := review.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": err,
+		})
+	}
+
+	user, ok := c.Locals("user").(types.User)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	post, ok, err := h.findReviewedPost(c, user)
+	if !ok {
+		return err
+	}
+
+	if review.Status == "" {
+		review.Status = post.Status
+	}
+	if !post.CanChangeStatus(user, review.Status) {
+		return statusChangeForbidden(c, post.Status, review.Status)
+	}
+
+	review.PostId = post.Id
+	review.Author = user
+
+	var createdReview *types.Review
+	if review.Status != post.Status {
+		// The status only changes along with the review recording it
+		published := post.IsPublished()
+		createdReview, err = h.reviewRepository.CreateWithStatus(review, post.Version)
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return preconditionFailed(c)
+		}
+		post.Status = review.Status
+
+		if err == nil && published != post.IsPublished() {
+			h.relatedPostService.Invalidate()
+		}
+	} else {
+		createdReview, err = h.reviewRepository.Create(review)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to create review",
+			"message": fmt.Sprintf("Error occurred while saving review: %v", err),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(createdReview)
+}
+
+// AssignReviewerHandler assigns the post to an editor for review, or to no one when the
+// reviewerId is empty.
+func (h *reviewHandler) AssignReviewerHandler(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var request struct {
+		ReviewerId string `json:"reviewerId"`
+	}
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request payload",
+			"message": fmt.Sprintf("Error parsing reviewer: %v", err),
+		})
+	}
+
+	if _, err := h.postRepository.FindById(id); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "Post not found",
+			"message": fmt.Sprintf("No post found with ID: %s", id),
+		})
+	}
+
+	if request.ReviewerId != "" {
+		reviewer, err := h.userRepository.FindById(request.ReviewerId)
+		if err != nil || !reviewer.HasRole(types.RoleEditor) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Validation failed",
+				"fails": fiber.Map{"ReviewerId": "editor"},
+			})
+		}
+	}
+
+	if err := h.postRepository.AssignReviewer(id, request.ReviewerId); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to assign reviewer",
+			"message": fmt.Sprintf("Error occurred while assigning reviewer: %v", err),
+		})
+	}
+
+	post, err := h.postRepository.FindById(id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to retrieve post",
+			"message": fmt.Sprintf("Error occurred while fetching post: %v", err),
+		})
+	}
+
+	c.Set(fiber.HeaderETag, entityTag(post.Version, post.Locale))
+	return c.JSON(post)
+}
@@ -0,0 +1,289 @@
+package handler
+
+import (
+	"fmt"
+	"go-blog/internal/repository"
+	"go-blog/internal/service"
+	"go-blog/internal/types"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type SeriesHandler interface {
+	GetSeriesHandler(c *fiber.Ctx) error
+	CreateSeriesHandler(c *fiber.Ctx) error
+	UpdateSeriesHandler(c *fiber.Ctx) error
+	DeleteSeriesHandler(c *fiber.Ctx) error
+	UpdateSeriesPostsHandler(c *fiber.Ctx) error
+}
+
+type seriesHandler struct {
+	seriesRepository repository.SeriesRepository
+	postRepository   repository.PostRepository
+	slugService      service.SlugService
+}
+
+func NewSeriesHandler(seriesRepository repository.SeriesRepository, postRepository repository.PostRepository, slugService service.SlugService) SeriesHandler {
+	return &seriesHandler{seriesRepository, postRepository, slugService}
+}
+
+// canManageSeries reports whether the user may change the series: its owner and editors can.
+func canManageSeries(user types.User, series *types.Series) bool {
+	return series.OwnerId == user.Id || user.HasRole(types.RoleEditor)
+}
+
+func (h *seriesHandler) GetSeriesHandler(c *fiber.Ctx) error {
+	slugOrId := c.Params("slugOrId")
+
+	if slugOrId != "" {
+		var series *types.Series
+
+		_, err := uuid.Parse(slugOrId)
+		if err == nil {
+			series, err = h.seriesRepository.FindById(slugOrId)
+			if err != nil {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"error":   "Series not found",
+					"message": fmt.Sprintf("No series found with id: %s", slugOrId),
+				})
+			}
+		} else {
+			series, err = h.seriesRepository.FindBySlug(slugOrId)
+			if err != nil {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"error":   "Series not found",
+					"message": fmt.Sprintf("No series found with slug: %s", slugOrId),
+				})
+			}
+		}
+
+		if series.Slug != slugOrId && series.Id != slugOrId {
+			return redirectToSlug(c, slugOrId, series.Slug)
+		}
+
+		return c.JSON(series)
+	}
+
+	seriesList, err := h.seriesRepository.FindAll()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to retrieve series",
+			"message": fmt.Sprintf("Error occurred while fetching series: %v", err),
+		})
+	}
+
+	return c.JSON(seriesList)
+}
+
+func (h *seriesHandler) CreateSeriesHandler(c *fiber.Ctx) error {
+	var series types.Series
+
+	if err := c.BodyParser(&series); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request payload",
+			"message": fmt.Sprintf("Error parsing series data: %v", err),
+		})
+	}
+
+	if err := series.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": err,
+		})
+	}
+
+	user, ok := c.Locals("user").(types.User)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	slug, err := resolveSlug(h.slugService, series.Slug, "", series.Title, "", "")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": fiber.Map{"Slug": err.Error()},
+		})
+	}
+	series.Slug = slug
+	series.OwnerId = user.Id
+
+	createdSeries, err := h.seriesRepository.Create(series)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to create series",
+			"message": fmt.Sprintf("Error occurred while creating series: %v", err),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(createdSeries)
+}
+
+func (h *seriesHandler) UpdateSeriesHandler(c *fiber.Ctx) error {
+	id := c.Params("id")
+	var series types.Series
+
+	if err := c.BodyParser(&series); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request payload",
+			"message": fmt.Sprintf("Error parsing series data: %v", err),
+		})
+	}
+
+	if err := series.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": err,
+		})
+	}
+
+	user, ok := c.Locals("user").(types.User)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	existingSeries, err := h.seriesRepository.FindById(id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "Series not found",
+			"message": fmt.Sprintf("No series found with ID: %s", id),
+		})
+	}
+
+	if !canManageSeries(user, existingSeries) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "You don't have permission to update this series",
+		})
+	}
+
+	// Former slugs keep redirecting to the series
+	series.Slug, err = resolveSlug(h.slugService, series.Slug, existingSeries.Slug, series.Title, existingSeries.Title, "")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": fiber.Map{"Slug": err.Error()},
+		})
+	}
+
+	updatedSeries, err := h.seriesRepository.Update(id, series)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to update series",
+			"message": fmt.Sprintf("Error occurred while updating series: %v", err),
+		})
+	}
+
+	return c.JSON(updatedSeries)
+}
+
+func (h *seriesHandler) DeleteSeriesHandler(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	user, ok := c.Locals("user").(types.User)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	existingSeries, err := h.seriesRepository.FindById(id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "Series not found",
+			"message": fmt.Sprintf("No series found with ID: %s", id),
+		})
+	}
+
+	if !canManageSeries(user, existingSeries) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "You don't have permission to delete this series",
+		})
+	}
+
+	if err := h.seriesRepository.Delete(id); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to delete series",
+			"message": fmt.Sprintf("Error occurred while deleting series: %v", err),
+		})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *seriesHandler) UpdateSeriesPostsHandler(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var request struct {
+		PostIds []string `json:"postIds"`
+	}
+
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request payload",
+			"message": fmt.Sprintf("Error parsing request body: %v", err),
+		})
+	}
+
+	user, ok := c.Locals("user").(types.User)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	existingSeries, err := h.seriesRepository.FindById(id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "Series not found",
+			"message": fmt.Sprintf("No series found with ID: %s", id),
+		})
+	}
+
+	if !canManageSeries(user, existingSeries) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "You don't have permission to update this series",
+		})
+	}
+
+	// Only posts the user may edit can be added, so nobody can attach someone else's post
+	fails := make(map[string]string)
+	seen := make(map[string]bool)
+	for _, postId := range request.PostIds {
+		if seen[postId] {
+			fails[postId] = "unique"
+			continue
+		}
+		seen[postId] = true
+
+		post, err := h.postRepository.FindById(postId)
+		if err != nil {
+			fails[postId] = "exists"
+			continue
+		}
+
+		if !post.IsAuthor(user.Id) && !user.HasRole(types.RoleEditor) {
+			fails[postId] = "author"
+		}
+	}
+
+	if len(fails) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": fiber.Map{"PostIds": fails},
+		})
+	}
+
+	parts, err := h.seriesRepository.UpdateSeriesPosts(id, request.PostIds)
+	if err != nil {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":   "Failed to update series posts",
+			"message": fmt.Sprintf("Error occurred while updating series posts, a post can only be part of one series: %v", err),
+		})
+	}
+
+	return c.JSON(parts)
+}
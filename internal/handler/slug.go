@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"fmt"
+	"go-blog/internal/service"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// resolveSlug picks the slug to save. An explicit slug wins and is validated, otherwise
+// the slug is derived from the title, transliterated for language when known, unless
+// the title didn't change.
+func resolveSlug(slugService service.SlugService, requested, current, title, currentTitle, language string) (string, error) {
+	switch {
+	case requested != "" && requested != current:
+		if err := slugService.ValidateCustom(requested); err != nil {
+			return "", err
+		}
+		return requested, nil
+	case requested != "":
+		return current, nil
+	case current != "" && title == currentTitle:
+		return current, nil
+	default:
+		return slugService.Generate(title, language), nil
+	}
+}
+
+// redirectToSlug answers a request made with a former slug with a permanent redirect
+// to the same URL using the current slug, keeping the query string.
+func redirectToSlug(c *fiber.Ctx, oldSlug, currentSlug string) error {
+	segments := strings.Split(c.Path(), "/")
+	for i, segment := range segments {
+		if segment == oldSlug {
+			segments[i] = currentSlug
+			break
+		}
+	}
+
+	location := strings.Join(segments, "/")
+	if query := string(c.Request().URI().QueryString()); query != "" {
+		location += "?" + query
+	}
+
+	c.Location(location)
+	return c.Status(fiber.StatusMovedPermanently).JSON(fiber.Map{
+		"message": fmt.Sprintf("%s has moved to %s", oldSlug, currentSlug),
+		"slug":    currentSlug,
+	})
+}
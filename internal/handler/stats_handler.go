@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"fmt"
+	"go-blog/internal/repository"
+	"go-blog/internal/types"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const maxStatsDays = 365
+
+type StatsHandler interface {
+	GetPostStatsHandler(c *fiber.Ctx) error
+	GetTopPostsHandler(c *fiber.Ctx) error
+}
+
+type statsHandler struct {
+	viewRepository repository.ViewRepository
+	postRepository repository.PostRepository
+}
+
+func NewStatsHandler(viewRepository repository.ViewRepository, postRepository repository.PostRepository) StatsHandler {
+	return &statsHandler{viewRepository, postRepository}
+}
+
+// statsRange returns the first and last day covered by the ?days= query parameter.
+func statsRange(c *fiber.Ctx, defaultDays int) (time.Time, time.Time) {
+	days, _ := strconv.Atoi(c.Query("days", strconv.Itoa(defaultDays)))
+	if days < 1 {
+		days = defaultDays
+	}
+	if days > maxStatsDays {
+		days = maxStatsDays
+	}
+
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	return to.AddDate(0, 0, 1-days), to
+}
+
+func (h *statsHandler) GetPostStatsHandler(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	user, ok := c.Locals("user").(types.User)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	post, err := h.postRepository.FindById(id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "Post not found",
+			"message": fmt.Sprintf("No post found with ID: %s", id),
+		})
+	}
+
+	if !post.IsAuthor(user.Id) && !user.HasRole(types.RoleEditor) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "You don't have permission to view the stats of this post",
+		})
+	}
+
+	from, to := statsRange(c, 30)
+
+	stats, err := h.viewRepository.FindPostStats(id, from, to)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to retrieve stats",
+			"message": fmt.Sprintf("Error occurred while fetching stats: %v", err),
+		})
+	}
+
+	return c.JSON(stats)
+}
+
+func (h *statsHandler) GetTopPostsHandler(c *fiber.Ctx) error {
+	from, to := statsRange(c, 7)
+
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	topPosts, err := h.viewRepository.FindTopPosts(from, to, limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to retrieve top posts",
+			"message": fmt.Sprintf("Error occurred while fetching top posts: %v", err),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"meta": fiber.Map{
+			"from":  from.Format("2006-01-02"),
+			"to":    to.Format("2006-01-02"),
+			"limit": limit,
+		},
+		"data": topPosts,
+	})
+}
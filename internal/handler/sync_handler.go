@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go-blog/internal/repository"
+	"go-blog/internal/service"
+	"go-blog/internal/types"
+	"os"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const defaultGitSyncBranch = "main"
+
+type SyncHandler interface {
+	GitSyncHandler(c *fiber.Ctx) error
+}
+
+type syncHandler struct {
+	gitSyncService     service.GitSyncService
+	userRepository     repository.UserRepository
+	relatedPostService service.RelatedPostService
+	repoPath           string
+	branch             string
+	secret             string
+	authorEmail        string
+}
+
+// NewSyncHandler syncs the posts with the branch GIT_SYNC_BRANCH of the local git
+// repository at GIT_SYNC_REPO. Requests must carry GIT_SYNC_SECRET, and new posts whose
+// author isn't a user are credited to the user with the email GIT_SYNC_AUTHOR.
+func NewSyncHandler(gitSyncService service.GitSyncService, userRepository repository.UserRepository, relatedPostService service.RelatedPostService) SyncHandler {
+	branch := os.Getenv("GIT_SYNC_BRANCH")
+	if branch == "" {
+		branch = defaultGitSyncBranch
+	}
+
+	return &syncHandler{
+		gitSyncService:     gitSyncService,
+		userRepository:     userRepository,
+		relatedPostService: relatedPostService,
+		repoPath:           os.Getenv("GIT_SYNC_REPO"),
+		branch:             branch,
+		secret:             os.Getenv("GIT_SYNC_SECRET"),
+		authorEmail:        os.Getenv("GIT_SYNC_AUTHOR"),
+	}
+}
+
+// GitSyncHandler is a webhook syncing the posts with the git repository, for a
+// post-receive hook or a push webhook of a git host. The secret is either sent as is in
+// the X-Sync-Secret header, or signs the body in the X-Hub-Signature-256 header as
+// GitHub and Gitea do. Pushes to other branches are ignored.
+func (h *syncHandler) GitSyncHandler(c *fiber.Ctx) error {
+	if h.repoPath == "" || h.secret == "" {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Git sync is not configured",
+		})
+	}
+
+	if !h.authorized(c) {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Invalid secret",
+		})
+	}
+
+	var event struct {
+		Ref string `json:"ref"`
+	}
+	if json.Unmarshal(c.Body(), &event) == nil && event.Ref != "" && event.Ref != "refs/heads/"+h.branch {
+		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+			"message": fmt.Sprintf("Only pushes to %s are synced", h.branch),
+		})
+	}
+
+	var fallbackAuthor *types.User
+	if h.authorEmail != "" {
+		if author, err := h.userRepository.FindByEmail(h.authorEmail); err == nil {
+			fallbackAuthor = author
+		}
+	}
+
+	report, err := h.gitSyncService.Sync(h.repoPath, h.branch, fallbackAuthor)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to sync",
+			"message": fmt.Sprintf("Error occurred while syncing: %v", err),
+		})
+	}
+
+	if report.Created > 0 || report.Updated > 0 || report.Archived > 0 {
+		h.relatedPostService.Invalidate()
+	}
+
+	return c.JSON(report)
+}
+
+func (h *syncHandler) authorized(c *fiber.Ctx) bool {
+	if signature := c.Get("X-Hub-Signature-256"); signature != "" {
+		mac := hmac.New(sha256.New, []byte(h.secret))
+		mac.Write(c.Body())
+		return hmac.Equal([]byte(signature), []byte("sha256="+hex.EncodeToString(mac.Sum(nil))))
+	}
+
+	return subtle.ConstantTimeCompare([]byte(c.Get("X-Sync-Secret")), []byte(h.secret)) == 1
+}
@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"fmt"
+	"go-blog/internal/repository"
+	"go-blog/internal/service"
+	"go-blog/internal/types"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type TagHandler interface {
+	GetTagsHandler(c *fiber.Ctx) error
+	GetTagPostsHandler(c *fiber.Ctx) error
+	RenameTagHandler(c *fiber.Ctx) error
+	MergeTagHandler(c *fiber.Ctx) error
+}
+
+type tagHandler struct {
+	tagRepository      repository.TagRepository
+	postRepository     repository.PostRepository
+	slugService        service.SlugService
+	relatedPostService service.RelatedPostService
+	postUnlockService  service.PostUnlockService
+}
+
+func NewTagHandler(tagRepository repository.TagRepository, postRepository repository.PostRepository, slugService service.SlugService, relatedPostService service.RelatedPostService, postUnlockService service.PostUnlockService) TagHandler {
+	return &tagHandler{tagRepository, postRepository, slugService, relatedPostService, postUnlockService}
+}
+
+// normalizeTags trims tag names, derives their slugs and drops duplicates.
+// It returns validation failures keyed by the offending tag name.
+func normalizeTags(slugService service.SlugService, tags []types.Tag) ([]types.Tag, map[string]string) {
+	normalized := []types.Tag{}
+	fails := make(map[string]string)
+	seen := make(map[string]bool)
+
+	for _, tag := range tags {
+		tag.Name = strings.TrimSpace(tag.Name)
+		if errs := tag.Validate(); errs != nil {
+			fails[tag.Name] = errs["Name"]
+			continue
+		}
+
+		tag.Slug = slugService.Generate(tag.Name, "")
+		if seen[tag.Slug] {
+			continue
+		}
+		seen[tag.Slug] = true
+		normalized = append(normalized, types.Tag{Name: tag.Name, Slug: tag.Slug})
+	}
+
+	if len(fails) > 0 {
+		return nil, fails
+	}
+
+	return normalized, nil
+}
+
+func (h *tagHandler) GetTagsHandler(c *fiber.Ctx) error {
+	tags, err := h.tagRepository.FindAll()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to retrieve tags",
+			"message": fmt.Sprintf("Error occurred while fetching tags: %v", err),
+		})
+	}
+
+	return c.JSON(tags)
+}
+
+func (h *tagHandler) GetTagPostsHandler(c *fiber.Ctx) error {
+	slug := c.Params("slug")
+
+	tag, err := h.tagRepository.FindBySlug(slug)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "Tag not found",
+			"message": fmt.Sprintf("No tag found with slug: %s", slug),
+		})
+	}
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+
+	posts, totalCount, err := h.postRepository.FindAllByTag(tag.Slug, page, limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to retrieve posts",
+			"message": fmt.Sprintf("Error occurred while fetching posts: %v", err),
+		})
+	}
+
+	lockPosts(c, h.postUnlockService, posts)
+	omitContent(c, posts)
+
+	totalPages := (totalCount + limit - 1) / limit
+
+	return c.JSON(fiber.Map{
+		"meta": fiber.Map{
+			"page":       page,
+			"limit":      limit,
+			"totalCount": totalCount,
+			"totalPages": totalPages,
+		},
+		"tag":  tag,
+		"data": posts,
+	})
+}
+
+func (h *tagHandler) RenameTagHandler(c *fiber.Ctx) error {
+	id := c.Params("id")
+	var tag types.Tag
+
+	if err := c.BodyParser(&tag); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request payload",
+			"message": fmt.Sprintf("Error parsing tag data: %v", err),
+		})
+	}
+
+	tags, fails := normalizeTags(h.slugService, []types.Tag{tag})
+	if fails != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": fails,
+		})
+	}
+
+	_, err := h.tagRepository.FindById(id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "Tag not found",
+			"message": fmt.Sprintf("No tag found with ID: %s", id),
+		})
+	}
+
+	renamedTag, err := h.tagRepository.Rename(id, tags[0])
+	if err != nil {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":   "Failed to rename tag",
+			"message": fmt.Sprintf("Error occurred while renaming tag: %v", err),
+		})
+	}
+
+	return c.JSON(renamedTag)
+}
+
+func (h *tagHandler) MergeTagHandler(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var request struct {
+		TargetId string `json:"targetId"`
+	}
+
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request payload",
+			"message": fmt.Sprintf("Error parsing request body: %v", err),
+		})
+	}
+
+	// The repository refuses to merge a tag into itself, but it is the request that is wrong
+	if request.TargetId == id {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": fiber.Map{"TargetId": "must differ from the merged tag"},
+		})
+	}
+
+	if _, err := h.tagRepository.FindById(id); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "Tag not found",
+			"message": fmt.Sprintf("No tag found with ID: %s", id),
+		})
+	}
+
+	target, err := h.tagRepository.FindById(request.TargetId)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "Target tag not found",
+			"message": fmt.Sprintf("No tag found with ID: %s", request.TargetId),
+		})
+	}
+
+	if err := h.tagRepository.Merge(id, target.Id); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to merge tags",
+			"message": fmt.Sprintf("Error occurred while merging tags: %v", err),
+		})
+	}
+
+	h.relatedPostService.Invalidate()
+
+	return c.JSON(target)
+}
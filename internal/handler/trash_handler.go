@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"fmt"
+	"go-blog/internal/repository"
+	"go-blog/internal/service"
+	"go-blog/internal/types"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type TrashHandler interface {
+	GetTrashHandler(c *fiber.Ctx) error
+	RestorePostHandler(c *fiber.Ctx) error
+	PurgePostHandler(c *fiber.Ctx) error
+	RestoreCategoryHandler(c *fiber.Ctx) error
+	PurgeCategoryHandler(c *fiber.Ctx) error
+}
+
+type trashHandler struct {
+	postRepository     repository.PostRepository
+	categoryRepository repository.CategoryRepository
+	relatedPostService service.RelatedPostService
+}
+
+func NewTrashHandler(postRepository repository.PostRepository, categoryRepository repository.CategoryRepository, relatedPostService service.RelatedPostService) TrashHandler {
+	return &trashHandler{postRepository, categoryRepository, relatedPostService}
+}
+
+// GetTrashHandler lists the trashed posts of the user, or of everyone for editors,
+// along with the trashed categories.
+func (h *trashHandler) GetTrashHandler(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(types.User)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	ownerId := user.Id
+	if user.HasRole(types.RoleEditor) {
+		ownerId = ""
+	}
+
+	posts, err := h.postRepository.FindDeleted(ownerId)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to retrieve trash",
+			"message": fmt.Sprintf("Error occurred while fetching deleted posts: %v", err),
+		})
+	}
+
+	categories, err := h.categoryRepository.FindDeleted()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to retrieve trash",
+			"message": fmt.Sprintf("Error occurred while fetching deleted categories: %v", err),
+		})
+	}
+
+	if posts == nil {
+		posts = []types.Post{}
+	}
+	omitContent(c, posts)
+
+	return c.JSON(fiber.Map{
+		"posts":      posts,
+		"categories": categories,
+	})
+}
+
+func (h *trashHandler) RestorePostHandler(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	user, ok := c.Locals("user").(types.User)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	post, err := h.postRepository.FindDeletedById(id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "Post not found",
+			"message": fmt.Sprintf("No deleted post found with ID: %s", id),
+		})
+	}
+
+	if !post.IsOwner(user.Id) && !user.HasRole(types.RoleEditor) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "You don't have permission to restore this post",
+		})
+	}
+
+	if err := h.postRepository.Restore(id); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to restore post",
+			"message": fmt.Sprintf("Error occurred while restoring post: %v", err),
+		})
+	}
+
+	h.relatedPostService.Invalidate()
+
+	post.DeletedAt = nil
+	return c.JSON(post)
+}
+
+func (h *trashHandler) PurgePostHandler(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	user, ok := c.Locals("user").(types.User)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	post, err := h.postRepository.FindDeletedById(id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "Post not found",
+			"message": fmt.Sprintf("No deleted post found with ID: %s", id),
+		})
+	}
+
+	if !post.IsOwner(user.Id) && !user.HasRole(types.RoleEditor) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "You don't have permission to purge this post",
+		})
+	}
+
+	if err := h.postRepository.Purge(id); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to purge post",
+			"message": fmt.Sprintf("Error occurred while purging post: %v", err),
+		})
+	}
+
+	h.relatedPostService.Invalidate()
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *trashHandler) RestoreCategoryHandler(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	if err := h.categoryRepository.Restore(id); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "Category not found",
+			"message": fmt.Sprintf("No deleted category found with ID: %s", id),
+		})
+	}
+
+	h.relatedPostService.Invalidate()
+
+	category, err := h.categoryRepository.FindById(id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to retrieve category",
+			"message": fmt.Sprintf("Error occurred while fetching restored category: %v", err),
+		})
+	}
+
+	return c.JSON(category)
+}
+
+func (h *trashHandler) PurgeCategoryHandler(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	if err := h.categoryRepository.Purge(id); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "Category not found",
+			"message": fmt.Sprintf("No deleted category found with ID: %s", id),
+		})
+	}
+
+	h.relatedPostService.Invalidate()
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
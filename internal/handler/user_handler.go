@@ -3,6 +3,7 @@ package handler
 import (
 	"fmt"
 	"go-blog/internal/repository"
+	"go-blog/internal/types"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -13,6 +14,7 @@ type userHandler struct {
 
 type UserHandler interface {
 	GetUserHandler(c *fiber.Ctx) error
+	PatchMeHandler(c *fiber.Ctx) error
 }
 
 func NewUserHandler(userRepository repository.UserRepository) UserHandler {
@@ -43,3 +45,48 @@ func (h *userHandler) GetUserHandler(c *fiber.Ctx) error {
 
 	return c.JSON(users)
 }
+
+// PatchMeHandler applies a JSON merge patch to the name, lastname or email of the
+// signed in user.
+func (h *userHandler) PatchMeHandler(c *fiber.Ctx) error {
+	sessionUser, ok := c.Locals("user").(types.User)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	existingUser, err := h.userRepository.FindById(sessionUser.Id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "User not found",
+			"message": fmt.Sprintf("No user found with ID: %s", sessionUser.Id),
+		})
+	}
+
+	var user types.User
+	if _, ok, err := mergePatch(c, existingUser, []string{"name", "lastname", "email"}, &user); !ok {
+		return err
+	}
+
+	if err := user.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"fails": err,
+		})
+	}
+
+	user.Id = existingUser.Id
+	user.Role = existingUser.Role
+	user.CreatedAt = existingUser.CreatedAt
+
+	updatedUser, err := h.userRepository.Patch(user.Id, user)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to update user",
+			"message": fmt.Sprintf("Error occurred while updating user: %v", err),
+		})
+	}
+
+	return c.JSON(updatedUser)
+}
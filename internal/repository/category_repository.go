@@ -3,19 +3,29 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"go-blog/internal/types"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 )
 
+// ErrCategoryCycle is returned when a category would become its own ancestor.
+var ErrCategoryCycle = errors.New("the category can't be nested under itself")
+
 type CategoryRepository interface {
 	FindAll() ([]types.Category, error)
 	FindBySlug(slug string) (*types.Category, error)
 	FindById(id string) (*types.Category, error)
 	Create(category types.Category) (*types.Category, error)
 	Update(id string, category types.Category) (*types.Category, error)
+	Patch(id string, category types.Category) (*types.Category, error)
 	Delete(id string) error
+	FindDeleted() ([]types.Category, error)
+	Restore(id string) error
+	Purge(id string) error
+	PurgeDeletedBefore(before time.Time) (int64, error)
 }
 
 type categoryRepository struct {
@@ -29,8 +39,9 @@ func NewCategoryRepository(db *sql.DB) CategoryRepository {
 func (repo categoryRepository) FindAll() ([]types.Category, error) {
 	var categories []types.Category
 
-	sql, args, err := sq.Select("id, title, slug, created_at").
+	sql, args, err := sq.Select("id, title, slug, parent_id, created_at, version, updated_at").
 		From("categories").
+		Where("deleted_at IS NULL").
 		PlaceholderFormat(sq.Dollar).
 		ToSql()
 
@@ -50,7 +61,10 @@ func (repo categoryRepository) FindAll() ([]types.Category, error) {
 			&category.Id,
 			&category.Title,
 			&category.Slug,
+			&category.ParentId,
 			&category.CreatedAt,
+			&category.Version,
+			&category.UpdatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("error scanning row in FindAll: %v", err)
@@ -66,10 +80,11 @@ func (repo categoryRepository) FindAll() ([]types.Category, error) {
 }
 
 func (repo categoryRepository) FindBySlug(slug string) (*types.Category, error) {
-	query := sq.Select("id, title, slug, created_at").
+	query := sq.Select("id, title, slug, parent_id, created_at, version, updated_at").
 		From("categories").
 		PlaceholderFormat(sq.Dollar).
-		Where(sq.Eq{"slug": slug})
+		Where(sq.Eq{"slug": slug}).
+		Where("deleted_at IS NULL")
 
 	sql, args, err := query.ToSql()
 	if err != nil {
@@ -81,10 +96,18 @@ func (repo categoryRepository) FindBySlug(slug string) (*types.Category, error)
 		&category.Id,
 		&category.Title,
 		&category.Slug,
+		&category.ParentId,
 		&category.CreatedAt,
+		&category.Version,
+		&category.UpdatedAt,
 	)
 
 	if err != nil {
+		// The category may have been renamed, the caller can tell by comparing slugs
+		if categoryId, historyErr := findSlugOwner(repo.db, "category_slug_history", "category_id", slug); historyErr == nil {
+			return repo.FindById(categoryId)
+		}
+
 		return nil, fmt.Errorf("error executing FindBySlug query: %v", err)
 	}
 
@@ -92,10 +115,11 @@ func (repo categoryRepository) FindBySlug(slug string) (*types.Category, error)
 }
 
 func (repo categoryRepository) FindById(id string) (*types.Category, error) {
-	query := sq.Select("id, title, slug, created_at").
+	query := sq.Select("id, title, slug, parent_id, created_at, version, updated_at").
 		From("categories").
 		PlaceholderFormat(sq.Dollar).
-		Where(sq.Eq{"id": id})
+		Where(sq.Eq{"id": id}).
+		Where("deleted_at IS NULL")
 
 	sql, args, err := query.ToSql()
 	if err != nil {
@@ -107,7 +131,10 @@ func (repo categoryRepository) FindById(id string) (*types.Category, error) {
 		&category.Id,
 		&category.Title,
 		&category.Slug,
+		&category.ParentId,
 		&category.CreatedAt,
+		&category.Version,
+		&category.UpdatedAt,
 	)
 
 	if err != nil {
@@ -118,74 +145,197 @@ func (repo categoryRepository) FindById(id string) (*types.Category, error) {
 }
 
 func (repo categoryRepository) Create(category types.Category) (*types.Category, error) {
-	slug, err := repo.generateUniqueSlug(category.Slug, "")
+	tx, err := repo.db.BeginTx(context.Background(), nil)
 	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+
+	slug, err := uniqueSlug(tx, "categories", "category_slug_history", "category_id", category.Slug, "")
+	if err != nil {
+		tx.Rollback()
 		return nil, fmt.Errorf("error generating unique slug: %v", err)
 	}
 
 	insertQuery := sq.Insert("categories").
-		Columns("title", "slug").
-		Values(category.Title, slug).
-		Suffix("RETURNING id, title, slug, created_at").
+		Columns("title", "slug", "parent_id").
+		Values(category.Title, slug, category.ParentId).
+		Suffix("RETURNING id, title, slug, parent_id, created_at, version, updated_at").
 		PlaceholderFormat(sq.Dollar)
 
 	sql, args, err := insertQuery.ToSql()
 	if err != nil {
+		tx.Rollback()
 		return nil, fmt.Errorf("error creating SQL for Create: %v", err)
 	}
 
 	var createdCategory types.Category
 
-	err = repo.db.QueryRowContext(context.Background(), sql, args...).Scan(
+	err = tx.QueryRowContext(context.Background(), sql, args...).Scan(
 		&createdCategory.Id,
 		&createdCategory.Title,
 		&createdCategory.Slug,
+		&createdCategory.ParentId,
 		&createdCategory.CreatedAt,
+		&createdCategory.Version,
+		&createdCategory.UpdatedAt,
 	)
 
 	if err != nil {
+		tx.Rollback()
 		return nil, fmt.Errorf("error executing Create query: %v", err)
 	}
 
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
 	return &createdCategory, nil
 }
 
+// Update saves the category and bumps its version. It returns ErrVersionConflict when the
+// category is no longer at category.Version, or is changed by someone else meanwhile.
 func (repo categoryRepository) Update(id string, category types.Category) (*types.Category, error) {
-	slug, err := repo.generateUniqueSlug(category.Slug, id)
+	return repo.save(id, category, false, "Update")
+}
+
+// Patch is Update writing only the title, slug or parent when it differs from the stored one.
+// The category is returned as stored, without a new version, when neither differs.
+func (repo categoryRepository) Patch(id string, category types.Category) (*types.Category, error) {
+	return repo.save(id, category, true, "Patch")
+}
+
+func (repo categoryRepository) save(id string, category types.Category, changedOnly bool, method string) (*types.Category, error) {
+	existingCategory, err := repo.FindById(id)
 	if err != nil {
-		return nil, fmt.Errorf("error generating unique slug for update: %v", err)
+		return nil, fmt.Errorf("error finding category to update: %v", err)
 	}
 
-	updateQuery := sq.Update("categories").
-		Set("title", category.Title).
-		Set("slug", slug).
-		Where(sq.Eq{"id": id}).
-		Suffix("RETURNING id, title, slug, created_at").
+	// Only the version the caller read is updated, when it tells which one that was
+	version := existingCategory.Version
+	if category.Version != 0 && category.Version != version {
+		return nil, ErrVersionConflict
+	}
+
+	titleChanged := category.Title != existingCategory.Title
+	slugChanged := category.Slug != existingCategory.Slug
+	parentChanged := !sameParent(category.ParentId, existingCategory.ParentId)
+	if changedOnly && !titleChanged && !slugChanged && !parentChanged {
+		return existingCategory, nil
+	}
+
+	tx, err := repo.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+
+	slug := existingCategory.Slug
+	if slugChanged {
+		slug, err = uniqueSlug(tx, "categories", "category_slug_history", "category_id", category.Slug, id)
+		if err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("error generating unique slug for update: %v", err)
+		}
+	}
+
+	if parentChanged && category.ParentId != nil {
+		if err := checkCategoryParent(tx, id, *category.ParentId); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	updateQuery := sq.Update("categories")
+	if titleChanged || !changedOnly {
+		updateQuery = updateQuery.Set("title", category.Title)
+	}
+	if slugChanged || !changedOnly {
+		updateQuery = updateQuery.Set("slug", slug)
+	}
+	if parentChanged || !changedOnly {
+		updateQuery = updateQuery.Set("parent_id", category.ParentId)
+	}
+	updateQuery = updateQuery.
+		Set("version", sq.Expr("version + 1")).
+		Set("updated_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Where(sq.Eq{"id": id, "version": version}).
+		Suffix("RETURNING id, title, slug, parent_id, created_at, version, updated_at").
 		PlaceholderFormat(sq.Dollar)
 
 	sql, args, err := updateQuery.ToSql()
 	if err != nil {
-		return nil, fmt.Errorf("error creating SQL for Update: %v", err)
+		tx.Rollback()
+		return nil, fmt.Errorf("error creating SQL for %s: %v", method, err)
 	}
 
 	var updatedCategory types.Category
-	err = repo.db.QueryRowContext(context.Background(), sql, args...).Scan(
+	err = tx.QueryRowContext(context.Background(), sql, args...).Scan(
 		&updatedCategory.Id,
 		&updatedCategory.Title,
 		&updatedCategory.Slug,
+		&updatedCategory.ParentId,
 		&updatedCategory.CreatedAt,
+		&updatedCategory.Version,
+		&updatedCategory.UpdatedAt,
 	)
 
+	if isNoRows(err) {
+		tx.Rollback()
+		return nil, ErrVersionConflict
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("error executing %s query: %v", method, err)
+	}
+
+	if err := recordSlugChange(tx, "category_slug_history", "category_id", id, existingCategory.Slug, updatedCategory.Slug); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
 	if err != nil {
-		return nil, fmt.Errorf("error executing Update query: %v", err)
+		return nil, fmt.Errorf("error committing transaction: %v", err)
 	}
 
 	return &updatedCategory, nil
 }
 
+func sameParent(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// checkCategoryParent returns ErrCategoryCycle when the category is parentId itself or
+// one of its ancestors.
+func checkCategoryParent(tx *sql.Tx, id string, parentId string) error {
+	var cycle bool
+	err := tx.QueryRowContext(context.Background(),
+		"WITH RECURSIVE ancestors(id, parent_id) AS (SELECT id, parent_id FROM categories WHERE id = $1 "+
+			"UNION SELECT c.id, c.parent_id FROM categories c JOIN ancestors a ON c.id = a.parent_id) "+
+			"SELECT EXISTS(SELECT 1 FROM ancestors WHERE id = $2)",
+		parentId, id,
+	).Scan(&cycle)
+	if err != nil {
+		return fmt.Errorf("error checking category parent: %v", err)
+	}
+
+	if cycle {
+		return ErrCategoryCycle
+	}
+
+	return nil
+}
+
+// Delete moves the category to the trash, see Restore and Purge. Posts keep their
+// assignment to it but don't list it until it's restored.
 func (repo categoryRepository) Delete(id string) error {
-	deleteQuery := sq.Delete("categories").
+	deleteQuery := sq.Update("categories").
+		Set("deleted_at", sq.Expr("CURRENT_TIMESTAMP")).
 		Where(sq.Eq{"id": id}).
+		Where("deleted_at IS NULL").
 		PlaceholderFormat(sq.Dollar)
 
 	sql, args, err := deleteQuery.ToSql()
@@ -210,39 +360,122 @@ func (repo categoryRepository) Delete(id string) error {
 	return nil
 }
 
-func (repo categoryRepository) generateUniqueSlug(baseSlug string, excludeId string) (string, error) {
-	slug := baseSlug
-	counter := 1
-
-	for {
-		var exists bool
-		var err error
-
-		if excludeId != "" {
-			err = repo.db.QueryRowContext(
-				context.Background(),
-				"SELECT EXISTS(SELECT 1 FROM categories WHERE slug = $1 AND id != $2)",
-				slug, excludeId,
-			).Scan(&exists)
-		} else {
-			err = repo.db.QueryRowContext(
-				context.Background(),
-				"SELECT EXISTS(SELECT 1 FROM categories WHERE slug = $1)",
-				slug,
-			).Scan(&exists)
-		}
+// FindDeleted returns the categories in the trash, most recently deleted first.
+func (repo categoryRepository) FindDeleted() ([]types.Category, error) {
+	sql, args, err := sq.Select("id, title, slug, parent_id, created_at, version, updated_at, deleted_at").
+		From("categories").
+		Where("deleted_at IS NOT NULL").
+		OrderBy("deleted_at DESC").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error creating SQL for FindDeleted: %v", err)
+	}
+
+	rows, err := repo.db.QueryContext(context.Background(), sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error executing FindDeleted query: %v", err)
+	}
+	defer rows.Close()
 
+	categories := []types.Category{}
+	for rows.Next() {
+		var category types.Category
+		err := rows.Scan(
+			&category.Id,
+			&category.Title,
+			&category.Slug,
+			&category.ParentId,
+			&category.CreatedAt,
+			&category.Version,
+			&category.UpdatedAt,
+			&category.DeletedAt,
+		)
 		if err != nil {
-			return "", fmt.Errorf("error checking slug existence: %v", err)
+			return nil, fmt.Errorf("error scanning row in FindDeleted: %v", err)
 		}
+		categories = append(categories, category)
+	}
 
-		if !exists {
-			break
-		}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after iterating rows in FindDeleted: %v", err)
+	}
+
+	return categories, nil
+}
+
+// Restore takes the category back out of the trash.
+func (repo categoryRepository) Restore(id string) error {
+	sql, args, err := sq.Update("categories").
+		Set("deleted_at", nil).
+		Where(sq.Eq{"id": id}).
+		Where("deleted_at IS NOT NULL").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("error creating SQL for Restore: %v", err)
+	}
+
+	result, err := repo.db.ExecContext(context.Background(), sql, args...)
+	if err != nil {
+		return fmt.Errorf("error executing Restore query: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("couldn't get affected rows: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("no deleted category found with id %s to restore", id)
+	}
 
-		slug = fmt.Sprintf("%s-%d", baseSlug, counter)
-		counter++
+	return nil
+}
+
+// Purge permanently deletes a category from the trash, along with its assignments.
+func (repo categoryRepository) Purge(id string) error {
+	sql, args, err := sq.Delete("categories").
+		Where(sq.Eq{"id": id}).
+		Where("deleted_at IS NOT NULL").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("error creating SQL for Purge: %v", err)
+	}
+
+	result, err := repo.db.ExecContext(context.Background(), sql, args...)
+	if err != nil {
+		return fmt.Errorf("error executing Purge query: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("couldn't get affected rows: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("no deleted category found with id %s to purge", id)
+	}
+
+	return nil
+}
+
+// PurgeDeletedBefore permanently deletes the categories moved to the trash before
+// the given time and returns how many were deleted.
+func (repo categoryRepository) PurgeDeletedBefore(before time.Time) (int64, error) {
+	sql, args, err := sq.Delete("categories").
+		Where(sq.Lt{"deleted_at": before}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("error creating SQL for PurgeDeletedBefore: %v", err)
+	}
+
+	result, err := repo.db.ExecContext(context.Background(), sql, args...)
+	if err != nil {
+		return 0, fmt.Errorf("error executing PurgeDeletedBefore query: %v", err)
 	}
 
-	return slug, nil
+	return result.RowsAffected()
 }
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-blog/internal/types"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+type CommentRepository interface {
+	FindByPost(postId string, statuses []string) ([]types.Comment, error)
+	FindByStatus(status string, page, limit int) ([]types.Comment, int, error)
+	FindById(id string) (*types.Comment, error)
+	Create(comment types.Comment) (*types.Comment, error)
+	Update(id string, content string, status string) (*types.Comment, error)
+	UpdateStatus(id string, status string) (*types.Comment, error)
+}
+
+type commentRepository struct {
+	db *sql.DB
+}
+
+func NewCommentRepository(db *sql.DB) CommentRepository {
+	return &commentRepository{db: db}
+}
+
+func selectComments() sq.SelectBuilder {
+	return sq.Select("comments.id, comments.post_id, comments.parent_id, comments.content, comments.status, comments.created_at, comments.updated_at, users.id, users.name, users.lastname").
+		From("comments").
+		Join("users ON comments.user_id = users.id").
+		PlaceholderFormat(sq.Dollar)
+}
+
+func scanComment(row rowScanner) (*types.Comment, error) {
+	var comment types.Comment
+	var parentId *string
+
+	err := row.Scan(
+		&comment.Id,
+		&comment.PostId,
+		&parentId,
+		&comment.Content,
+		&comment.Status,
+		&comment.CreatedAt,
+		&comment.UpdatedAt,
+		&comment.Author.Id,
+		&comment.Author.Name,
+		&comment.Author.Lastname,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if parentId != nil {
+		comment.ParentId = *parentId
+	}
+
+	return &comment, nil
+}
+
+func (repo commentRepository) queryComments(query sq.SelectBuilder, method string) ([]types.Comment, error) {
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error creating SQL for %s: %v", method, err)
+	}
+
+	rows, err := repo.db.QueryContext(context.Background(), sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error executing %s query: %v", method, err)
+	}
+	defer rows.Close()
+
+	var comments []types.Comment
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning row in %s: %v", method, err)
+		}
+		comments = append(comments, *comment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after iterating rows in %s: %v", method, err)
+	}
+
+	return comments, nil
+}
+
+// FindByPost returns the comments of a post having one of the given statuses, oldest first.
+func (repo commentRepository) FindByPost(postId string, statuses []string) ([]types.Comment, error) {
+	query := selectComments().
+		Where(sq.Eq{"comments.post_id": postId, "comments.status": statuses}).
+		OrderBy("comments.created_at ASC", "comments.id ASC")
+
+	return repo.queryComments(query, "FindByPost")
+}
+
+func (repo commentRepository) FindByStatus(status string, page, limit int) ([]types.Comment, int, error) {
+	var totalCount int
+
+	countSql, countArgs, err := sq.Select("COUNT(*)").
+		From("comments").
+		Where(sq.Eq{"status": status}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+
+	if err != nil {
+		return nil, 0, fmt.Errorf("error creating SQL for count query: %v", err)
+	}
+
+	err = repo.db.QueryRowContext(context.Background(), countSql, countArgs...).Scan(&totalCount)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error executing count query: %v", err)
+	}
+
+	offset := (page - 1) * limit
+
+	query := selectComments().
+		Where(sq.Eq{"comments.status": status}).
+		OrderBy("comments.created_at ASC", "comments.id ASC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset))
+
+	comments, err := repo.queryComments(query, "FindByStatus")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return comments, totalCount, nil
+}
+
+func (repo commentRepository) FindById(id string) (*types.Comment, error) {
+	query := selectComments().
+		Where(sq.Eq{"comments.id": id})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error creating SQL for FindById: %v", err)
+	}
+
+	comment, err := scanComment(repo.db.QueryRowContext(context.Background(), sql, args...))
+	if err != nil {
+		return nil, fmt.Errorf("error scanning row in FindById: %v", err)
+	}
+
+	return comment, nil
+}
+
+func (repo commentRepository) Create(comment types.Comment) (*types.Comment, error) {
+	var parentId interface{}
+	if comment.ParentId != "" {
+		parentId = comment.ParentId
+	}
+
+	insertQuery := sq.Insert("comments").
+		Columns("post_id", "user_id", "parent_id", "content", "status").
+		Values(comment.PostId, comment.Author.Id, parentId, comment.Content, comment.Status).
+		Suffix("RETURNING id, created_at, updated_at").
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := insertQuery.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error creating SQL for Create: %v", err)
+	}
+
+	err = repo.db.QueryRowContext(context.Background(), sql, args...).Scan(
+		&comment.Id,
+		&comment.CreatedAt,
+		&comment.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("error executing Create query: %v", err)
+	}
+
+	return &comment, nil
+}
+
+// Update saves the edited content of a comment along with its status, since edits may
+// have to be moderated again.
+func (repo commentRepository) Update(id string, content string, status string) (*types.Comment, error) {
+	updateQuery := sq.Update("comments").
+		Set("content", content).
+		Set("status", status).
+		Set("updated_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Where(sq.Eq{"id": id}).
+		PlaceholderFormat(sq.Dollar)
+
+	return repo.execUpdate(id, updateQuery, "Update")
+}
+
+func (repo commentRepository) UpdateStatus(id string, status string) (*types.Comment, error) {
+	updateQuery := sq.Update("comments").
+		Set("status", status).
+		Set("updated_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Where(sq.Eq{"id": id}).
+		PlaceholderFormat(sq.Dollar)
+
+	return repo.execUpdate(id, updateQuery, "UpdateStatus")
+}
+
+func (repo commentRepository) execUpdate(id string, updateQuery sq.UpdateBuilder, method string) (*types.Comment, error) {
+	sql, args, err := updateQuery.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error creating SQL for %s: %v", method, err)
+	}
+
+	result, err := repo.db.ExecContext(context.Background(), sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error executing %s query: %v", method, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get affected rows: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return nil, fmt.Errorf("no comment found with id %s to update", id)
+	}
+
+	return repo.FindById(id)
+}
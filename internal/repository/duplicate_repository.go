@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-blog/internal/types"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// DuplicateRepository finds posts by the fingerprint of their content, see
+// types.ContentFingerprint. Posts in the trash are left out.
+type DuplicateRepository interface {
+	FindUnfingerprinted() ([]types.Post, error)
+	SaveFingerprint(postId string, fingerprint uint64) error
+	FindNearDuplicates(postId string, fingerprint uint64, maxDistance int, readerId string) ([]types.Duplicate, error)
+	FindFingerprinted() ([]types.Duplicate, error)
+}
+
+type duplicateRepository struct {
+	db *sql.DB
+}
+
+func NewDuplicateRepository(db *sql.DB) DuplicateRepository {
+	return &duplicateRepository{db: db}
+}
+
+// FindUnfingerprinted returns the id and content of the posts with content but without
+// a fingerprint, saved before posts had one.
+func (repo duplicateRepository) FindUnfingerprinted() ([]types.Post, error) {
+	sql, args, err := sq.Select("id, content").
+		From("posts").
+		Where("content_fingerprint IS NULL AND content <> ''").
+		Where("deleted_at IS NULL").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error creating SQL for FindUnfingerprinted: %v", err)
+	}
+
+	rows, err := repo.db.QueryContext(context.Background(), sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error executing FindUnfingerprinted query: %v", err)
+	}
+	defer rows.Close()
+
+	var posts []types.Post
+	for rows.Next() {
+		var post types.Post
+		if err := rows.Scan(&post.Id, &post.Content); err != nil {
+			return nil, fmt.Errorf("error scanning row in FindUnfingerprinted: %v", err)
+		}
+		posts = append(posts, post)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after iterating rows in FindUnfingerprinted: %v", err)
+	}
+
+	return posts, nil
+}
+
+// SaveFingerprint saves the fingerprint of a post, leaving its version as it is since
+// the post itself doesn't change.
+func (repo duplicateRepository) SaveFingerprint(postId string, fingerprint uint64) error {
+	sql, args, err := sq.Update("posts").
+		Set("content_fingerprint", int64(fingerprint)).
+		Where(sq.Eq{"id": postId}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("error creating SQL for SaveFingerprint: %v", err)
+	}
+
+	if _, err := repo.db.ExecContext(context.Background(), sql, args...); err != nil {
+		return fmt.Errorf("error executing SaveFingerprint query: %v", err)
+	}
+
+	return nil
+}
+
+// fingerprintDistance counts the bits in which the fingerprint of the current post row
+// differs from the fingerprint given as parameter.
+const fingerprintDistance = "bit_count((posts.content_fingerprint # ?)::bit(64))"
+
+// FindNearDuplicates returns the posts other than postId whose fingerprint differs from
+// the fingerprint in at most maxDistance bits, the most similar first. Only the published
+// posts listed to readers and the posts readerId is an author of are returned, an empty
+// readerId returns the posts of every user.
+func (repo duplicateRepository) FindNearDuplicates(postId string, fingerprint uint64, maxDistance int, readerId string) ([]types.Duplicate, error) {
+	query := sq.Select("posts.id, posts.title, posts.slug, posts.status, posts.created_at, posts.content_fingerprint").
+		From("posts").
+		Where("posts.deleted_at IS NULL").
+		Where(sq.NotEq{"posts.id": postId}).
+		Where(sq.Expr(fingerprintDistance+" <= ?", int64(fingerprint), maxDistance))
+
+	if readerId != "" {
+		query = query.Where(sq.Expr("(("+publishedPost+") OR EXISTS(SELECT 1 FROM post_authors pa WHERE pa.post_id = posts.id AND pa.user_id = ?))", readerId))
+	}
+
+	sql, args, err := query.
+		OrderByClause(fingerprintDistance+" ASC", int64(fingerprint)).
+		OrderBy("posts.created_at ASC").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error creating SQL for FindNearDuplicates: %v", err)
+	}
+
+	return repo.queryDuplicates(sql, args, "FindNearDuplicates")
+}
+
+// FindFingerprinted returns the posts with a fingerprint, oldest first.
+func (repo duplicateRepository) FindFingerprinted() ([]types.Duplicate, error) {
+	sql, args, err := sq.Select("posts.id, posts.title, posts.slug, posts.status, posts.created_at, posts.content_fingerprint").
+		From("posts").
+		Where("posts.deleted_at IS NULL").
+		Where("posts.content_fingerprint IS NOT NULL").
+		OrderBy("posts.created_at ASC", "posts.id ASC").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error creating SQL for FindFingerprinted: %v", err)
+	}
+
+	return repo.queryDuplicates(sql, args, "FindFingerprinted")
+}
+
+func (repo duplicateRepository) queryDuplicates(sql string, args []interface{}, method string) ([]types.Duplicate, error) {
+	rows, err := repo.db.QueryContext(context.Background(), sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error executing %s query: %v", method, err)
+	}
+	defer rows.Close()
+
+	var duplicates []types.Duplicate
+	for rows.Next() {
+		var duplicate types.Duplicate
+		var fingerprint int64
+		if err := rows.Scan(&duplicate.Id, &duplicate.Title, &duplicate.Slug, &duplicate.Status, &duplicate.CreatedAt, &fingerprint); err != nil {
+			return nil, fmt.Errorf("error scanning row in %s: %v", method, err)
+		}
+		duplicate.Fingerprint = uint64(fingerprint)
+		duplicates = append(duplicates, duplicate)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after iterating rows in %s: %v", method, err)
+	}
+
+	return duplicates, nil
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-blog/internal/types"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+type FileRepository interface {
+	FindById(id string) (*types.File, error)
+	FindByFilename(userId string, filename string) (*types.File, error)
+	Create(file types.File) (*types.File, error)
+	Delete(id string) error
+	CountPostsUsing(id string) (int, error)
+}
+
+type fileRepository struct {
+	db *sql.DB
+}
+
+func NewFileRepository(db *sql.DB) FileRepository {
+	return &fileRepository{db: db}
+}
+
+func selectFiles() sq.SelectBuilder {
+	return sq.Select("id, user_id, filename, content_type, size, width, height, created_at").
+		From("files").
+		PlaceholderFormat(sq.Dollar)
+}
+
+func scanFile(row rowScanner) (*types.File, error) {
+	var file types.File
+	err := row.Scan(
+		&file.Id,
+		&file.UserId,
+		&file.Filename,
+		&file.ContentType,
+		&file.Size,
+		&file.Width,
+		&file.Height,
+		&file.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	file.Url = types.FileURL(file.UserId, file.Filename)
+	return &file, nil
+}
+
+func (repo fileRepository) FindById(id string) (*types.File, error) {
+	sql, args, err := selectFiles().
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error creating SQL for FindById: %v", err)
+	}
+
+	file, err := scanFile(repo.db.QueryRowContext(context.Background(), sql, args...))
+	if err != nil {
+		return nil, fmt.Errorf("error executing FindById query: %v", err)
+	}
+
+	return file, nil
+}
+
+func (repo fileRepository) FindByFilename(userId string, filename string) (*types.File, error) {
+	sql, args, err := selectFiles().
+		Where(sq.Eq{"user_id": userId, "filename": filename}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error creating SQL for FindByFilename: %v", err)
+	}
+
+	file, err := scanFile(repo.db.QueryRowContext(context.Background(), sql, args...))
+	if err != nil {
+		return nil, fmt.Errorf("error executing FindByFilename query: %v", err)
+	}
+
+	return file, nil
+}
+
+func (repo fileRepository) Create(file types.File) (*types.File, error) {
+	sql, args, err := sq.Insert("files").
+		Columns("user_id", "filename", "content_type", "size", "width", "height").
+		Values(file.UserId, file.Filename, file.ContentType, file.Size, file.Width, file.Height).
+		Suffix("RETURNING id, user_id, filename, content_type, size, width, height, created_at").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error creating SQL for Create: %v", err)
+	}
+
+	createdFile, err := scanFile(repo.db.QueryRowContext(context.Background(), sql, args...))
+	if err != nil {
+		return nil, fmt.Errorf("error executing Create query: %v", err)
+	}
+
+	return createdFile, nil
+}
+
+func (repo fileRepository) Delete(id string) error {
+	sql, args, err := sq.Delete("files").
+		Where(sq.Eq{"id": id}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("error creating SQL for Delete: %v", err)
+	}
+
+	result, err := repo.db.ExecContext(context.Background(), sql, args...)
+	if err != nil {
+		return fmt.Errorf("error executing Delete query: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("couldn't get affected rows: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("no file found with id %s to delete", id)
+	}
+
+	return nil
+}
+
+// CountPostsUsing returns how many posts use the file as their cover image.
+func (repo fileRepository) CountPostsUsing(id string) (int, error) {
+	sql, args, err := sq.Select("COUNT(*)").
+		From("posts").
+		Where(sq.Eq{"cover_image_id": id}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("error creating SQL for CountPostsUsing: %v", err)
+	}
+
+	var count int
+	if err := repo.db.QueryRowContext(context.Background(), sql, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("error executing CountPostsUsing query: %v", err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-blog/internal/types"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// GitSyncRepository remembers which post each Markdown file of the synced git repository
+// was imported as, and from which blob.
+type GitSyncRepository interface {
+	FindAll() ([]types.GitSyncedPost, error)
+	Save(syncedPost types.GitSyncedPost) error
+	Delete(path string) error
+}
+
+type gitSyncRepository struct {
+	db *sql.DB
+}
+
+func NewGitSyncRepository(db *sql.DB) GitSyncRepository {
+	return &gitSyncRepository{db: db}
+}
+
+func (repo gitSyncRepository) FindAll() ([]types.GitSyncedPost, error) {
+	sql, args, err := sq.Select("path", "post_id", "blob_sha", "commit_sha", "synced_at").
+		From("git_synced_posts").
+		OrderBy("path").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error creating SQL for FindAll: %v", err)
+	}
+
+	rows, err := repo.db.QueryContext(context.Background(), sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error executing FindAll query: %v", err)
+	}
+	defer rows.Close()
+
+	var syncedPosts []types.GitSyncedPost
+	for rows.Next() {
+		var syncedPost types.GitSyncedPost
+		if err := rows.Scan(&syncedPost.Path, &syncedPost.PostId, &syncedPost.BlobSha, &syncedPost.CommitSha, &syncedPost.SyncedAt); err != nil {
+			return nil, fmt.Errorf("error scanning row in FindAll: %v", err)
+		}
+		syncedPosts = append(syncedPosts, syncedPost)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after iterating rows in FindAll: %v", err)
+	}
+
+	return syncedPosts, nil
+}
+
+// Save remembers the file as synced, replacing what it was synced as before.
+func (repo gitSyncRepository) Save(syncedPost types.GitSyncedPost) error {
+	sql, args, err := sq.Insert("git_synced_posts").
+		Columns("path", "post_id", "blob_sha", "commit_sha").
+		Values(syncedPost.Path, syncedPost.PostId, syncedPost.BlobSha, syncedPost.CommitSha).
+		Suffix("ON CONFLICT (path) DO UPDATE SET post_id = EXCLUDED.post_id, blob_sha = EXCLUDED.blob_sha, commit_sha = EXCLUDED.commit_sha, synced_at = CURRENT_TIMESTAMP").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("error creating SQL for Save: %v", err)
+	}
+
+	if _, err := repo.db.ExecContext(context.Background(), sql, args...); err != nil {
+		return fmt.Errorf("error executing Save query: %v", err)
+	}
+
+	return nil
+}
+
+func (repo gitSyncRepository) Delete(path string) error {
+	sql, args, err := sq.Delete("git_synced_posts").
+		Where(sq.Eq{"path": path}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("error creating SQL for Delete: %v", err)
+	}
+
+	if _, err := repo.db.ExecContext(context.Background(), sql, args...); err != nil {
+		return fmt.Errorf("error executing Delete query: %v", err)
+	}
+
+	return nil
+}
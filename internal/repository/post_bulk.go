@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-blog/internal/types"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// postTagFilter matches the posts tagged with the tag of the given slug.
+const postTagFilter = "EXISTS(SELECT 1 FROM post_tags pt JOIN tags t ON t.id = pt.tag_id WHERE pt.post_id = posts.id AND t.slug = ?)"
+
+// FindByIds returns the posts with the given ids that aren't in the trash, drafts included.
+func (repo postRepository) FindByIds(ids []string) ([]types.Post, error) {
+	query := selectPosts().
+		Where(sq.Eq{"posts.id": ids}).
+		OrderBy("posts.created_at DESC", "posts.id DESC")
+
+	return repo.queryPosts(query, "FindByIds")
+}
+
+// FindByFilter returns up to limit posts matching the filter, or all of them when limit
+// is 0, newest first, drafts included.
+func (repo postRepository) FindByFilter(filter types.PostFilter, limit int) ([]types.Post, error) {
+	query := selectPosts()
+
+	if filter.CategoryId != "" {
+		query = query.Where("EXISTS(SELECT 1 FROM post_categories pc WHERE pc.post_id = posts.id AND pc.category_id = ?)", filter.CategoryId)
+	}
+	if filter.Tag != "" {
+		query = query.Where(postTagFilter, filter.Tag)
+	}
+	if filter.AuthorId != "" {
+		query = query.Where("EXISTS(SELECT 1 FROM post_authors pa WHERE pa.post_id = posts.id AND pa.user_id = ?)", filter.AuthorId)
+	}
+	if filter.Status != "" {
+		query = query.Where(sq.Eq{"posts.status": filter.Status})
+	}
+	if filter.CreatedAfter != nil {
+		query = query.Where(sq.GtOrEq{"posts.created_at": *filter.CreatedAfter})
+	}
+	if filter.CreatedBefore != nil {
+		query = query.Where(sq.Lt{"posts.created_at": *filter.CreatedBefore})
+	}
+
+	query = query.OrderBy("posts.created_at DESC", "posts.id DESC")
+	if limit > 0 {
+		query = query.Limit(uint64(limit))
+	}
+
+	return repo.queryPosts(query, "FindByFilter")
+}
+
+// ApplyBulkAction applies the action of the request to each of the posts in a single
+// transaction. Every post is changed under its own savepoint, so a post that can't be
+// changed is left as it was, with its error in the returned map, while the others are
+// saved.
+func (repo postRepository) ApplyBulkAction(request types.BulkRequest, postIds []string) (map[string]error, error) {
+	tx, err := repo.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+
+	failed := make(map[string]error)
+	for _, postId := range postIds {
+		if _, err := tx.ExecContext(context.Background(), "SAVEPOINT bulk_post"); err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("error creating savepoint: %v", err)
+		}
+
+		if err := applyBulkAction(tx, request, postId); err != nil {
+			if _, rollbackErr := tx.ExecContext(context.Background(), "ROLLBACK TO SAVEPOINT bulk_post"); rollbackErr != nil {
+				tx.Rollback()
+				return nil, fmt.Errorf("error rolling back to savepoint: %v", rollbackErr)
+			}
+			failed[postId] = err
+			continue
+		}
+
+		if _, err := tx.ExecContext(context.Background(), "RELEASE SAVEPOINT bulk_post"); err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("error releasing savepoint: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return failed, nil
+}
+
+func applyBulkAction(tx *sql.Tx, request types.BulkRequest, postId string) error {
+	switch request.Action {
+	case types.BulkActionDelete:
+		return updateBulkPost(tx, postId, sq.Update("posts").
+			Set("deleted_at", sq.Expr("CURRENT_TIMESTAMP")))
+
+	case types.BulkActionPublish, types.BulkActionUnpublish:
+		status := types.PostStatusPublished
+		if request.Action == types.BulkActionUnpublish {
+			status = types.PostStatusDraft
+		}
+		return updateBulkPost(tx, postId, sq.Update("posts").
+			Set("status", status).
+			Set("version", sq.Expr("version + 1")).
+			Set("updated_at", sq.Expr("CURRENT_TIMESTAMP")))
+
+	case types.BulkActionAssignCategories:
+		for _, categoryId := range request.CategoryIds {
+			_, err := tx.ExecContext(context.Background(), "INSERT INTO post_categories (post_id, category_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", postId, categoryId)
+			if err != nil {
+				return fmt.Errorf("error assigning category %s: %v", categoryId, err)
+			}
+		}
+		return bumpVersion(tx, "posts", postId)
+
+	case types.BulkActionRemoveCategories:
+		sql, args, err := sq.Delete("post_categories").
+			Where(sq.Eq{"post_id": postId, "category_id": request.CategoryIds}).
+			PlaceholderFormat(sq.Dollar).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("error creating SQL for category removal: %v", err)
+		}
+		if _, err := tx.ExecContext(context.Background(), sql, args...); err != nil {
+			return fmt.Errorf("error removing categories: %v", err)
+		}
+		return bumpVersion(tx, "posts", postId)
+
+	case types.BulkActionChangeAuthor:
+		// The new owner takes the byline of the former one, dropping any other credit
+		_, err := tx.ExecContext(context.Background(), "DELETE FROM post_authors WHERE post_id = $1 AND user_id = $2", postId, request.AuthorId)
+		if err != nil {
+			return fmt.Errorf("error removing the former credit of the new author: %v", err)
+		}
+		_, err = tx.ExecContext(context.Background(), "UPDATE post_authors SET user_id = $1 WHERE post_id = $2 AND user_id = (SELECT user_id FROM posts WHERE id = $2)", request.AuthorId, postId)
+		if err != nil {
+			return fmt.Errorf("error crediting the new author: %v", err)
+		}
+		return updateBulkPost(tx, postId, sq.Update("posts").
+			Set("user_id", request.AuthorId).
+			Set("version", sq.Expr("version + 1")).
+			Set("updated_at", sq.Expr("CURRENT_TIMESTAMP")))
+	}
+
+	return fmt.Errorf("unknown bulk action %s", request.Action)
+}
+
+// updateBulkPost runs the update on the post, failing when it isn't found or in the trash.
+func updateBulkPost(tx *sql.Tx, postId string, query sq.UpdateBuilder) error {
+	sql, args, err := query.
+		Where(sq.Eq{"id": postId}).
+		Where("deleted_at IS NULL").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("error creating SQL for bulk update: %v", err)
+	}
+
+	result, err := tx.ExecContext(context.Background(), sql, args...)
+	if err != nil {
+		return fmt.Errorf("error executing bulk update: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("couldn't get affected rows: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("no post found with id %s", postId)
+	}
+
+	return nil
+}
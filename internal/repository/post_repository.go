@@ -3,25 +3,48 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"go-blog/internal/types"
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type PostRepository interface {
 	FindAll() ([]types.Post, error)
 	FindAllPaginated(page, limit int) ([]types.Post, int, error)
-	FindBySlug(slug string) (*types.Post, error)
+	FindAllAfter(after types.Cursor, limit int) ([]types.Post, bool, error)
+	FindAllBefore(before types.Cursor, limit int) ([]types.Post, bool, error)
+	FindBySlug(slug string, locale string) (*types.Post, error)
 	FindById(id string) (*types.Post, error)
+	FindByIds(ids []string) ([]types.Post, error)
+	FindByFilter(filter types.PostFilter, limit int) ([]types.Post, error)
 	Create(post types.Post) (*types.Post, error)
 	Update(id string, post types.Post) (*types.Post, error)
+	Patch(id string, post types.Post) (*types.Post, error)
 	Delete(id string) error
+	FindDeleted(ownerId string) ([]types.Post, error)
+	FindDeletedById(id string) (*types.Post, error)
+	Restore(id string) error
+	Purge(id string) error
+	PurgeDeletedBefore(before time.Time) (int64, error)
 	AssignCategoryToPost(postId string, categoryId string) error
 	UnassignCategoryFromPost(postId string, categoryId string) error
 	GetCategoriesForPost(postId string) ([]types.Category, error)
 	UpdatePostCategories(postId string, categoryIds []string) error
+	UpdatePostTags(postId string, tags []types.Tag) ([]types.Tag, error)
+	UpdatePostAuthors(postId string, authors []types.PostAuthor) ([]types.PostAuthor, error)
+	FindAllByTag(tagSlug string, page, limit int) ([]types.Post, int, error)
+	FindRelated(postId string, limit int) ([]types.Post, error)
+	FindPostTranslation(postId string, locale string) (*types.PostTranslation, error)
+	SavePostTranslation(postId string, translation types.PostTranslation) (*types.PostTranslation, error)
+	DeletePostTranslation(postId string, locale string) error
+	ApplyBulkAction(request types.BulkRequest, postIds []string) (map[string]error, error)
+	FindReviewQueue(reviewerId string) ([]types.Post, error)
+	AssignReviewer(postId string, reviewerId string) error
 }
 
 type postRepository struct {
@@ -32,90 +55,273 @@ func NewPostRepository(db *sql.DB) PostRepository {
 	return &postRepository{db: db}
 }
 
-func (repo postRepository) FindAll() ([]types.Post, error) {
-	var posts []types.Post
+// postCategoriesJson aggregates the categories of the current post row into a JSON
+// array, so listings page over posts instead of the posts x categories join.
+const postCategoriesJson = "COALESCE((SELECT json_agg(json_build_object('id', c.id, 'title', c.title, 'slug', c.slug, 'createdAt', c.created_at) ORDER BY c.title) " +
+	"FROM post_categories pc JOIN categories c ON c.id = pc.category_id WHERE pc.post_id = posts.id AND c.deleted_at IS NULL), '[]')"
+
+// postTagsJson does the same for the tags of the current post row.
+const postTagsJson = "COALESCE((SELECT json_agg(json_build_object('id', t.id, 'name', t.name, 'slug', t.slug, 'createdAt', t.created_at) ORDER BY t.name) " +
+	"FROM post_tags pt JOIN tags t ON t.id = pt.tag_id WHERE pt.post_id = posts.id), '[]')"
+
+const postCommentCount = "(SELECT COUNT(*) FROM comments WHERE comments.post_id = posts.id AND comments.status = 'approved')"
+
+const postReactionsJson = "COALESCE((SELECT json_agg(json_build_object('type', r.type, 'count', r.count) ORDER BY r.type) " +
+	"FROM (SELECT type, COUNT(*) AS count FROM post_reactions WHERE post_reactions.post_id = posts.id GROUP BY type) r), '[]')"
+
+// postAuthorsJson lists the credited authors of the current post row in byline order.
+const postAuthorsJson = "COALESCE((SELECT json_agg(json_build_object('id', u.id, 'name', u.name, 'lastname', u.lastname, 'email', u.email, 'role', pa.role) ORDER BY pa.position) " +
+	"FROM post_authors pa JOIN users u ON u.id = pa.user_id WHERE pa.post_id = posts.id), '[]')"
+
+// postCoverImageJson describes the cover image file of the current post row, or is NULL.
+const postCoverImageJson = "(SELECT json_build_object('id', f.id, 'userId', f.user_id, 'filename', f.filename, 'width', f.width, 'height', f.height, 'alt', posts.cover_image_alt) " +
+	"FROM files f WHERE f.id = posts.cover_image_id)"
 
-	sql, args, err := sq.Select("DISTINCT posts.id, posts.title, posts.slug, posts.content, posts.created_at, users.id, users.name, users.lastname, users.email, categories.id, categories.title, categories.slug, categories.created_at").
+// relatedPostScore scores how related the current post row is to the src post: each
+// shared category counts twice, each shared tag once, and similar titles (by trigram
+// similarity, above the pg_trgm threshold) up to three times.
+const relatedPostScore = "(2 * (SELECT COUNT(*) FROM post_categories pc JOIN post_categories spc ON spc.category_id = pc.category_id JOIN categories c ON c.id = pc.category_id " +
+	"WHERE pc.post_id = posts.id AND spc.post_id = src.id AND c.deleted_at IS NULL) " +
+	"+ (SELECT COUNT(*) FROM post_tags pt JOIN post_tags spt ON spt.tag_id = pt.tag_id WHERE pt.post_id = posts.id AND spt.post_id = src.id) " +
+	"+ CASE WHEN posts.title % src.title THEN 3 * similarity(posts.title, src.title) ELSE 0 END)"
+
+// postTranslationsJson links the current post row to its translations.
+const postTranslationsJson = "COALESCE((SELECT json_agg(json_build_object('locale', tr.locale, 'title', tr.title, 'slug', tr.slug) ORDER BY tr.locale) " +
+	"FROM post_translations tr WHERE tr.post_id = posts.id), '[]')"
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// selectAllPosts selects posts whether they are in the trash or not.
+func selectAllPosts() sq.SelectBuilder {
+	return sq.Select("posts.id, posts.title, posts.slug, posts.content, posts.excerpt, posts.word_count, posts.reading_time_minutes, posts.meta_title, posts.meta_description, posts.canonical_url, posts.og_image, posts.noindex, posts.created_at, users.id, users.name, users.lastname, users.email, " + postCategoriesJson + ", " + postTagsJson + ", " + postCommentCount + ", " + postReactionsJson + ", " + postAuthorsJson + ", " + postCoverImageJson + ", posts.deleted_at, posts.locale, " + postTranslationsJson + ", posts.version, posts.updated_at, posts.status, posts.visibility, posts.password_hash, posts.reviewer_id, posts.excerpt_generated").
 		From("posts").
 		Join("users ON posts.user_id = users.id").
-		LeftJoin("post_categories ON posts.id = post_categories.post_id").
-		LeftJoin("categories ON post_categories.category_id = categories.id").
-		PlaceholderFormat(sq.Dollar).
-		ToSql()
+		PlaceholderFormat(sq.Dollar)
+}
+
+// selectPosts selects the posts which aren't in the trash.
+func selectPosts() sq.SelectBuilder {
+	return selectAllPosts().
+		Where("posts.deleted_at IS NULL")
+}
+
+// publishedPost filters the posts listed to readers, drafts and unlisted posts are only
+// found by id or slug.
+const publishedPost = "posts.status = 'published' AND posts.visibility <> 'unlisted'"
+
+// selectPublishedPosts selects the posts listed to readers.
+func selectPublishedPosts() sq.SelectBuilder {
+	return selectPosts().
+		Where(publishedPost)
+}
+
+func scanPost(row rowScanner) (*types.Post, error) {
+	var post types.Post
+	var categories, tags, reactions, authors, coverImage, translations []byte
+	var passwordHash, reviewerId sql.NullString
+
+	err := row.Scan(
+		&post.Id,
+		&post.Title,
+		&post.Slug,
+		&post.Content,
+		&post.Excerpt,
+		&post.WordCount,
+		&post.ReadingTimeMinutes,
+		&post.Seo.MetaTitle,
+		&post.Seo.MetaDescription,
+		&post.Seo.CanonicalUrl,
+		&post.Seo.OgImage,
+		&post.Seo.Noindex,
+		&post.CreatedAt,
+		&post.Author.Id,
+		&post.Author.Name,
+		&post.Author.Lastname,
+		&post.Author.Email,
+		&categories,
+		&tags,
+		&post.CommentCount,
+		&reactions,
+		&authors,
+		&coverImage,
+		&post.DeletedAt,
+		&post.Locale,
+		&translations,
+		&post.Version,
+		&post.UpdatedAt,
+		&post.Status,
+		&post.Visibility,
+		&passwordHash,
+		&reviewerId,
+		&post.ExcerptGenerated,
+	)
+	if err != nil {
+		return nil, err
+	}
+	post.PasswordHash = passwordHash.String
+	post.ReviewerId = reviewerId.String
+
+	if err := json.Unmarshal(categories, &post.Categories); err != nil {
+		return nil, fmt.Errorf("error decoding categories: %v", err)
+	}
+
+	if err := json.Unmarshal(tags, &post.Tags); err != nil {
+		return nil, fmt.Errorf("error decoding tags: %v", err)
+	}
+
+	if err := json.Unmarshal(reactions, &post.Reactions); err != nil {
+		return nil, fmt.Errorf("error decoding reactions: %v", err)
+	}
+
+	if err := json.Unmarshal(authors, &post.Authors); err != nil {
+		return nil, fmt.Errorf("error decoding authors: %v", err)
+	}
+
+	if err := json.Unmarshal(translations, &post.Translations); err != nil {
+		return nil, fmt.Errorf("error decoding translations: %v", err)
+	}
+	for i, translation := range post.Translations {
+		post.Translations[i].Url = types.TranslationURL(translation.Slug, translation.Locale)
+	}
+
+	if coverImage != nil {
+		var file struct {
+			types.CoverImage
+			UserId   string `json:"userId"`
+			Filename string `json:"filename"`
+		}
+		if err := json.Unmarshal(coverImage, &file); err != nil {
+			return nil, fmt.Errorf("error decoding cover image: %v", err)
+		}
+		file.CoverImage.Url = types.FileURL(file.UserId, file.Filename)
+		post.CoverImage = &file.CoverImage
+	}
+
+	return &post, nil
+}
+
+// coverImageColumns returns the cover_image_id and cover_image_alt values of a post.
+func coverImageColumns(post types.Post) (interface{}, string) {
+	if post.CoverImage == nil {
+		return nil, ""
+	}
+
+	return post.CoverImage.Id, post.CoverImage.Alt
+}
+
+// passwordHash returns the password hash to save for the post: a hash of its new
+// password, hashed as user passwords are, or else the hash it has. Only password
+// protected posts have one.
+func passwordHash(post types.Post) (string, error) {
+	if post.Visibility != types.VisibilityPassword {
+		return "", nil
+	}
+
+	if post.Password != "" {
+		bytes, err := bcrypt.GenerateFromPassword([]byte(post.Password), 14)
+		if err != nil {
+			return "", fmt.Errorf("error hashing password: %v", err)
+		}
+		return string(bytes), nil
+	}
+
+	return post.PasswordHash, nil
+}
+
+// passwordHashColumn returns the password_hash value of a post.
+func passwordHashColumn(post types.Post) interface{} {
+	if post.PasswordHash == "" {
+		return nil
+	}
+
+	return post.PasswordHash
+}
+
+// contentFingerprintColumn returns the content_fingerprint value of a post. The bits of
+// the fingerprint are kept as a signed BIGINT, which holds no unsigned values.
+func contentFingerprintColumn(post types.Post) interface{} {
+	fingerprint := types.ContentFingerprint(post.Content)
+	if fingerprint == 0 {
+		return nil
+	}
+
+	return int64(fingerprint)
+}
+
+// postUpdateColumns lists the columns of posts written by Update, in the order they are set.
+var postUpdateColumns = []string{"title", "slug", "locale", "status", "content", "excerpt", "word_count", "reading_time_minutes", "meta_title", "meta_description", "canonical_url", "og_image", "noindex", "cover_image_id", "cover_image_alt", "visibility", "password_hash", "content_fingerprint", "excerpt_generated"}
+
+// postColumnValues maps postUpdateColumns to their values in post.
+func postColumnValues(post types.Post) map[string]interface{} {
+	coverImageId, coverImageAlt := coverImageColumns(post)
+
+	return map[string]interface{}{
+		"title":                post.Title,
+		"slug":                 post.Slug,
+		"locale":               post.Locale,
+		"status":               post.Status,
+		"content":              post.Content,
+		"excerpt":              post.Excerpt,
+		"word_count":           post.WordCount,
+		"reading_time_minutes": post.ReadingTimeMinutes,
+		"meta_title":           post.Seo.MetaTitle,
+		"meta_description":     post.Seo.MetaDescription,
+		"canonical_url":        post.Seo.CanonicalUrl,
+		"og_image":             post.Seo.OgImage,
+		"noindex":              post.Seo.Noindex,
+		"cover_image_id":       coverImageId,
+		"cover_image_alt":      coverImageAlt,
+		"visibility":           post.Visibility,
+		"password_hash":        passwordHashColumn(post),
+		"content_fingerprint":  contentFingerprintColumn(post),
+		"excerpt_generated":    post.ExcerptGenerated,
+	}
+}
 
+func (repo postRepository) queryPosts(query sq.SelectBuilder, method string) ([]types.Post, error) {
+	sql, args, err := query.ToSql()
 	if err != nil {
-		return nil, fmt.Errorf("error creating SQL for FindAll: %v", err)
+		return nil, fmt.Errorf("error creating SQL for %s: %v", method, err)
 	}
 
 	rows, err := repo.db.QueryContext(context.Background(), sql, args...)
 	if err != nil {
-		return nil, fmt.Errorf("error executing FindAll query: %v", err)
+		return nil, fmt.Errorf("error executing %s query: %v", method, err)
 	}
 	defer rows.Close()
 
-	postMap := make(map[string]*types.Post)
+	var posts []types.Post
 	for rows.Next() {
-		var post types.Post
-		var user types.User
-		var categoryId, categoryTitle, categorySlug *string
-		var categoryCreatedAt *time.Time
-
-		err := rows.Scan(
-			&post.Id,
-			&post.Title,
-			&post.Slug,
-			&post.Content,
-			&post.CreatedAt,
-			&user.Id,
-			&user.Name,
-			&user.Lastname,
-			&user.Email,
-			&categoryId,
-			&categoryTitle,
-			&categorySlug,
-			&categoryCreatedAt,
-		)
+		post, err := scanPost(rows)
 		if err != nil {
-			return nil, fmt.Errorf("error scanning row in FindAll: %v", err)
-		}
-		post.Author = user
-
-		if categoryId != nil {
-			category := types.Category{
-				Id:        *categoryId,
-				Title:     *categoryTitle,
-				Slug:      *categorySlug,
-				CreatedAt: *categoryCreatedAt,
-			}
-
-			if existingPost, ok := postMap[post.Id]; ok {
-				existingPost.Categories = append(existingPost.Categories, category)
-			} else {
-				post.Categories = []types.Category{category}
-				postMap[post.Id] = &post
-			}
-		} else if _, ok := postMap[post.Id]; !ok {
-			postMap[post.Id] = &post
+			return nil, fmt.Errorf("error scanning row in %s: %v", method, err)
 		}
+		posts = append(posts, *post)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error after iterating rows in FindAll: %v", err)
-	}
-
-	for _, post := range postMap {
-		posts = append(posts, *post)
+		return nil, fmt.Errorf("error after iterating rows in %s: %v", method, err)
 	}
 
 	return posts, nil
 }
 
+func (repo postRepository) FindAll() ([]types.Post, error) {
+	query := selectPublishedPosts().
+		OrderBy("posts.created_at DESC", "posts.id DESC")
+
+	return repo.queryPosts(query, "FindAll")
+}
+
 func (repo postRepository) FindAllPaginated(page, limit int) ([]types.Post, int, error) {
-	var posts []types.Post
 	var totalCount int
 
-	countSql, countArgs, err := sq.Select("COUNT(DISTINCT posts.id)").
+	countSql, countArgs, err := sq.Select("COUNT(*)").
 		From("posts").
+		Where("posts.deleted_at IS NULL").
+		Where(publishedPost).
 		PlaceholderFormat(sq.Dollar).
 		ToSql()
 
@@ -130,294 +336,353 @@ func (repo postRepository) FindAllPaginated(page, limit int) ([]types.Post, int,
 
 	offset := (page - 1) * limit
 
-	sql, args, err := sq.Select("DISTINCT posts.id, posts.title, posts.slug, posts.content, posts.created_at, users.id, users.name, users.lastname, users.email, categories.id, categories.title, categories.slug, categories.created_at").
-		From("posts").
-		Join("users ON posts.user_id = users.id").
-		LeftJoin("post_categories ON posts.id = post_categories.post_id").
-		LeftJoin("categories ON post_categories.category_id = categories.id").
-		OrderBy("posts.created_at DESC").
+	query := selectPublishedPosts().
+		OrderBy("posts.created_at DESC", "posts.id DESC").
 		Limit(uint64(limit)).
-		Offset(uint64(offset)).
-		PlaceholderFormat(sq.Dollar).
-		ToSql()
+		Offset(uint64(offset))
 
+	posts, err := repo.queryPosts(query, "FindAllPaginated")
 	if err != nil {
-		return nil, 0, fmt.Errorf("error creating SQL for FindAllPaginated: %v", err)
+		return nil, 0, err
 	}
 
-	rows, err := repo.db.QueryContext(context.Background(), sql, args...)
-	if err != nil {
-		return nil, 0, fmt.Errorf("error executing FindAllPaginated query: %v", err)
-	}
-	defer rows.Close()
-
-	postMap := make(map[string]*types.Post)
-	for rows.Next() {
-		var post types.Post
-		var user types.User
-		var categoryId, categoryTitle, categorySlug *string
-		var categoryCreatedAt *time.Time
-
-		err := rows.Scan(
-			&post.Id,
-			&post.Title,
-			&post.Slug,
-			&post.Content,
-			&post.CreatedAt,
-			&user.Id,
-			&user.Name,
-			&user.Lastname,
-			&user.Email,
-			&categoryId,
-			&categoryTitle,
-			&categorySlug,
-			&categoryCreatedAt,
-		)
-		if err != nil {
-			return nil, 0, fmt.Errorf("error scanning row in FindAllPaginated: %v", err)
-		}
-		post.Author = user
-
-		if categoryId != nil {
-			category := types.Category{
-				Id:        *categoryId,
-				Title:     *categoryTitle,
-				Slug:      *categorySlug,
-				CreatedAt: *categoryCreatedAt,
-			}
+	return posts, totalCount, nil
+}
 
-			if existingPost, ok := postMap[post.Id]; ok {
-				existingPost.Categories = append(existingPost.Categories, category)
-			} else {
-				post.Categories = []types.Category{category}
-				postMap[post.Id] = &post
-			}
-		} else if _, ok := postMap[post.Id]; !ok {
-			postMap[post.Id] = &post
-		}
-	}
+// FindAllAfter returns up to limit posts that come after the cursor in the
+// listing order, and whether more posts exist beyond them.
+func (repo postRepository) FindAllAfter(after types.Cursor, limit int) ([]types.Post, bool, error) {
+	query := selectPublishedPosts().
+		Where("(posts.created_at, posts.id) < (?, ?)", after.CreatedAt, after.Id).
+		OrderBy("posts.created_at DESC", "posts.id DESC").
+		Limit(uint64(limit + 1))
 
-	if err := rows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("error after iterating rows in FindAllPaginated: %v", err)
+	posts, err := repo.queryPosts(query, "FindAllAfter")
+	if err != nil {
+		return nil, false, err
 	}
 
-	for _, post := range postMap {
-		posts = append(posts, *post)
+	hasMore := len(posts) > limit
+	if hasMore {
+		posts = posts[:limit]
 	}
 
-	return posts, totalCount, nil
+	return posts, hasMore, nil
 }
 
-func (repo postRepository) FindBySlug(slug string) (*types.Post, error) {
-	query := sq.Select("posts.id, posts.title, posts.slug, posts.content, posts.created_at, users.id, users.name, users.lastname, users.email").
-		From("posts").
-		Join("users ON posts.user_id = users.id").
-		Where(sq.Eq{"posts.slug": slug}).
-		PlaceholderFormat(sq.Dollar)
+// FindAllBefore returns up to limit posts that come right before the cursor in
+// the listing order, and whether more posts exist before them.
+func (repo postRepository) FindAllBefore(before types.Cursor, limit int) ([]types.Post, bool, error) {
+	query := selectPublishedPosts().
+		Where("(posts.created_at, posts.id) > (?, ?)", before.CreatedAt, before.Id).
+		OrderBy("posts.created_at ASC", "posts.id ASC").
+		Limit(uint64(limit + 1))
 
-	sql, args, err := query.ToSql()
+	posts, err := repo.queryPosts(query, "FindAllBefore")
 	if err != nil {
-		return nil, fmt.Errorf("error creating SQL for FindBySlug: %v", err)
+		return nil, false, err
 	}
 
-	var post types.Post
-	var user types.User
-	err = repo.db.QueryRowContext(context.Background(), sql, args...).Scan(
-		&post.Id,
-		&post.Title,
-		&post.Slug,
-		&post.Content,
-		&post.CreatedAt,
-		&user.Id,
-		&user.Name,
-		&user.Lastname,
-		&user.Email,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("error scanning row in FindBySlug: %v", err)
+	hasMore := len(posts) > limit
+	if hasMore {
+		posts = posts[:limit]
 	}
-	post.Author = user
-
-	// Fetch categories separately
-	categoriesQuery := sq.Select("categories.id, categories.title, categories.slug, categories.created_at").
-		From("categories").
-		Join("post_categories ON categories.id = post_categories.category_id").
-		Where(sq.Eq{"post_categories.post_id": post.Id}).
-		PlaceholderFormat(sq.Dollar)
 
-	categoriesSql, categoriesArgs, err := categoriesQuery.ToSql()
-	if err != nil {
-		return nil, fmt.Errorf("error creating SQL for categories: %v", err)
+	for i, j := 0, len(posts)-1; i < j; i, j = i+1, j-1 {
+		posts[i], posts[j] = posts[j], posts[i]
 	}
 
-	rows, err := repo.db.QueryContext(context.Background(), categoriesSql, categoriesArgs...)
+	return posts, hasMore, nil
+}
+
+// FindBySlug finds the post by its slug or the slug of one of its translations. Slugs
+// are only unique within a language, so the post known by slug in locale comes first.
+func (repo postRepository) FindBySlug(slug string, locale string) (*types.Post, error) {
+	query := selectPosts().
+		Where(sq.Or{
+			sq.Eq{"posts.slug": slug},
+			sq.Expr("EXISTS(SELECT 1 FROM post_translations tr WHERE tr.post_id = posts.id AND tr.slug = ?)", slug),
+		}).
+		OrderByClause("(posts.slug = ? AND posts.locale = ?) OR EXISTS(SELECT 1 FROM post_translations tr WHERE tr.post_id = posts.id AND tr.slug = ? AND tr.locale = ?) DESC", slug, locale, slug, locale).
+		Limit(1)
+
+	sql, args, err := query.ToSql()
 	if err != nil {
-		return nil, fmt.Errorf("error fetching categories: %v", err)
+		return nil, fmt.Errorf("error creating SQL for FindBySlug: %v", err)
 	}
-	defer rows.Close()
 
-	for rows.Next() {
-		var category types.Category
-		err := rows.Scan(
-			&category.Id,
-			&category.Title,
-			&category.Slug,
-			&category.CreatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("error scanning category: %v", err)
+	post, err := scanPost(repo.db.QueryRowContext(context.Background(), sql, args...))
+	if err != nil {
+		// The post may have been renamed, the caller can tell by comparing slugs
+		if postId, historyErr := findSlugOwner(repo.db, "post_slug_history", "post_id", slug); historyErr == nil {
+			return repo.FindById(postId)
 		}
-		post.Categories = append(post.Categories, category)
+
+		return nil, fmt.Errorf("error scanning row in FindBySlug: %v", err)
 	}
 
-	return &post, nil
+	return post, nil
 }
 
 func (repo postRepository) FindById(id string) (*types.Post, error) {
-	query := sq.Select("posts.id, posts.title, posts.slug, posts.content, posts.created_at, users.id, users.name, users.lastname, users.email").
-		From("posts").
-		Join("users ON posts.user_id = users.id").
-		Where(sq.Eq{"posts.id": id}).
-		PlaceholderFormat(sq.Dollar)
+	query := selectPosts().
+		Where(sq.Eq{"posts.id": id})
 
 	sql, args, err := query.ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("error creating SQL for FindById: %v", err)
 	}
 
-	var post types.Post
-	var user types.User
-	err = repo.db.QueryRowContext(context.Background(), sql, args...).Scan(
-		&post.Id,
-		&post.Title,
-		&post.Slug,
-		&post.Content,
-		&post.CreatedAt,
-		&user.Id,
-		&user.Name,
-		&user.Lastname,
-		&user.Email,
-	)
+	post, err := scanPost(repo.db.QueryRowContext(context.Background(), sql, args...))
 	if err != nil {
 		return nil, fmt.Errorf("error scanning row in FindById: %v", err)
 	}
-	post.Author = user
 
-	// Fetch categories separately
-	categoriesQuery := sq.Select("categories.id, categories.title, categories.slug, categories.created_at").
-		From("categories").
-		Join("post_categories ON categories.id = post_categories.category_id").
-		Where(sq.Eq{"post_categories.post_id": post.Id}).
-		PlaceholderFormat(sq.Dollar)
+	return post, nil
+}
 
-	categoriesSql, categoriesArgs, err := categoriesQuery.ToSql()
+func (repo postRepository) Create(post types.Post) (*types.Post, error) {
+	tx, err := repo.db.BeginTx(context.Background(), nil)
 	if err != nil {
-		return nil, fmt.Errorf("error creating SQL for categories: %v", err)
+		return nil, fmt.Errorf("error starting transaction: %v", err)
 	}
 
-	rows, err := repo.db.QueryContext(context.Background(), categoriesSql, categoriesArgs...)
+	slug, err := uniquePostSlug(tx, post.Locale, post.Slug, "")
 	if err != nil {
-		return nil, fmt.Errorf("error fetching categories: %v", err)
+		tx.Rollback()
+		return nil, fmt.Errorf("error generating unique slug: %v", err)
 	}
-	defer rows.Close()
 
-	for rows.Next() {
-		var category types.Category
-		err := rows.Scan(
-			&category.Id,
-			&category.Title,
-			&category.Slug,
-			&category.CreatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("error scanning category: %v", err)
-		}
-		post.Categories = append(post.Categories, category)
+	coverImageId, coverImageAlt := coverImageColumns(post)
+
+	if post.Visibility == "" {
+		post.Visibility = types.VisibilityPublic
+	}
+	post.PasswordHash, err = passwordHash(post)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
 	}
 
-	return &post, nil
-}
+	columns := []string{"title", "slug", "locale", "status", "content", "excerpt", "word_count", "reading_time_minutes", "meta_title", "meta_description", "canonical_url", "og_image", "noindex", "cover_image_id", "cover_image_alt", "visibility", "password_hash", "content_fingerprint", "excerpt_generated", "user_id"}
+	values := []interface{}{post.Title, slug, post.Locale, post.Status, post.Content, post.Excerpt, post.WordCount, post.ReadingTimeMinutes, post.Seo.MetaTitle, post.Seo.MetaDescription, post.Seo.CanonicalUrl, post.Seo.OgImage, post.Seo.Noindex, coverImageId, coverImageAlt, post.Visibility, passwordHashColumn(post), contentFingerprintColumn(post), post.ExcerptGenerated, post.Author.Id}
 
-func (repo postRepository) Create(post types.Post) (*types.Post, error) {
-	slug, err := repo.generateUniqueSlug(post.Slug)
-	if err != nil {
-		return nil, fmt.Errorf("error generating unique slug: %v", err)
+	// Imported posts keep the date they were first published
+	if !post.CreatedAt.IsZero() {
+		columns = append(columns, "created_at")
+		values = append(values, post.CreatedAt)
 	}
 
 	insertQuery := sq.Insert("posts").
-		Columns("title", "slug", "content", "user_id").
-		Values(post.Title, slug, post.Content, post.Author.Id).
-		Suffix("RETURNING id, title, slug, content, created_at").
+		Columns(columns...).
+		Values(values...).
+		Suffix("RETURNING id, title, slug, locale, status, content, excerpt, word_count, reading_time_minutes, meta_title, meta_description, canonical_url, og_image, noindex, created_at, version, updated_at").
 		PlaceholderFormat(sq.Dollar)
 
 	sql, args, err := insertQuery.ToSql()
 	if err != nil {
+		tx.Rollback()
 		return nil, fmt.Errorf("error creating SQL for Create: %v", err)
 	}
 
 	var createdPost types.Post
 
-	err = repo.db.QueryRowContext(context.Background(), sql, args...).Scan(
+	err = tx.QueryRowContext(context.Background(), sql, args...).Scan(
 		&createdPost.Id,
 		&createdPost.Title,
 		&createdPost.Slug,
+		&createdPost.Locale,
+		&createdPost.Status,
 		&createdPost.Content,
+		&createdPost.Excerpt,
+		&createdPost.WordCount,
+		&createdPost.ReadingTimeMinutes,
+		&createdPost.Seo.MetaTitle,
+		&createdPost.Seo.MetaDescription,
+		&createdPost.Seo.CanonicalUrl,
+		&createdPost.Seo.OgImage,
+		&createdPost.Seo.Noindex,
 		&createdPost.CreatedAt,
+		&createdPost.Version,
+		&createdPost.UpdatedAt,
 	)
 
 	if err != nil {
+		tx.Rollback()
 		return nil, fmt.Errorf("error executing Create query: %v", err)
 	}
 
+	// The owner is credited as the first author
+	_, err = tx.ExecContext(context.Background(), "INSERT INTO post_authors (post_id, user_id, role, position) VALUES ($1, $2, $3, 0)", createdPost.Id, post.Author.Id, types.AuthorRoleAuthor)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("error adding post owner as author: %v", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
 	createdPost.Author = post.Author
+	createdPost.CoverImage = post.CoverImage
+	createdPost.Visibility = post.Visibility
+	createdPost.ExcerptGenerated = post.ExcerptGenerated
+	createdPost.PasswordHash = post.PasswordHash
+	createdPost.Translations = []types.Translation{}
+	createdPost.Authors = []types.PostAuthor{{
+		Id:       post.Author.Id,
+		Name:     post.Author.Name,
+		Lastname: post.Author.Lastname,
+		Email:    post.Author.Email,
+		Role:     types.AuthorRoleAuthor,
+	}}
 	return &createdPost, nil
 }
 
+// Update saves the post and bumps its version. It returns ErrVersionConflict when the
+// post is no longer at post.Version, or is changed by someone else meanwhile.
 func (repo postRepository) Update(id string, post types.Post) (*types.Post, error) {
+	return repo.save(id, post, false, "Update")
+}
+
+// Patch is Update writing only the columns whose value differs from the stored post.
+// The post is returned as stored, without a new version, when nothing differs.
+func (repo postRepository) Patch(id string, post types.Post) (*types.Post, error) {
+	return repo.save(id, post, true, "Patch")
+}
+
+func (repo postRepository) save(id string, post types.Post, changedOnly bool, method string) (*types.Post, error) {
 	existingPost, err := repo.FindById(id)
 	if err != nil {
 		return nil, fmt.Errorf("error finding post to update: %v", err)
 	}
 
-	slug, err := repo.generateUniqueSlug(post.Slug)
+	// Only the version the caller read is updated, when it tells which one that was
+	version := existingPost.Version
+	if post.Version != 0 && post.Version != version {
+		return nil, ErrVersionConflict
+	}
+
+	// The visibility and password are kept unless new ones are given
+	if post.Visibility == "" {
+		post.Visibility = existingPost.Visibility
+	}
+	if post.PasswordHash == "" {
+		post.PasswordHash = existingPost.PasswordHash
+	}
+	post.PasswordHash, err = passwordHash(post)
 	if err != nil {
-		return nil, fmt.Errorf("error generating unique slug for update: %v", err)
+		return nil, err
 	}
 
-	updateQuery := sq.Update("posts").
-		Set("title", post.Title).
-		Set("slug", slug).
-		Set("content", post.Content).
-		Where(sq.Eq{"id": id}).
-		Suffix("RETURNING id, title, slug, content, created_at").
+	values := postColumnValues(post)
+	existingValues := postColumnValues(*existingPost)
+	columns := postUpdateColumns
+	if changedOnly {
+		columns = nil
+		for _, column := range postUpdateColumns {
+			// The slug is unique per language, so it is checked again in a new one
+			if values[column] != existingValues[column] || column == "slug" && post.Locale != existingPost.Locale {
+				columns = append(columns, column)
+			}
+		}
+		if len(columns) == 0 {
+			return existingPost, nil
+		}
+	}
+
+	tx, err := repo.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+
+	slug := existingPost.Slug
+	if post.Slug != existingPost.Slug || post.Locale != existingPost.Locale {
+		slug, err = uniquePostSlug(tx, post.Locale, post.Slug, id)
+		if err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("error generating unique slug for update: %v", err)
+		}
+	}
+
+	values["slug"] = slug
+
+	updateQuery := sq.Update("posts")
+	for _, column := range columns {
+		updateQuery = updateQuery.Set(column, values[column])
+	}
+	updateQuery = updateQuery.
+		Set("version", sq.Expr("version + 1")).
+		Set("updated_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Where(sq.Eq{"id": id, "version": version}).
+		Suffix("RETURNING id, title, slug, locale, status, content, excerpt, word_count, reading_time_minutes, meta_title, meta_description, canonical_url, og_image, noindex, created_at, version, updated_at").
 		PlaceholderFormat(sq.Dollar)
 
 	sql, args, err := updateQuery.ToSql()
 	if err != nil {
-		return nil, fmt.Errorf("error creating SQL for Update: %v", err)
+		tx.Rollback()
+		return nil, fmt.Errorf("error creating SQL for %s: %v", method, err)
 	}
 
 	var updatedPost types.Post
-	err = repo.db.QueryRowContext(context.Background(), sql, args...).Scan(
+	err = tx.QueryRowContext(context.Background(), sql, args...).Scan(
 		&updatedPost.Id,
 		&updatedPost.Title,
 		&updatedPost.Slug,
+		&updatedPost.Locale,
+		&updatedPost.Status,
 		&updatedPost.Content,
+		&updatedPost.Excerpt,
+		&updatedPost.WordCount,
+		&updatedPost.ReadingTimeMinutes,
+		&updatedPost.Seo.MetaTitle,
+		&updatedPost.Seo.MetaDescription,
+		&updatedPost.Seo.CanonicalUrl,
+		&updatedPost.Seo.OgImage,
+		&updatedPost.Seo.Noindex,
 		&updatedPost.CreatedAt,
+		&updatedPost.Version,
+		&updatedPost.UpdatedAt,
 	)
 
+	if isNoRows(err) {
+		tx.Rollback()
+		return nil, ErrVersionConflict
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("error executing %s query: %v", method, err)
+	}
+
+	if err := recordSlugChange(tx, "post_slug_history", "post_id", id, existingPost.Slug, updatedPost.Slug); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
 	if err != nil {
-		return nil, fmt.Errorf("error executing Update query: %v", err)
+		return nil, fmt.Errorf("error committing transaction: %v", err)
 	}
 
 	updatedPost.Author = existingPost.Author
+	updatedPost.CoverImage = post.CoverImage
+	updatedPost.Visibility = post.Visibility
+	updatedPost.ExcerptGenerated = post.ExcerptGenerated
+	updatedPost.PasswordHash = post.PasswordHash
+	updatedPost.ReviewerId = existingPost.ReviewerId
+	updatedPost.Authors = existingPost.Authors
+	updatedPost.Translations = existingPost.Translations
 
 	return &updatedPost, nil
 }
 
+// Delete moves the post to the trash, see Restore and Purge.
 func (repo postRepository) Delete(id string) error {
-	deleteQuery := sq.Delete("posts").
+	deleteQuery := sq.Update("posts").
+		Set("deleted_at", sq.Expr("CURRENT_TIMESTAMP")).
 		Where(sq.Eq{"id": id}).
+		Where("deleted_at IS NULL").
 		PlaceholderFormat(sq.Dollar)
 
 	sql, args, err := deleteQuery.ToSql()
@@ -442,34 +707,112 @@ func (repo postRepository) Delete(id string) error {
 	return nil
 }
 
-func (repo postRepository) generateUniqueSlug(baseSlug string) (string, error) {
-	slug := baseSlug
-	counter := 1
+// FindDeleted returns the posts in the trash, most recently deleted first. An empty
+// ownerId returns the trashed posts of every user.
+func (repo postRepository) FindDeleted(ownerId string) ([]types.Post, error) {
+	query := selectAllPosts().
+		Where("posts.deleted_at IS NOT NULL").
+		OrderBy("posts.deleted_at DESC", "posts.id DESC")
 
-	for {
-		query := sq.Select("EXISTS(SELECT 1 FROM posts WHERE slug = ?)").
-			PlaceholderFormat(sq.Dollar)
+	if ownerId != "" {
+		query = query.Where(sq.Eq{"posts.user_id": ownerId})
+	}
 
-		sql, args, err := query.ToSql()
-		if err != nil {
-			return "", fmt.Errorf("error creating SQL for slug check: %v", err)
-		}
+	return repo.queryPosts(query, "FindDeleted")
+}
 
-		var exists bool
-		err = repo.db.QueryRowContext(context.Background(), sql, append(args, slug)...).Scan(&exists)
-		if err != nil {
-			return "", fmt.Errorf("error checking slug existence: %v", err)
-		}
+func (repo postRepository) FindDeletedById(id string) (*types.Post, error) {
+	query := selectAllPosts().
+		Where(sq.Eq{"posts.id": id}).
+		Where("posts.deleted_at IS NOT NULL")
 
-		if !exists {
-			break
-		}
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error creating SQL for FindDeletedById: %v", err)
+	}
+
+	post, err := scanPost(repo.db.QueryRowContext(context.Background(), sql, args...))
+	if err != nil {
+		return nil, fmt.Errorf("error scanning row in FindDeletedById: %v", err)
+	}
+
+	return post, nil
+}
+
+// Restore takes the post back out of the trash.
+func (repo postRepository) Restore(id string) error {
+	sql, args, err := sq.Update("posts").
+		Set("deleted_at", nil).
+		Where(sq.Eq{"id": id}).
+		Where("deleted_at IS NOT NULL").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("error creating SQL for Restore: %v", err)
+	}
+
+	result, err := repo.db.ExecContext(context.Background(), sql, args...)
+	if err != nil {
+		return fmt.Errorf("error executing Restore query: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("couldn't get affected rows: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("no deleted post found with id %s to restore", id)
+	}
+
+	return nil
+}
+
+// Purge permanently deletes a post from the trash.
+func (repo postRepository) Purge(id string) error {
+	sql, args, err := sq.Delete("posts").
+		Where(sq.Eq{"id": id}).
+		Where("deleted_at IS NOT NULL").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("error creating SQL for Purge: %v", err)
+	}
+
+	result, err := repo.db.ExecContext(context.Background(), sql, args...)
+	if err != nil {
+		return fmt.Errorf("error executing Purge query: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("couldn't get affected rows: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("no deleted post found with id %s to purge", id)
+	}
+
+	return nil
+}
+
+// PurgeDeletedBefore permanently deletes the posts moved to the trash before the
+// given time and returns how many were deleted.
+func (repo postRepository) PurgeDeletedBefore(before time.Time) (int64, error) {
+	sql, args, err := sq.Delete("posts").
+		Where(sq.Lt{"deleted_at": before}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("error creating SQL for PurgeDeletedBefore: %v", err)
+	}
 
-		slug = fmt.Sprintf("%s-%d", baseSlug, counter)
-		counter++
+	result, err := repo.db.ExecContext(context.Background(), sql, args...)
+	if err != nil {
+		return 0, fmt.Errorf("error executing PurgeDeletedBefore query: %v", err)
 	}
 
-	return slug, nil
+	return result.RowsAffected()
 }
 
 func (repo postRepository) AssignCategoryToPost(postId string, categoryId string) error {
@@ -488,7 +831,7 @@ func (repo postRepository) AssignCategoryToPost(postId string, categoryId string
 		return fmt.Errorf("error executing AssignCategoryToPost query: %v", err)
 	}
 
-	return nil
+	return bumpVersion(repo.db, "posts", postId)
 }
 
 func (repo postRepository) UnassignCategoryFromPost(postId string, categoryId string) error {
@@ -506,7 +849,7 @@ func (repo postRepository) UnassignCategoryFromPost(postId string, categoryId st
 		return fmt.Errorf("error executing UnassignCategoryFromPost query: %v", err)
 	}
 
-	return nil
+	return bumpVersion(repo.db, "posts", postId)
 }
 
 func (repo postRepository) GetCategoriesForPost(postId string) ([]types.Category, error) {
@@ -514,6 +857,7 @@ func (repo postRepository) GetCategoriesForPost(postId string) ([]types.Category
 		From("categories c").
 		Join("post_categories pc ON c.id = pc.category_id").
 		Where(sq.Eq{"pc.post_id": postId}).
+		Where("c.deleted_at IS NULL").
 		PlaceholderFormat(sq.Dollar)
 
 	sql, args, err := query.ToSql()
@@ -564,6 +908,11 @@ func (repo postRepository) UpdatePostCategories(postId string, categoryIds []str
 		}
 	}
 
+	if err := bumpVersion(tx, "posts", postId); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	err = tx.Commit()
 	if err != nil {
 		return fmt.Errorf("error committing transaction: %v", err)
@@ -571,3 +920,293 @@ func (repo postRepository) UpdatePostCategories(postId string, categoryIds []str
 
 	return nil
 }
+
+// UpdatePostTags replaces the tags of a post, creating the ones that don't exist yet.
+// Tags are matched by their already normalised slug.
+func (repo postRepository) UpdatePostTags(postId string, tags []types.Tag) ([]types.Tag, error) {
+	tx, err := repo.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+
+	_, err = tx.ExecContext(context.Background(), "DELETE FROM post_tags WHERE post_id = $1", postId)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("error removing existing tags: %v", err)
+	}
+
+	savedTags := []types.Tag{}
+	for _, tag := range tags {
+		var savedTag types.Tag
+		err = tx.QueryRowContext(
+			context.Background(),
+			"INSERT INTO tags (name, slug) VALUES ($1, $2) ON CONFLICT (slug) DO UPDATE SET slug = EXCLUDED.slug RETURNING id, name, slug, created_at",
+			tag.Name, tag.Slug,
+		).Scan(&savedTag.Id, &savedTag.Name, &savedTag.Slug, &savedTag.CreatedAt)
+		if err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("error saving tag %s: %v", tag.Slug, err)
+		}
+
+		_, err = tx.ExecContext(context.Background(), "INSERT INTO post_tags (post_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", postId, savedTag.Id)
+		if err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("error adding tag to post: %v", err)
+		}
+
+		savedTags = append(savedTags, savedTag)
+	}
+
+	if err := bumpVersion(tx, "posts", postId); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return savedTags, nil
+}
+
+// UpdatePostAuthors replaces the credited authors of a post, keeping the given
+// order, and returns them with their user details.
+func (repo postRepository) UpdatePostAuthors(postId string, authors []types.PostAuthor) ([]types.PostAuthor, error) {
+	tx, err := repo.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+
+	_, err = tx.ExecContext(context.Background(), "DELETE FROM post_authors WHERE post_id = $1", postId)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("error removing existing authors: %v", err)
+	}
+
+	for position, author := range authors {
+		_, err = tx.ExecContext(context.Background(), "INSERT INTO post_authors (post_id, user_id, role, position) VALUES ($1, $2, $3, $4)", postId, author.Id, author.Role, position)
+		if err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("error adding author %s: %v", author.Id, err)
+		}
+	}
+
+	rows, err := tx.QueryContext(
+		context.Background(),
+		"SELECT u.id, u.name, COALESCE(u.lastname, ''), u.email, pa.role FROM post_authors pa JOIN users u ON u.id = pa.user_id WHERE pa.post_id = $1 ORDER BY pa.position",
+		postId,
+	)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("error executing UpdatePostAuthors query: %v", err)
+	}
+
+	savedAuthors := []types.PostAuthor{}
+	for rows.Next() {
+		var author types.PostAuthor
+		if err := rows.Scan(&author.Id, &author.Name, &author.Lastname, &author.Email, &author.Role); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return nil, fmt.Errorf("error scanning row in UpdatePostAuthors: %v", err)
+		}
+		savedAuthors = append(savedAuthors, author)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("error after iterating rows in UpdatePostAuthors: %v", err)
+	}
+
+	if err := bumpVersion(tx, "posts", postId); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return savedAuthors, nil
+}
+
+func (repo postRepository) FindAllByTag(tagSlug string, page, limit int) ([]types.Post, int, error) {
+	var totalCount int
+
+	tagFilter := sq.Expr(postTagFilter, tagSlug)
+
+	countSql, countArgs, err := sq.Select("COUNT(*)").
+		From("posts").
+		Where("posts.deleted_at IS NULL").
+		Where(publishedPost).
+		Where(tagFilter).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+
+	if err != nil {
+		return nil, 0, fmt.Errorf("error creating SQL for count query: %v", err)
+	}
+
+	err = repo.db.QueryRowContext(context.Background(), countSql, countArgs...).Scan(&totalCount)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error executing count query: %v", err)
+	}
+
+	offset := (page - 1) * limit
+
+	query := selectPublishedPosts().
+		Where(tagFilter).
+		OrderBy("posts.created_at DESC", "posts.id DESC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset))
+
+	posts, err := repo.queryPosts(query, "FindAllByTag")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return posts, totalCount, nil
+}
+
+// FindRelated returns up to limit posts related to the post, most related first.
+// Posts sharing nothing with it are left out, so fewer posts may be returned.
+func (repo postRepository) FindRelated(postId string, limit int) ([]types.Post, error) {
+	query := selectPublishedPosts().
+		Join("posts src ON src.id = ?", postId).
+		Where("posts.id <> src.id").
+		Where(relatedPostScore+" > 0").
+		OrderBy(relatedPostScore+" DESC", "posts.created_at DESC", "posts.id DESC").
+		Limit(uint64(limit))
+
+	return repo.queryPosts(query, "FindRelated")
+}
+
+func (repo postRepository) FindPostTranslation(postId string, locale string) (*types.PostTranslation, error) {
+	sql, args, err := sq.Select("locale, title, slug, content, excerpt, word_count, reading_time_minutes, updated_at").
+		From("post_translations").
+		Where(sq.Eq{"post_id": postId, "locale": locale}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error creating SQL for FindPostTranslation: %v", err)
+	}
+
+	var translation types.PostTranslation
+	err = repo.db.QueryRowContext(context.Background(), sql, args...).Scan(
+		&translation.Locale,
+		&translation.Title,
+		&translation.Slug,
+		&translation.Content,
+		&translation.Excerpt,
+		&translation.WordCount,
+		&translation.ReadingTimeMinutes,
+		&translation.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error executing FindPostTranslation query: %v", err)
+	}
+
+	return &translation, nil
+}
+
+// SavePostTranslation creates or replaces the translation of the post in the language
+// of the translation. Its slug is made unique within that language, and a former slug
+// keeps redirecting to the post.
+func (repo postRepository) SavePostTranslation(postId string, translation types.PostTranslation) (*types.PostTranslation, error) {
+	tx, err := repo.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+
+	var currentSlug string
+	err = tx.QueryRowContext(context.Background(), "SELECT slug FROM post_translations WHERE post_id = $1 AND locale = $2 FOR UPDATE", postId, translation.Locale).Scan(&currentSlug)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		tx.Rollback()
+		return nil, fmt.Errorf("error finding current translation: %v", err)
+	}
+
+	slug := currentSlug
+	if translation.Slug != currentSlug {
+		slug, err = uniquePostSlug(tx, translation.Locale, translation.Slug, postId)
+		if err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("error generating unique slug for translation: %v", err)
+		}
+	}
+
+	sql, args, err := sq.Insert("post_translations").
+		Columns("post_id", "locale", "title", "slug", "content", "excerpt", "word_count", "reading_time_minutes").
+		Values(postId, translation.Locale, translation.Title, slug, translation.Content, translation.Excerpt, translation.WordCount, translation.ReadingTimeMinutes).
+		Suffix("ON CONFLICT (post_id, locale) DO UPDATE SET title = EXCLUDED.title, slug = EXCLUDED.slug, content = EXCLUDED.content, excerpt = EXCLUDED.excerpt, " +
+			"word_count = EXCLUDED.word_count, reading_time_minutes = EXCLUDED.reading_time_minutes, updated_at = CURRENT_TIMESTAMP " +
+			"RETURNING locale, title, slug, content, excerpt, word_count, reading_time_minutes, updated_at").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("error creating SQL for SavePostTranslation: %v", err)
+	}
+
+	var savedTranslation types.PostTranslation
+	err = tx.QueryRowContext(context.Background(), sql, args...).Scan(
+		&savedTranslation.Locale,
+		&savedTranslation.Title,
+		&savedTranslation.Slug,
+		&savedTranslation.Content,
+		&savedTranslation.Excerpt,
+		&savedTranslation.WordCount,
+		&savedTranslation.ReadingTimeMinutes,
+		&savedTranslation.UpdatedAt,
+	)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("error executing SavePostTranslation query: %v", err)
+	}
+
+	if currentSlug != "" {
+		if err := recordSlugChange(tx, "post_slug_history", "post_id", postId, currentSlug, savedTranslation.Slug); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := bumpVersion(tx, "posts", postId); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return &savedTranslation, nil
+}
+
+func (repo postRepository) DeletePostTranslation(postId string, locale string) error {
+	sql, args, err := sq.Delete("post_translations").
+		Where(sq.Eq{"post_id": postId, "locale": locale}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("error creating SQL for DeletePostTranslation: %v", err)
+	}
+
+	result, err := repo.db.ExecContext(context.Background(), sql, args...)
+	if err != nil {
+		return fmt.Errorf("error executing DeletePostTranslation query: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("couldn't get affected rows: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("no %s translation found for post %s to delete", locale, postId)
+	}
+
+	return bumpVersion(repo.db, "posts", postId)
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"go-blog/internal/types"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// FindReviewQueue returns the posts waiting for review, the ones waiting longest first.
+// With a reviewerId, only the posts assigned to that reviewer are returned.
+func (repo postRepository) FindReviewQueue(reviewerId string) ([]types.Post, error) {
+	query := selectPosts().
+		Where(sq.Eq{"posts.status": types.PostStatusInReview})
+
+	if reviewerId != "" {
+		query = query.Where(sq.Eq{"posts.reviewer_id": reviewerId})
+	}
+
+	query = query.OrderBy("posts.updated_at ASC", "posts.id ASC")
+
+	return repo.queryPosts(query, "FindReviewQueue")
+}
+
+// AssignReviewer assigns the post to the reviewer, or to no one when reviewerId is empty.
+// The post keeps its place in the review queue.
+func (repo postRepository) AssignReviewer(postId string, reviewerId string) error {
+	var reviewer interface{}
+	if reviewerId != "" {
+		reviewer = reviewerId
+	}
+
+	sql, args, err := sq.Update("posts").
+		Set("reviewer_id", reviewer).
+		Set("version", sq.Expr("version + 1")).
+		Where(sq.Eq{"id": postId}).
+		Where("deleted_at IS NULL").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("error creating SQL for AssignReviewer: %v", err)
+	}
+
+	result, err := repo.db.ExecContext(context.Background(), sql, args...)
+	if err != nil {
+		return fmt.Errorf("error executing AssignReviewer query: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("couldn't get affected rows: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("no post found with id %s", postId)
+	}
+
+	return nil
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-blog/internal/types"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+type PreviewLinkRepository interface {
+	FindById(id string) (*types.PreviewLink, error)
+	FindActiveByPost(postId string) ([]types.PreviewLink, error)
+	Create(link types.PreviewLink) (*types.PreviewLink, error)
+	Delete(postId string, id string) error
+}
+
+type previewLinkRepository struct {
+	db *sql.DB
+}
+
+func NewPreviewLinkRepository(db *sql.DB) PreviewLinkRepository {
+	return &previewLinkRepository{db: db}
+}
+
+func selectPreviewLinks() sq.SelectBuilder {
+	return sq.Select("id", "post_id", "user_id", "expires_at", "created_at").
+		From("post_preview_links").
+		PlaceholderFormat(sq.Dollar)
+}
+
+func scanPreviewLink(row rowScanner) (*types.PreviewLink, error) {
+	var link types.PreviewLink
+	err := row.Scan(&link.Id, &link.PostId, &link.CreatedBy, &link.ExpiresAt, &link.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+func (repo previewLinkRepository) FindById(id string) (*types.PreviewLink, error) {
+	sql, args, err := selectPreviewLinks().
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error creating SQL for FindById: %v", err)
+	}
+
+	link, err := scanPreviewLink(repo.db.QueryRowContext(context.Background(), sql, args...))
+	if err != nil {
+		return nil, fmt.Errorf("error scanning row in FindById: %v", err)
+	}
+
+	return link, nil
+}
+
+// FindActiveByPost returns the links of the post which haven't expired, newest first.
+func (repo previewLinkRepository) FindActiveByPost(postId string) ([]types.PreviewLink, error) {
+	sql, args, err := selectPreviewLinks().
+		Where(sq.Eq{"post_id": postId}).
+		Where("expires_at > CURRENT_TIMESTAMP").
+		OrderBy("created_at DESC", "id DESC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error creating SQL for FindActiveByPost: %v", err)
+	}
+
+	rows, err := repo.db.QueryContext(context.Background(), sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error executing FindActiveByPost query: %v", err)
+	}
+	defer rows.Close()
+
+	links := []types.PreviewLink{}
+	for rows.Next() {
+		link, err := scanPreviewLink(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning row in FindActiveByPost: %v", err)
+		}
+		links = append(links, *link)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after iterating rows in FindActiveByPost: %v", err)
+	}
+
+	return links, nil
+}
+
+func (repo previewLinkRepository) Create(link types.PreviewLink) (*types.PreviewLink, error) {
+	sql, args, err := sq.Insert("post_preview_links").
+		Columns("post_id", "user_id", "expires_at").
+		Values(link.PostId, link.CreatedBy, link.ExpiresAt).
+		Suffix("RETURNING id, created_at").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error creating SQL for Create: %v", err)
+	}
+
+	err = repo.db.QueryRowContext(context.Background(), sql, args...).Scan(&link.Id, &link.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("error executing Create query: %v", err)
+	}
+
+	return &link, nil
+}
+
+// Delete revokes the link of the post.
+func (repo previewLinkRepository) Delete(postId string, id string) error {
+	sql, args, err := sq.Delete("post_preview_links").
+		Where(sq.Eq{"id": id, "post_id": postId}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("error creating SQL for Delete: %v", err)
+	}
+
+	result, err := repo.db.ExecContext(context.Background(), sql, args...)
+	if err != nil {
+		return fmt.Errorf("error executing Delete query: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("couldn't get affected rows: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("no preview link found with id %s", id)
+	}
+
+	return nil
+}
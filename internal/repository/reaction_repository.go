@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-blog/internal/types"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+type ReactionRepository interface {
+	Add(postId string, userId string, reactionType string) error
+	Remove(postId string, userId string, reactionType string) error
+	CountByPost(postId string) ([]types.Reaction, error)
+	FindUserReactions(userId string, postIds []string) (map[string]map[string]bool, error)
+}
+
+type reactionRepository struct {
+	db *sql.DB
+}
+
+func NewReactionRepository(db *sql.DB) ReactionRepository {
+	return &reactionRepository{db: db}
+}
+
+// Add is idempotent, reacting twice with the same type keeps a single reaction.
+func (repo reactionRepository) Add(postId string, userId string, reactionType string) error {
+	query := sq.Insert("post_reactions").
+		Columns("post_id", "user_id", "type").
+		Values(postId, userId, reactionType).
+		Suffix("ON CONFLICT DO NOTHING").
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("error creating SQL for Add: %v", err)
+	}
+
+	_, err = repo.db.ExecContext(context.Background(), sql, args...)
+	if err != nil {
+		return fmt.Errorf("error executing Add query: %v", err)
+	}
+
+	return nil
+}
+
+func (repo reactionRepository) Remove(postId string, userId string, reactionType string) error {
+	query := sq.Delete("post_reactions").
+		Where(sq.Eq{"post_id": postId, "user_id": userId, "type": reactionType}).
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("error creating SQL for Remove: %v", err)
+	}
+
+	_, err = repo.db.ExecContext(context.Background(), sql, args...)
+	if err != nil {
+		return fmt.Errorf("error executing Remove query: %v", err)
+	}
+
+	return nil
+}
+
+func (repo reactionRepository) CountByPost(postId string) ([]types.Reaction, error) {
+	query := sq.Select("type", "COUNT(*)").
+		From("post_reactions").
+		Where(sq.Eq{"post_id": postId}).
+		GroupBy("type").
+		OrderBy("type").
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error creating SQL for CountByPost: %v", err)
+	}
+
+	rows, err := repo.db.QueryContext(context.Background(), sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error executing CountByPost query: %v", err)
+	}
+	defer rows.Close()
+
+	reactions := []types.Reaction{}
+	for rows.Next() {
+		var reaction types.Reaction
+		if err := rows.Scan(&reaction.Type, &reaction.Count); err != nil {
+			return nil, fmt.Errorf("error scanning row in CountByPost: %v", err)
+		}
+		reactions = append(reactions, reaction)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after iterating rows in CountByPost: %v", err)
+	}
+
+	return reactions, nil
+}
+
+// FindUserReactions returns, per post id, the set of reaction types the user left on it.
+func (repo reactionRepository) FindUserReactions(userId string, postIds []string) (map[string]map[string]bool, error) {
+	query := sq.Select("post_id", "type").
+		From("post_reactions").
+		Where(sq.Eq{"user_id": userId, "post_id": postIds}).
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error creating SQL for FindUserReactions: %v", err)
+	}
+
+	rows, err := repo.db.QueryContext(context.Background(), sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error executing FindUserReactions query: %v", err)
+	}
+	defer rows.Close()
+
+	reacted := make(map[string]map[string]bool)
+	for rows.Next() {
+		var postId, reactionType string
+		if err := rows.Scan(&postId, &reactionType); err != nil {
+			return nil, fmt.Errorf("error scanning row in FindUserReactions: %v", err)
+		}
+		if reacted[postId] == nil {
+			reacted[postId] = make(map[string]bool)
+		}
+		reacted[postId][reactionType] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after iterating rows in FindUserReactions: %v", err)
+	}
+
+	return reacted, nil
+}
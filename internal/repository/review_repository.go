@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-blog/internal/types"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// ReviewRepository keeps the review history of posts.
+type ReviewRepository interface {
+	FindByPost(postId string) ([]types.Review, error)
+	Create(review types.Review) (*types.Review, error)
+	CreateWithStatus(review types.Review, version int) (*types.Review, error)
+}
+
+type reviewRepository struct {
+	db *sql.DB
+}
+
+func NewReviewRepository(db *sql.DB) ReviewRepository {
+	return &reviewRepository{db: db}
+}
+
+// FindByPost returns the reviews of a post, oldest first.
+func (repo reviewRepository) FindByPost(postId string) ([]types.Review, error) {
+	sql, args, err := sq.Select("post_reviews.id, post_reviews.post_id, post_reviews.status, post_reviews.comment, post_reviews.created_at, users.id, users.name, users.lastname").
+		From("post_reviews").
+		Join("users ON post_reviews.user_id = users.id").
+		Where(sq.Eq{"post_reviews.post_id": postId}).
+		OrderBy("post_reviews.created_at ASC", "post_reviews.id ASC").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error creating SQL for FindByPost: %v", err)
+	}
+
+	rows, err := repo.db.QueryContext(context.Background(), sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error executing FindByPost query: %v", err)
+	}
+	defer rows.Close()
+
+	reviews := []types.Review{}
+	for rows.Next() {
+		var review types.Review
+		err := rows.Scan(
+			&review.Id,
+			&review.PostId,
+			&review.Status,
+			&review.Comment,
+			&review.CreatedAt,
+			&review.Author.Id,
+			&review.Author.Name,
+			&review.Author.Lastname,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning row in FindByPost: %v", err)
+		}
+		reviews = append(reviews, review)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after iterating rows in FindByPost: %v", err)
+	}
+
+	return reviews, nil
+}
+
+func (repo reviewRepository) Create(review types.Review) (*types.Review, error) {
+	return insertReview(repo.db, review)
+}
+
+// CreateWithStatus moves the post to the status of the review and saves the review in
+// one transaction, so that neither is saved without the other. The post is only moved
+// from the version the caller read, ErrVersionConflict is returned otherwise.
+func (repo reviewRepository) CreateWithStatus(review types.Review, version int) (*types.Review, error) {
+	tx, err := repo.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+
+	sql, args, err := sq.Update("posts").
+		Set("status", review.Status).
+		Set("version", sq.Expr("version + 1")).
+		Set("updated_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Where(sq.Eq{"id": review.PostId, "version": version}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("error creating SQL for CreateWithStatus: %v", err)
+	}
+
+	result, err := tx.ExecContext(context.Background(), sql, args...)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("error executing CreateWithStatus query: %v", err)
+	}
+	if rows, err := result.RowsAffected(); err != nil || rows == 0 {
+		tx.Rollback()
+		return nil, ErrVersionConflict
+	}
+
+	createdReview, err := insertReview(tx, review)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return createdReview, nil
+}
+
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func insertReview(q queryRower, review types.Review) (*types.Review, error) {
+	sql, args, err := sq.Insert("post_reviews").
+		Columns("post_id", "user_id", "status", "comment").
+		Values(review.PostId, review.Author.Id, review.Status, review.Comment).
+		Suffix("RETURNING id, created_at").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error creating SQL for Create: %v", err)
+	}
+
+	err = q.QueryRowContext(context.Background(), sql, args...).Scan(
+		&review.Id,
+		&review.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error executing Create query: %v", err)
+	}
+
+	return &review, nil
+}
@@ -0,0 +1,367 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-blog/internal/types"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+type SeriesRepository interface {
+	FindAll() ([]types.Series, error)
+	FindBySlug(slug string) (*types.Series, error)
+	FindById(id string) (*types.Series, error)
+	FindByPost(postId string) (*types.Series, error)
+	Create(series types.Series) (*types.Series, error)
+	Update(id string, series types.Series) (*types.Series, error)
+	Delete(id string) error
+	UpdateSeriesPosts(seriesId string, postIds []string) ([]types.SeriesPart, error)
+}
+
+type seriesRepository struct {
+	db *sql.DB
+}
+
+func NewSeriesRepository(db *sql.DB) SeriesRepository {
+	return &seriesRepository{db: db}
+}
+
+// seriesPartCount counts the parts of a series listed to readers, see findParts.
+const seriesPartCount = "(SELECT COUNT(*) FROM series_posts JOIN posts ON posts.id = series_posts.post_id WHERE series_posts.series_id = series.id AND posts.deleted_at IS NULL AND " + publishedPost + ")"
+
+func selectSeries() sq.SelectBuilder {
+	return sq.Select("series.id, series.title, series.slug, series.description, series.user_id, " + seriesPartCount + ", series.created_at").
+		From("series").
+		PlaceholderFormat(sq.Dollar)
+}
+
+func scanSeries(row rowScanner) (*types.Series, error) {
+	var series types.Series
+	err := row.Scan(
+		&series.Id,
+		&series.Title,
+		&series.Slug,
+		&series.Description,
+		&series.OwnerId,
+		&series.PartCount,
+		&series.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &series, nil
+}
+
+// queryer is implemented by both *sql.DB and *sql.Tx.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// findParts returns the posts of a series in reading order. Unless all is set, only the
+// published posts listed to readers are parts, so that drafts are neither numbered nor
+// linked to from the navigation of the series.
+func findParts(q queryer, seriesId string, all bool) ([]types.SeriesPart, error) {
+	query := sq.Select("posts.id, posts.title, posts.slug").
+		From("series_posts").
+		Join("posts ON posts.id = series_posts.post_id").
+		Where(sq.Eq{"series_posts.series_id": seriesId}).
+		Where("posts.deleted_at IS NULL").
+		OrderBy("series_posts.position").
+		PlaceholderFormat(sq.Dollar)
+	if !all {
+		query = query.Where(publishedPost)
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error creating SQL for series parts: %v", err)
+	}
+
+	rows, err := q.QueryContext(context.Background(), sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error executing series parts query: %v", err)
+	}
+	defer rows.Close()
+
+	parts := []types.SeriesPart{}
+	for rows.Next() {
+		part := types.SeriesPart{Part: len(parts) + 1}
+		if err := rows.Scan(&part.Id, &part.Title, &part.Slug); err != nil {
+			return nil, fmt.Errorf("error scanning series part: %v", err)
+		}
+		parts = append(parts, part)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after iterating series parts: %v", err)
+	}
+
+	return parts, nil
+}
+
+func (repo seriesRepository) FindAll() ([]types.Series, error) {
+	sql, args, err := selectSeries().
+		OrderBy("series.created_at DESC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error creating SQL for FindAll: %v", err)
+	}
+
+	rows, err := repo.db.QueryContext(context.Background(), sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error executing FindAll query: %v", err)
+	}
+	defer rows.Close()
+
+	seriesList := []types.Series{}
+	for rows.Next() {
+		series, err := scanSeries(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning row in FindAll: %v", err)
+		}
+		seriesList = append(seriesList, *series)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after iterating rows in FindAll: %v", err)
+	}
+
+	return seriesList, nil
+}
+
+func (repo seriesRepository) FindBySlug(slug string) (*types.Series, error) {
+	sql, args, err := selectSeries().
+		Where(sq.Eq{"series.slug": slug}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error creating SQL for FindBySlug: %v", err)
+	}
+
+	series, err := scanSeries(repo.db.QueryRowContext(context.Background(), sql, args...))
+	if err != nil {
+		// The series may have been renamed, the caller can tell by comparing slugs
+		if seriesId, historyErr := findSlugOwner(repo.db, "series_slug_history", "series_id", slug); historyErr == nil {
+			return repo.FindById(seriesId)
+		}
+
+		return nil, fmt.Errorf("error executing FindBySlug query: %v", err)
+	}
+
+	series.Parts, err = findParts(repo.db, series.Id, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return series, nil
+}
+
+func (repo seriesRepository) FindById(id string) (*types.Series, error) {
+	sql, args, err := selectSeries().
+		Where(sq.Eq{"series.id": id}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error creating SQL for FindById: %v", err)
+	}
+
+	series, err := scanSeries(repo.db.QueryRowContext(context.Background(), sql, args...))
+	if err != nil {
+		return nil, fmt.Errorf("error executing FindById query: %v", err)
+	}
+
+	series.Parts, err = findParts(repo.db, series.Id, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return series, nil
+}
+
+// FindByPost returns the series the post is part of, or nil when it isn't part of any.
+func (repo seriesRepository) FindByPost(postId string) (*types.Series, error) {
+	var seriesId string
+	err := repo.db.QueryRowContext(context.Background(), "SELECT series_id FROM series_posts WHERE post_id = $1", postId).Scan(&seriesId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error executing FindByPost query: %v", err)
+	}
+
+	return repo.FindById(seriesId)
+}
+
+func (repo seriesRepository) Create(series types.Series) (*types.Series, error) {
+	tx, err := repo.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+
+	slug, err := uniqueSlug(tx, "series", "series_slug_history", "series_id", series.Slug, "")
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("error generating unique slug: %v", err)
+	}
+
+	sql, args, err := sq.Insert("series").
+		Columns("title", "slug", "description", "user_id").
+		Values(series.Title, slug, series.Description, series.OwnerId).
+		Suffix("RETURNING id, title, slug, description, user_id, created_at").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("error creating SQL for Create: %v", err)
+	}
+
+	var createdSeries types.Series
+	err = tx.QueryRowContext(context.Background(), sql, args...).Scan(
+		&createdSeries.Id,
+		&createdSeries.Title,
+		&createdSeries.Slug,
+		&createdSeries.Description,
+		&createdSeries.OwnerId,
+		&createdSeries.CreatedAt,
+	)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("error executing Create query: %v", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	createdSeries.Parts = []types.SeriesPart{}
+	return &createdSeries, nil
+}
+
+func (repo seriesRepository) Update(id string, series types.Series) (*types.Series, error) {
+	existingSeries, err := repo.FindById(id)
+	if err != nil {
+		return nil, fmt.Errorf("error finding series to update: %v", err)
+	}
+
+	tx, err := repo.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+
+	slug := existingSeries.Slug
+	if series.Slug != existingSeries.Slug {
+		slug, err = uniqueSlug(tx, "series", "series_slug_history", "series_id", series.Slug, id)
+		if err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("error generating unique slug for update: %v", err)
+		}
+	}
+
+	sql, args, err := sq.Update("series").
+		Set("title", series.Title).
+		Set("slug", slug).
+		Set("description", series.Description).
+		Where(sq.Eq{"id": id}).
+		Suffix("RETURNING id, title, slug, description, user_id, created_at").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("error creating SQL for Update: %v", err)
+	}
+
+	var updatedSeries types.Series
+	err = tx.QueryRowContext(context.Background(), sql, args...).Scan(
+		&updatedSeries.Id,
+		&updatedSeries.Title,
+		&updatedSeries.Slug,
+		&updatedSeries.Description,
+		&updatedSeries.OwnerId,
+		&updatedSeries.CreatedAt,
+	)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("error executing Update query: %v", err)
+	}
+
+	if err := recordSlugChange(tx, "series_slug_history", "series_id", id, existingSeries.Slug, updatedSeries.Slug); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	updatedSeries.PartCount = existingSeries.PartCount
+	updatedSeries.Parts = existingSeries.Parts
+
+	return &updatedSeries, nil
+}
+
+func (repo seriesRepository) Delete(id string) error {
+	sql, args, err := sq.Delete("series").
+		Where(sq.Eq{"id": id}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("error creating SQL for Delete: %v", err)
+	}
+
+	result, err := repo.db.ExecContext(context.Background(), sql, args...)
+	if err != nil {
+		return fmt.Errorf("error executing Delete query: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("couldn't get affected rows: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("no series found with id %s to delete", id)
+	}
+
+	return nil
+}
+
+// UpdateSeriesPosts replaces the posts of a series, in reading order, and returns
+// the resulting parts, drafts included. A post already part of another series is rejected.
+func (repo seriesRepository) UpdateSeriesPosts(seriesId string, postIds []string) ([]types.SeriesPart, error) {
+	tx, err := repo.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %v", err)
+	}
+
+	_, err = tx.ExecContext(context.Background(), "DELETE FROM series_posts WHERE series_id = $1", seriesId)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("error removing existing posts: %v", err)
+	}
+
+	for position, postId := range postIds {
+		_, err = tx.ExecContext(context.Background(), "INSERT INTO series_posts (series_id, post_id, position) VALUES ($1, $2, $3)", seriesId, postId, position)
+		if err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("error adding post %s to series: %v", postId, err)
+		}
+	}
+
+	// The owner sees the drafts they added too
+	parts, err := findParts(tx, seriesId, true)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return parts, nil
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// uniqueSlug returns baseSlug, or baseSlug-N with the lowest free N, skipping the
+// current and former slugs of every other row. It takes a transaction level lock on
+// the table's slugs first, so concurrent writers can't end up with the same slug.
+func uniqueSlug(tx *sql.Tx, table, historyTable, foreignKey, baseSlug, excludeId string) (string, error) {
+	current := slugsLike(table, baseSlug)
+	former := slugsLike(historyTable, baseSlug)
+	if excludeId != "" {
+		current = current.Where(sq.NotEq{"id": excludeId})
+		former = former.Where(sq.NotEq{foreignKey: excludeId})
+	}
+
+	return firstFreeSlug(tx, table+".slug", baseSlug, current, former)
+}
+
+// uniquePostSlug does the same for the slug of a post or post translation, which only
+// has to be unique among the posts and translations in the same language. Former
+// slugs are skipped whatever their language, since they keep redirecting.
+func uniquePostSlug(tx *sql.Tx, locale, baseSlug, excludePostId string) (string, error) {
+	current := slugsLike("posts", baseSlug).Where(sq.Eq{"locale": locale})
+	translated := slugsLike("post_translations", baseSlug).Where(sq.Eq{"locale": locale})
+	former := slugsLike("post_slug_history", baseSlug)
+	if excludePostId != "" {
+		current = current.Where(sq.NotEq{"id": excludePostId})
+		translated = translated.Where(sq.NotEq{"post_id": excludePostId})
+		former = former.Where(sq.NotEq{"post_id": excludePostId})
+	}
+
+	return firstFreeSlug(tx, "posts.slug", baseSlug, current, translated, former)
+}
+
+// slugsLike selects the slugs of table which are baseSlug or one of its numbered variants.
+func slugsLike(table, baseSlug string) sq.SelectBuilder {
+	return sq.Select("slug").
+		From(table).
+		Where(sq.Or{sq.Eq{"slug": baseSlug}, sq.Like{"slug": baseSlug + "-%"}})
+}
+
+// firstFreeSlug locks lockKey and returns the first of baseSlug, baseSlug-1, ... which
+// none of the queries select.
+func firstFreeSlug(tx *sql.Tx, lockKey, baseSlug string, queries ...sq.SelectBuilder) (string, error) {
+	if _, err := tx.ExecContext(context.Background(), "SELECT pg_advisory_xact_lock(hashtext($1))", lockKey); err != nil {
+		return "", fmt.Errorf("error locking %s: %v", lockKey, err)
+	}
+
+	union := queries[0]
+	for _, query := range queries[1:] {
+		querySql, queryArgs, err := query.ToSql()
+		if err != nil {
+			return "", fmt.Errorf("error creating SQL for slug check: %v", err)
+		}
+		union = union.Suffix("UNION "+querySql, queryArgs...)
+	}
+
+	query, args, err := union.
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return "", fmt.Errorf("error creating SQL for slug check: %v", err)
+	}
+
+	rows, err := tx.QueryContext(context.Background(), query, args...)
+	if err != nil {
+		return "", fmt.Errorf("error checking slug existence: %v", err)
+	}
+	defer rows.Close()
+
+	taken := make(map[string]bool)
+	for rows.Next() {
+		var slug string
+		if err := rows.Scan(&slug); err != nil {
+			return "", fmt.Errorf("error scanning taken slug: %v", err)
+		}
+		taken[slug] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("error after iterating taken slugs: %v", err)
+	}
+
+	slug := baseSlug
+	for counter := 1; taken[slug]; counter++ {
+		slug = fmt.Sprintf("%s-%d", baseSlug, counter)
+	}
+
+	return slug, nil
+}
+
+// recordSlugChange keeps oldSlug pointing at the renamed row, and forgets newSlug
+// in case the row is taking back one of its former slugs.
+func recordSlugChange(tx *sql.Tx, historyTable, foreignKey, id, oldSlug, newSlug string) error {
+	if oldSlug == newSlug {
+		return nil
+	}
+
+	insertSql, insertArgs, err := sq.Insert(historyTable).
+		Columns("slug", foreignKey).
+		Values(oldSlug, id).
+		Suffix(fmt.Sprintf("ON CONFLICT (slug) DO UPDATE SET %s = EXCLUDED.%s, created_at = CURRENT_TIMESTAMP", foreignKey, foreignKey)).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("error creating SQL for slug history: %v", err)
+	}
+
+	if _, err := tx.ExecContext(context.Background(), insertSql, insertArgs...); err != nil {
+		return fmt.Errorf("error saving slug history: %v", err)
+	}
+
+	deleteSql, deleteArgs, err := sq.Delete(historyTable).
+		Where(sq.Eq{"slug": newSlug}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("error creating SQL for slug history cleanup: %v", err)
+	}
+
+	if _, err := tx.ExecContext(context.Background(), deleteSql, deleteArgs...); err != nil {
+		return fmt.Errorf("error cleaning up slug history: %v", err)
+	}
+
+	return nil
+}
+
+// findSlugOwner returns the id of the row which used to be known by slug.
+func findSlugOwner(db *sql.DB, historyTable, foreignKey, slug string) (string, error) {
+	query, args, err := sq.Select(foreignKey).
+		From(historyTable).
+		Where(sq.Eq{"slug": slug}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return "", fmt.Errorf("error creating SQL for slug history lookup: %v", err)
+	}
+
+	var id string
+	if err := db.QueryRowContext(context.Background(), query, args...).Scan(&id); err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
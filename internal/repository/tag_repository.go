@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-blog/internal/types"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+type TagRepository interface {
+	FindAll() ([]types.Tag, error)
+	FindBySlug(slug string) (*types.Tag, error)
+	FindById(id string) (*types.Tag, error)
+	Rename(id string, tag types.Tag) (*types.Tag, error)
+	Merge(sourceId string, targetId string) error
+}
+
+type tagRepository struct {
+	db *sql.DB
+}
+
+func NewTagRepository(db *sql.DB) TagRepository {
+	return &tagRepository{db: db}
+}
+
+// FindAll returns the tag cloud: the tags of the posts listed to readers, counting those
+// posts. Tags of nothing but drafts, unlisted or trashed posts are left out.
+func (repo tagRepository) FindAll() ([]types.Tag, error) {
+	var tags []types.Tag
+
+	sql, args, err := sq.Select("tags.id, tags.name, tags.slug, tags.created_at, COUNT(post_tags.post_id) AS post_count").
+		From("tags").
+		Join("post_tags ON tags.id = post_tags.tag_id").
+		Join("posts ON posts.id = post_tags.post_id").
+		Where("posts.deleted_at IS NULL").
+		Where(publishedPost).
+		GroupBy("tags.id").
+		OrderBy("post_count DESC", "tags.name ASC").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("error creating SQL for FindAll: %v", err)
+	}
+
+	rows, err := repo.db.QueryContext(context.Background(), sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error executing FindAll query: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tag types.Tag
+		err := rows.Scan(
+			&tag.Id,
+			&tag.Name,
+			&tag.Slug,
+			&tag.CreatedAt,
+			&tag.PostCount,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning row in FindAll: %v", err)
+		}
+		tags = append(tags, tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after iterating rows in FindAll: %v", err)
+	}
+
+	return tags, nil
+}
+
+func (repo tagRepository) FindBySlug(slug string) (*types.Tag, error) {
+	query := sq.Select("id, name, slug, created_at").
+		From("tags").
+		PlaceholderFormat(sq.Dollar).
+		Where(sq.Eq{"slug": slug})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error creating SQL for FindBySlug: %v", err)
+	}
+
+	var tag types.Tag
+	err = repo.db.QueryRowContext(context.Background(), sql, args...).Scan(
+		&tag.Id,
+		&tag.Name,
+		&tag.Slug,
+		&tag.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("error executing FindBySlug query: %v", err)
+	}
+
+	return &tag, nil
+}
+
+func (repo tagRepository) FindById(id string) (*types.Tag, error) {
+	query := sq.Select("id, name, slug, created_at").
+		From("tags").
+		PlaceholderFormat(sq.Dollar).
+		Where(sq.Eq{"id": id})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error creating SQL for FindById: %v", err)
+	}
+
+	var tag types.Tag
+	err = repo.db.QueryRowContext(context.Background(), sql, args...).Scan(
+		&tag.Id,
+		&tag.Name,
+		&tag.Slug,
+		&tag.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("error executing FindById query: %v", err)
+	}
+
+	return &tag, nil
+}
+
+func (repo tagRepository) Rename(id string, tag types.Tag) (*types.Tag, error) {
+	var exists bool
+	err := repo.db.QueryRowContext(
+		context.Background(),
+		"SELECT EXISTS(SELECT 1 FROM tags WHERE slug = $1 AND id != $2)",
+		tag.Slug, id,
+	).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("error checking slug existence: %v", err)
+	}
+
+	if exists {
+		return nil, fmt.Errorf("a tag with slug %s already exists, merge the tags instead", tag.Slug)
+	}
+
+	updateQuery := sq.Update("tags").
+		Set("name", tag.Name).
+		Set("slug", tag.Slug).
+		Where(sq.Eq{"id": id}).
+		Suffix("RETURNING id, name, slug, created_at").
+		PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := updateQuery.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error creating SQL for Rename: %v", err)
+	}
+
+	var renamedTag types.Tag
+	err = repo.db.QueryRowContext(context.Background(), sql, args...).Scan(
+		&renamedTag.Id,
+		&renamedTag.Name,
+		&renamedTag.Slug,
+		&renamedTag.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("error executing Rename query: %v", err)
+	}
+
+	return &renamedTag, nil
+}
+
+// Merge moves every post of the source tag to the target tag and removes the source tag.
+func (repo tagRepository) Merge(sourceId string, targetId string) error {
+	if sourceId == targetId {
+		return fmt.Errorf("cannot merge a tag into itself")
+	}
+
+	tx, err := repo.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+
+	_, err = tx.ExecContext(
+		context.Background(),
+		"INSERT INTO post_tags (post_id, tag_id) SELECT post_id, $1 FROM post_tags WHERE tag_id = $2 ON CONFLICT DO NOTHING",
+		targetId, sourceId,
+	)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("error moving posts to target tag: %v", err)
+	}
+
+	result, err := tx.ExecContext(context.Background(), "DELETE FROM tags WHERE id = $1", sourceId)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("error deleting source tag: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("couldn't get affected rows: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		tx.Rollback()
+		return fmt.Errorf("no tag found with id %s to merge", sourceId)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("error committing transaction: %v", err)
+	}
+
+	return nil
+}
@@ -17,6 +17,7 @@ type UserRepository interface {
 	FindById(id string) (*types.User, error)
 	Create(user types.User) (*types.User, error)
 	Update(id string, user types.User) (*types.User, error)
+	Patch(id string, user types.User) (*types.User, error)
 	Delete(id string) error
 }
 
@@ -64,7 +65,7 @@ func (repo userRepository) FindAll() ([]types.User, error) {
 func (repo userRepository) FindByEmail(email string) (*types.User, error) {
 	var user types.User
 
-	sql, args, err := sq.Select("id, name, lastname, email, password, created_at").
+	sql, args, err := sq.Select("id, name, lastname, email, password, role, created_at").
 		From("users").
 		Where(sq.Eq{"email": email}).
 		PlaceholderFormat(sq.Dollar).
@@ -74,7 +75,7 @@ func (repo userRepository) FindByEmail(email string) (*types.User, error) {
 	}
 
 	err = repo.db.QueryRowContext(context.Background(), sql, args...).
-		Scan(&user.Id, &user.Name, &user.Lastname, &user.Email, &user.Password, &user.CreatedAt)
+		Scan(&user.Id, &user.Name, &user.Lastname, &user.Email, &user.Password, &user.Role, &user.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -85,7 +86,7 @@ func (repo userRepository) FindByEmail(email string) (*types.User, error) {
 func (repo userRepository) FindById(id string) (*types.User, error) {
 	var user types.User
 
-	sql, args, err := sq.Select("id, name, lastname, email, created_at").
+	sql, args, err := sq.Select("id, name, lastname, email, role, created_at").
 		From("users").
 		Where(sq.Eq{"id": id}).
 		PlaceholderFormat(sq.Dollar).
@@ -95,7 +96,7 @@ func (repo userRepository) FindById(id string) (*types.User, error) {
 	}
 
 	err = repo.db.QueryRowContext(context.Background(), sql, args...).
-		Scan(&user.Id, &user.Name, &user.Lastname, &user.Email, &user.CreatedAt)
+		Scan(&user.Id, &user.Name, &user.Lastname, &user.Email, &user.Role, &user.CreatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("error executing FindById query: %v", err)
 	}
@@ -134,41 +135,59 @@ func (repo userRepository) Create(user types.User) (*types.User, error) {
 }
 
 func (repo userRepository) Update(id string, user types.User) (*types.User, error) {
-	checkSQL, checkArgs, err := sq.Select("id").
-		From("users").
-		Where(sq.And{
-			sq.Eq{"email": user.Email},
-			sq.NotEq{"id": id},
-		}).
-		PlaceholderFormat(sq.Dollar).
-		ToSql()
+	return repo.save(id, user, nil, "Update")
+}
+
+// Patch is Update writing only the name, lastname or email when it differs from the
+// stored one. The user is returned as stored when none differs.
+func (repo userRepository) Patch(id string, user types.User) (*types.User, error) {
+	existingUser, err := repo.FindById(id)
 	if err != nil {
-		return nil, fmt.Errorf("error creating SQL for email check: %v", err)
+		return nil, fmt.Errorf("error finding user to patch: %v", err)
 	}
 
-	var existingID string
-	err = repo.db.QueryRowContext(context.Background(), checkSQL, checkArgs...).Scan(&existingID)
-	if err != nil && err != sql.ErrNoRows {
-		return nil, fmt.Errorf("error checking existing email: %v", err)
+	return repo.save(id, user, existingUser, "Patch")
+}
+
+// save writes the name, lastname and email of the user, or only the ones differing
+// from existingUser when given.
+func (repo userRepository) save(id string, user types.User, existingUser *types.User, method string) (*types.User, error) {
+	nameChanged := existingUser == nil || user.Name != existingUser.Name
+	lastnameChanged := existingUser == nil || user.Lastname != existingUser.Lastname
+	emailChanged := existingUser == nil || user.Email != existingUser.Email
+
+	if !nameChanged && !lastnameChanged && !emailChanged {
+		return existingUser, nil
 	}
-	if existingID != "" {
-		return nil, fmt.Errorf("a user with email %s already exists", user.Email)
+
+	if emailChanged {
+		if err := repo.checkEmailFree(id, user.Email); err != nil {
+			return nil, err
+		}
 	}
 
-	sql, args, err := sq.Update("users").
-		Set("name", user.Name).
-		Set("lastname", user.Lastname).
-		Set("email", user.Email).
+	updateQuery := sq.Update("users")
+	if nameChanged {
+		updateQuery = updateQuery.Set("name", user.Name)
+	}
+	if lastnameChanged {
+		updateQuery = updateQuery.Set("lastname", user.Lastname)
+	}
+	if emailChanged {
+		updateQuery = updateQuery.Set("email", user.Email)
+	}
+
+	sql, args, err := updateQuery.
 		Where(sq.Eq{"id": id}).
 		PlaceholderFormat(sq.Dollar).
 		ToSql()
 	if err != nil {
-		return nil, fmt.Errorf("error creating SQL for Update: %v", err)
+		return nil, fmt.Errorf("error creating SQL for %s: %v", method, err)
 	}
 
 	result, err := repo.db.ExecContext(context.Background(), sql, args...)
 	if err != nil {
-		return nil, fmt.Errorf("error executing Update query: %v", err)
+		return nil, fmt.Errorf("error executing %s query: %v", method, err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -182,6 +201,32 @@ func (repo userRepository) Update(id string, user types.User) (*types.User, erro
 	return &user, nil
 }
 
+// checkEmailFree fails when another user than id has the email.
+func (repo userRepository) checkEmailFree(id, email string) error {
+	checkSQL, checkArgs, err := sq.Select("id").
+		From("users").
+		Where(sq.And{
+			sq.Eq{"email": email},
+			sq.NotEq{"id": id},
+		}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("error creating SQL for email check: %v", err)
+	}
+
+	var existingID string
+	err = repo.db.QueryRowContext(context.Background(), checkSQL, checkArgs...).Scan(&existingID)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("error checking existing email: %v", err)
+	}
+	if existingID != "" {
+		return fmt.Errorf("a user with email %s already exists", email)
+	}
+
+	return nil
+}
+
 func (repo userRepository) Delete(id string) error {
 	sql, args, err := sq.Delete("users").
 		Where(sq.Eq{"id": id}).
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// ErrVersionConflict is returned by updates when the row changed since the caller read it.
+var ErrVersionConflict = errors.New("the resource was changed by someone else")
+
+// isNoRows reports whether err is sql.ErrNoRows, for functions where sql names a query.
+func isNoRows(err error) bool {
+	return errors.Is(err, sql.ErrNoRows)
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// bumpVersion marks the row of table as changed, for changes made to rows that
+// belong to it, such as the categories of a post.
+func bumpVersion(e execer, table, id string) error {
+	sql, args, err := sq.Update(table).
+		Set("version", sq.Expr("version + 1")).
+		Set("updated_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Where(sq.Eq{"id": id}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("error creating SQL for version bump: %v", err)
+	}
+
+	if _, err := e.ExecContext(context.Background(), sql, args...); err != nil {
+		return fmt.Errorf("error bumping %s version: %v", table, err)
+	}
+
+	return nil
+}
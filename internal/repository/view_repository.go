@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-blog/internal/types"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+const dateLayout = "2006-01-02"
+
+type ViewRepository interface {
+	SaveViews(views []types.PostView) error
+	FindPostStats(postId string, from, to time.Time) (*types.PostStats, error)
+	FindTopPosts(from, to time.Time, limit int) ([]types.TopPost, error)
+}
+
+type viewRepository struct {
+	db *sql.DB
+}
+
+func NewViewRepository(db *sql.DB) ViewRepository {
+	return &viewRepository{db: db}
+}
+
+// SaveViews inserts a batch of views, silently skipping visitors already counted that day.
+func (repo viewRepository) SaveViews(views []types.PostView) error {
+	if len(views) == 0 {
+		return nil
+	}
+
+	query := sq.Insert("post_views").
+		Columns("post_id", "day", "visitor_hash", "referrer_domain").
+		Suffix("ON CONFLICT DO NOTHING").
+		PlaceholderFormat(sq.Dollar)
+
+	for _, view := range views {
+		var referrer interface{}
+		if view.ReferrerDomain != "" {
+			referrer = view.ReferrerDomain
+		}
+		query = query.Values(view.PostId, view.Day.Format(dateLayout), view.VisitorHash, referrer)
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("error creating SQL for SaveViews: %v", err)
+	}
+
+	_, err = repo.db.ExecContext(context.Background(), sql, args...)
+	if err != nil {
+		return fmt.Errorf("error executing SaveViews query: %v", err)
+	}
+
+	return nil
+}
+
+func (repo viewRepository) FindPostStats(postId string, from, to time.Time) (*types.PostStats, error) {
+	series, err := repo.findDailyViews([]string{postId}, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := types.PostStats{
+		PostId:    postId,
+		Series:    dailySeries(series[postId], from, to),
+		Referrers: []types.ReferrerViews{},
+	}
+	for _, day := range stats.Series {
+		stats.TotalViews += day.Views
+	}
+
+	referrerSql, referrerArgs, err := sq.Select("COALESCE(referrer_domain, 'direct') AS domain", "COUNT(*) AS views").
+		From("post_views").
+		Where(sq.Eq{"post_id": postId}).
+		Where("day BETWEEN ? AND ?", from.Format(dateLayout), to.Format(dateLayout)).
+		GroupBy("domain").
+		OrderBy("views DESC").
+		Limit(10).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error creating SQL for referrers: %v", err)
+	}
+
+	rows, err := repo.db.QueryContext(context.Background(), referrerSql, referrerArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("error executing referrers query: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var referrer types.ReferrerViews
+		if err := rows.Scan(&referrer.Domain, &referrer.Views); err != nil {
+			return nil, fmt.Errorf("error scanning row in referrers: %v", err)
+		}
+		stats.Referrers = append(stats.Referrers, referrer)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after iterating rows in referrers: %v", err)
+	}
+
+	return &stats, nil
+}
+
+func (repo viewRepository) FindTopPosts(from, to time.Time, limit int) ([]types.TopPost, error) {
+	sql, args, err := sq.Select("posts.id, posts.title, posts.slug, COUNT(*) AS views").
+		From("post_views").
+		Join("posts ON posts.id = post_views.post_id AND posts.deleted_at IS NULL").
+		Where("post_views.day BETWEEN ? AND ?", from.Format(dateLayout), to.Format(dateLayout)).
+		GroupBy("posts.id").
+		OrderBy("views DESC", "posts.id").
+		Limit(uint64(limit)).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error creating SQL for FindTopPosts: %v", err)
+	}
+
+	rows, err := repo.db.QueryContext(context.Background(), sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error executing FindTopPosts query: %v", err)
+	}
+	defer rows.Close()
+
+	topPosts := []types.TopPost{}
+	var postIds []string
+	for rows.Next() {
+		var topPost types.TopPost
+		if err := rows.Scan(&topPost.Id, &topPost.Title, &topPost.Slug, &topPost.Views); err != nil {
+			return nil, fmt.Errorf("error scanning row in FindTopPosts: %v", err)
+		}
+		topPosts = append(topPosts, topPost)
+		postIds = append(postIds, topPost.Id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after iterating rows in FindTopPosts: %v", err)
+	}
+
+	if len(postIds) == 0 {
+		return topPosts, nil
+	}
+
+	series, err := repo.findDailyViews(postIds, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range topPosts {
+		topPosts[i].Series = dailySeries(series[topPosts[i].Id], from, to)
+	}
+
+	return topPosts, nil
+}
+
+// findDailyViews returns the number of views per post id and per day.
+func (repo viewRepository) findDailyViews(postIds []string, from, to time.Time) (map[string]map[string]int, error) {
+	sql, args, err := sq.Select("post_id, day, COUNT(*)").
+		From("post_views").
+		Where(sq.Eq{"post_id": postIds}).
+		Where("day BETWEEN ? AND ?", from.Format(dateLayout), to.Format(dateLayout)).
+		GroupBy("post_id", "day").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error creating SQL for daily views: %v", err)
+	}
+
+	rows, err := repo.db.QueryContext(context.Background(), sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error executing daily views query: %v", err)
+	}
+	defer rows.Close()
+
+	views := make(map[string]map[string]int)
+	for rows.Next() {
+		var postId string
+		var day time.Time
+		var count int
+		if err := rows.Scan(&postId, &day, &count); err != nil {
+			return nil, fmt.Errorf("error scanning row in daily views: %v", err)
+		}
+		if views[postId] == nil {
+			views[postId] = make(map[string]int)
+		}
+		views[postId][day.Format(dateLayout)] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after iterating rows in daily views: %v", err)
+	}
+
+	return views, nil
+}
+
+// dailySeries lists every day between from and to, filling days without views with zero.
+func dailySeries(counts map[string]int, from, to time.Time) []types.DailyViews {
+	series := []types.DailyViews{}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format(dateLayout)
+		series = append(series, types.DailyViews{Date: date, Views: counts[date]})
+	}
+	return series
+}
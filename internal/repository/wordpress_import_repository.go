@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// WordPressImportRepository remembers what each WordPress object was imported as, so
+// imports can be run again without creating anything twice. Objects are known by the
+// site they come from, since every site numbers its own from 1.
+type WordPressImportRepository interface {
+	FindImported(site string, kind string, wpId int64) (string, error)
+	RecordImport(site string, kind string, wpId int64, localId string) error
+}
+
+type wordPressImportRepository struct {
+	db *sql.DB
+}
+
+func NewWordPressImportRepository(db *sql.DB) WordPressImportRepository {
+	return &wordPressImportRepository{db: db}
+}
+
+// FindImported returns the id of what the WordPress object of the site with the given
+// kind and id was imported as, or an empty id when it hasn't been imported yet.
+func (repo wordPressImportRepository) FindImported(site string, kind string, wpId int64) (string, error) {
+	sql, args, err := sq.Select("local_id").
+		From("wordpress_imports").
+		Where(sq.Eq{"site": site, "kind": kind, "wp_id": wpId}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return "", fmt.Errorf("error creating SQL for FindImported: %v", err)
+	}
+
+	var localId string
+	err = repo.db.QueryRowContext(context.Background(), sql, args...).Scan(&localId)
+	if isNoRows(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("error executing FindImported query: %v", err)
+	}
+
+	return localId, nil
+}
+
+// RecordImport remembers the WordPress object as imported as localId, replacing what it
+// was imported as before.
+func (repo wordPressImportRepository) RecordImport(site string, kind string, wpId int64, localId string) error {
+	sql, args, err := sq.Insert("wordpress_imports").
+		Columns("site", "kind", "wp_id", "local_id").
+		Values(site, kind, wpId, localId).
+		Suffix("ON CONFLICT (site, kind, wp_id) DO UPDATE SET local_id = EXCLUDED.local_id, created_at = CURRENT_TIMESTAMP").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("error creating SQL for RecordImport: %v", err)
+	}
+
+	if _, err := repo.db.ExecContext(context.Background(), sql, args...); err != nil {
+		return fmt.Errorf("error executing RecordImport query: %v", err)
+	}
+
+	return nil
+}
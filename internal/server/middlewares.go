@@ -1,11 +1,80 @@
 package server
 
 import (
+	"go-blog/internal/types"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 )
 
+const (
+	defaultUnlockAttempts = 10
+	defaultUnlockWindow   = 15 * time.Minute
+)
+
 func (s *FiberServer) RegisterFiberMiddlewares() {
 	s.App.Use(logger.New(logger.Config{
 		Format: "[${ip}]:${port} ${status} - ${method} ${path}\n",
 	}))
 }
+
+// requireRole only lets authenticated users holding one of the given roles through.
+// It must run after the auth middleware.
+func requireRole(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(types.User)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "User not authenticated",
+			})
+		}
+
+		if !user.HasRole(roles...) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "You don't have permission to perform this action",
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+// optionalAuth resolves the session cookie when present so public routes can
+// personalise their response, but never rejects the request.
+func (s *FiberServer) optionalAuth(c *fiber.Ctx) error {
+	if token := c.Cookies("access_token"); token != "" {
+		s.authService.ValidateSession(c, token)
+	}
+
+	return c.Next()
+}
+
+// unlockLimiter throttles the failed attempts at the password of posts, each an expensive
+// bcrypt comparison, to POST_UNLOCK_ATTEMPTS per POST_UNLOCK_WINDOW from each IP. The post
+// unlock service throttles the attempts at each post once it is found.
+func unlockLimiter() fiber.Handler {
+	attempts, err := strconv.Atoi(os.Getenv("POST_UNLOCK_ATTEMPTS"))
+	if err != nil || attempts <= 0 {
+		attempts = defaultUnlockAttempts
+	}
+	window, err := time.ParseDuration(os.Getenv("POST_UNLOCK_WINDOW"))
+	if err != nil || window <= 0 {
+		window = defaultUnlockWindow
+	}
+
+	return limiter.New(limiter.Config{
+		Max:                    attempts,
+		Expiration:             window,
+		SkipSuccessfulRequests: true,
+		LimitReached: func(c *fiber.Ctx) error {
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+				"error":   "Too many attempts",
+				"message": "Too many wrong passwords, try unlocking the post again later",
+			})
+		},
+	})
+}
@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"fmt"
+	"go-blog/internal/types"
 	"log"
 	"time"
 
@@ -24,6 +25,8 @@ func (s *FiberServer) RegisterFiberRoutes() {
 		Title:    "Go Blog API Docs",
 	}))
 
+	s.App.Static("/uploads", "./uploads")
+
 	api := s.App.Group("/api")
 	authMiddleware := keyauth.New(keyauth.Config{
 		KeyLookup:    "cookie:access_token",
@@ -48,20 +51,66 @@ func (s *FiberServer) RegisterFiberRoutes() {
 	userRoutes.Use(authMiddleware)
 	{
 		userRoutes.Get("/", s.userHandler.GetUserHandler)
+		userRoutes.Patch("/me", s.userHandler.PatchMeHandler)
 		userRoutes.Get("/:id", s.userHandler.GetUserHandler)
 	}
 
+	unlockByIp := unlockLimiter()
 	postRoutes := api.Group("/posts")
 	{
-		postRoutes.Get("/", s.postHandler.GetPostHandler)
-		postRoutes.Get("/:slugOrId", s.postHandler.GetPostHandler)
+		postRoutes.Get("/", s.optionalAuth, s.postHandler.GetPostHandler)
+		postRoutes.Get("/:slugOrId", s.optionalAuth, s.postHandler.GetPostHandler)
+		postRoutes.Get("/:slugOrId/jsonld", s.optionalAuth, s.postHandler.GetPostJsonLdHandler)
+		postRoutes.Get("/:slugOrId/related", s.optionalAuth, s.postHandler.GetRelatedPostsHandler)
+		postRoutes.Post("/:slugOrId/unlock", unlockByIp, s.optionalAuth, s.postHandler.UnlockPostHandler)
 		postRoutes.Post("/", authMiddleware, s.postHandler.CreatePostHandler)
+		postRoutes.Post("/bulk", authMiddleware, s.postHandler.BulkPostsHandler)
 		postRoutes.Put("/:id", authMiddleware, s.postHandler.UpdatePostHandler)
+		postRoutes.Patch("/:id", authMiddleware, s.postHandler.PatchPostHandler)
 		postRoutes.Delete("/:id", authMiddleware, s.postHandler.DeletePostHandler)
+		postRoutes.Post("/:id/restore", authMiddleware, s.trashHandler.RestorePostHandler)
 		postRoutes.Post("/:postId/categories/:categoryId", authMiddleware, s.postHandler.AssignCategoryToPostHandler)
 		postRoutes.Delete("/:postId/categories/:categoryId", authMiddleware, s.postHandler.UnassignCategoryFromPostHandler)
 		postRoutes.Get("/:postId/categories", s.postHandler.GetCategoriesForPostHandler)
 		postRoutes.Put("/:postId/categories", authMiddleware, s.postHandler.UpdatePostCategoriesHandler)
+		postRoutes.Put("/:postId/authors", authMiddleware, s.postHandler.UpdatePostAuthorsHandler)
+		postRoutes.Put("/:postId/translations/:locale", authMiddleware, s.postHandler.SavePostTranslationHandler)
+		postRoutes.Delete("/:postId/translations/:locale", authMiddleware, s.postHandler.DeletePostTranslationHandler)
+		postRoutes.Get("/:postId/comments", s.optionalAuth, s.commentHandler.GetCommentsHandler)
+		postRoutes.Post("/:postId/comments", authMiddleware, s.commentHandler.CreateCommentHandler)
+		postRoutes.Put("/:id/reactions/:type", authMiddleware, s.reactionHandler.AddReactionHandler)
+		postRoutes.Delete("/:id/reactions/:type", authMiddleware, s.reactionHandler.RemoveReactionHandler)
+		postRoutes.Get("/:id/stats", authMiddleware, s.statsHandler.GetPostStatsHandler)
+		postRoutes.Get("/:id/reviews", authMiddleware, s.reviewHandler.GetReviewsHandler)
+		postRoutes.Post("/:id/reviews", authMiddleware, s.reviewHandler.CreateReviewHandler)
+		postRoutes.Put("/:id/reviewer", authMiddleware, requireRole(types.RoleEditor), s.reviewHandler.AssignReviewerHandler)
+		postRoutes.Get("/:id/preview-links", authMiddleware, s.postHandler.GetPreviewLinksHandler)
+		postRoutes.Post("/:id/preview-links", authMiddleware, s.postHandler.CreatePreviewLinkHandler)
+		postRoutes.Delete("/:id/preview-links/:linkId", authMiddleware, s.postHandler.RevokePreviewLinkHandler)
+	}
+
+	// Authenticated by the signed token of the preview link rather than a session
+	api.Get("/preview/:token", s.postHandler.GetPreviewHandler)
+
+	reviewRoutes := api.Group("/reviews")
+	reviewRoutes.Use(authMiddleware)
+	{
+		reviewRoutes.Get("/queue", requireRole(types.RoleEditor), s.reviewHandler.GetReviewQueueHandler)
+	}
+
+	statsRoutes := api.Group("/stats")
+	statsRoutes.Use(authMiddleware)
+	{
+		statsRoutes.Get("/top-posts", requireRole(types.RoleEditor), s.statsHandler.GetTopPostsHandler)
+	}
+
+	commentRoutes := api.Group("/comments")
+	commentRoutes.Use(authMiddleware)
+	{
+		commentRoutes.Get("/queue", requireRole(types.RoleEditor), s.commentHandler.GetModerationQueueHandler)
+		commentRoutes.Put("/:id", s.commentHandler.UpdateCommentHandler)
+		commentRoutes.Delete("/:id", s.commentHandler.DeleteCommentHandler)
+		commentRoutes.Put("/:id/status", requireRole(types.RoleEditor), s.commentHandler.ModerateCommentHandler)
 	}
 
 	categoryRoutes := api.Group("/categories")
@@ -70,9 +119,49 @@ func (s *FiberServer) RegisterFiberRoutes() {
 		categoryRoutes.Get("/:slugOrId", s.categoryHandler.GetCategoryHandler)
 		categoryRoutes.Post("/", authMiddleware, s.categoryHandler.CreateCategoryHandler)
 		categoryRoutes.Put("/:id", authMiddleware, s.categoryHandler.UpdateCategoryHandler)
+		categoryRoutes.Patch("/:id", authMiddleware, s.categoryHandler.PatchCategoryHandler)
 		categoryRoutes.Delete("/:id", authMiddleware, s.categoryHandler.DeleteCategoryHandler)
+		categoryRoutes.Post("/:id/restore", authMiddleware, s.trashHandler.RestoreCategoryHandler)
+	}
+
+	trashRoutes := api.Group("/trash")
+	trashRoutes.Use(authMiddleware)
+	{
+		trashRoutes.Get("/", s.trashHandler.GetTrashHandler)
+		trashRoutes.Delete("/posts/:id", s.trashHandler.PurgePostHandler)
+		trashRoutes.Delete("/categories/:id", s.trashHandler.PurgeCategoryHandler)
+	}
+
+	seriesRoutes := api.Group("/series")
+	{
+		seriesRoutes.Get("/", s.seriesHandler.GetSeriesHandler)
+		seriesRoutes.Get("/:slugOrId", s.seriesHandler.GetSeriesHandler)
+		seriesRoutes.Post("/", authMiddleware, s.seriesHandler.CreateSeriesHandler)
+		seriesRoutes.Put("/:id", authMiddleware, s.seriesHandler.UpdateSeriesHandler)
+		seriesRoutes.Delete("/:id", authMiddleware, s.seriesHandler.DeleteSeriesHandler)
+		seriesRoutes.Put("/:id/posts", authMiddleware, s.seriesHandler.UpdateSeriesPostsHandler)
+	}
+
+	tagRoutes := api.Group("/tags")
+	{
+		tagRoutes.Get("/", s.tagHandler.GetTagsHandler)
+		tagRoutes.Get("/:slug/posts", s.optionalAuth, s.tagHandler.GetTagPostsHandler)
+		tagRoutes.Put("/:id", authMiddleware, requireRole(types.RoleEditor), s.tagHandler.RenameTagHandler)
+		tagRoutes.Post("/:id/merge", authMiddleware, requireRole(types.RoleEditor), s.tagHandler.MergeTagHandler)
+	}
+
+	adminRoutes := api.Group("/admin")
+	adminRoutes.Use(authMiddleware, requireRole(types.RoleAdmin))
+	{
+		adminRoutes.Post("/import/wordpress", s.importHandler.ImportWordPressHandler)
+		adminRoutes.Get("/export/markdown", s.importHandler.ExportMarkdownHandler)
+		adminRoutes.Post("/import/markdown", s.importHandler.ImportMarkdownHandler)
+		adminRoutes.Get("/duplicates", s.duplicateHandler.GetDuplicatesHandler)
 	}
 
+	// Authenticated by the secret of the webhook rather than a session
+	api.Post("/sync/git", s.syncHandler.GitSyncHandler)
+
 	fileRoutes := api.Group("/files")
 	fileRoutes.Use(authMiddleware)
 	{
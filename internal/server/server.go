@@ -16,12 +16,22 @@ import (
 type FiberServer struct {
 	*fiber.App
 
-	dbStatus        map[string]string
-	userHandler     handler.UserHandler
-	authHandler     handler.AuthHandler
-	postHandler     handler.PostHandler
-	categoryHandler handler.CategoryHandler
-	fileHandler     handler.FileHandler
+	dbStatus         map[string]string
+	userHandler      handler.UserHandler
+	authHandler      handler.AuthHandler
+	postHandler      handler.PostHandler
+	categoryHandler  handler.CategoryHandler
+	tagHandler       handler.TagHandler
+	commentHandler   handler.CommentHandler
+	reactionHandler  handler.ReactionHandler
+	statsHandler     handler.StatsHandler
+	seriesHandler    handler.SeriesHandler
+	trashHandler     handler.TrashHandler
+	fileHandler      handler.FileHandler
+	importHandler    handler.ImportHandler
+	syncHandler      handler.SyncHandler
+	reviewHandler    handler.ReviewHandler
+	duplicateHandler handler.DuplicateHandler
 
 	authService service.AuthService
 }
@@ -32,24 +42,59 @@ func New() *FiberServer {
 	var userRepository = repository.NewUserRepository(db.GetInstance())
 	var postRepository = repository.NewPostRepository(db.GetInstance())
 	var categoryRepository = repository.NewCategoryRepository(db.GetInstance())
+	var tagRepository = repository.NewTagRepository(db.GetInstance())
+	var commentRepository = repository.NewCommentRepository(db.GetInstance())
+	var reactionRepository = repository.NewReactionRepository(db.GetInstance())
+	var viewRepository = repository.NewViewRepository(db.GetInstance())
+	var seriesRepository = repository.NewSeriesRepository(db.GetInstance())
+	var fileRepository = repository.NewFileRepository(db.GetInstance())
+	var wordPressImportRepository = repository.NewWordPressImportRepository(db.GetInstance())
+	var gitSyncRepository = repository.NewGitSyncRepository(db.GetInstance())
+	var reviewRepository = repository.NewReviewRepository(db.GetInstance())
+	var previewLinkRepository = repository.NewPreviewLinkRepository(db.GetInstance())
+	var duplicateRepository = repository.NewDuplicateRepository(db.GetInstance())
 
 	var authService = service.NewAuthService(userRepository)
 	var fileService = service.NewFileService()
+	var viewService = service.NewViewService(viewRepository)
+	var slugService = service.NewSlugService()
+	var trashService = service.NewTrashService(postRepository, categoryRepository)
+	var relatedPostService = service.NewRelatedPostService(postRepository)
+	var wordPressImportService = service.NewWordPressImportService(wordPressImportRepository, userRepository, categoryRepository, postRepository, fileRepository, fileService, slugService)
+	var markdownService = service.NewMarkdownService(postRepository, categoryRepository, userRepository, slugService)
+	var gitSyncService = service.NewGitSyncService(gitSyncRepository, postRepository, categoryRepository, userRepository, slugService)
+	var postUnlockService = service.NewPostUnlockService()
+	var previewLinkService = service.NewPreviewLinkService(previewLinkRepository)
+	var duplicateService = service.NewDuplicateService(duplicateRepository)
 
 	server := &FiberServer{
 		App: fiber.New(fiber.Config{
 			ServerHeader: "go-blog",
 			AppName:      "go-blog",
 		}),
-		dbStatus:        db.Health(),
-		userHandler:     handler.NewUserHandler(userRepository),
-		authHandler:     handler.NewAuthHandler(authService),
-		postHandler:     handler.NewPostHandler(postRepository),
-		categoryHandler: handler.NewCategoryHandler(categoryRepository),
-		fileHandler:     handler.NewFileHandler(fileService),
-		authService:     authService,
+		dbStatus:         db.Health(),
+		userHandler:      handler.NewUserHandler(userRepository),
+		authHandler:      handler.NewAuthHandler(authService),
+		postHandler:      handler.NewPostHandler(postRepository, reactionRepository, seriesRepository, fileRepository, viewService, slugService, relatedPostService, postUnlockService, previewLinkService, duplicateService),
+		categoryHandler:  handler.NewCategoryHandler(categoryRepository, slugService, relatedPostService),
+		tagHandler:       handler.NewTagHandler(tagRepository, postRepository, slugService, relatedPostService, postUnlockService),
+		commentHandler:   handler.NewCommentHandler(commentRepository, postRepository, postUnlockService),
+		reactionHandler:  handler.NewReactionHandler(reactionRepository, postRepository, postUnlockService),
+		statsHandler:     handler.NewStatsHandler(viewRepository, postRepository),
+		seriesHandler:    handler.NewSeriesHandler(seriesRepository, postRepository, slugService),
+		trashHandler:     handler.NewTrashHandler(postRepository, categoryRepository, relatedPostService),
+		fileHandler:      handler.NewFileHandler(fileService, fileRepository),
+		importHandler:    handler.NewImportHandler(wordPressImportService, markdownService, relatedPostService),
+		syncHandler:      handler.NewSyncHandler(gitSyncService, userRepository, relatedPostService),
+		reviewHandler:    handler.NewReviewHandler(reviewRepository, postRepository, userRepository, relatedPostService),
+		duplicateHandler: handler.NewDuplicateHandler(duplicateService),
+		authService:      authService,
 	}
 
+	// Flush views still buffered in memory before the process exits
+	server.Hooks().OnShutdown(viewService.Stop)
+	server.Hooks().OnShutdown(trashService.Stop)
+
 	server.Use(logger.New(logger.Config{
 		Format: "[${time}] ${status} - ${latency} ${method} ${url}\n",
 	}))
@@ -57,7 +102,8 @@ func New() *FiberServer {
 	server.Use(cors.New(cors.Config{
 		AllowOrigins:     os.Getenv("CLIENT_URL"),
 		AllowMethods:     "GET,POST,HEAD,PUT,DELETE,PATCH,OPTIONS",
-		AllowHeaders:     "Origin, Content-Type, Accept",
+		AllowHeaders:     "Origin, Content-Type, Accept, Accept-Language, If-Match, If-None-Match",
+		ExposeHeaders:    "ETag, Content-Language",
 		AllowCredentials: true,
 	}))
 
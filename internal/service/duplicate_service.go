@@ -0,0 +1,162 @@
+package service
+
+import (
+	"go-blog/internal/repository"
+	"go-blog/internal/types"
+	"math"
+	"math/bits"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+const defaultDuplicateSimilarity = 0.9
+
+type DuplicateService interface {
+	FindNearDuplicates(postId string, content string, readerId string) ([]types.Duplicate, error)
+	FindClusters() ([]types.DuplicateCluster, error)
+}
+
+type duplicateService struct {
+	duplicateRepository repository.DuplicateRepository
+	maxDistance         int
+	mu                  sync.Mutex
+	backfilled          bool
+}
+
+// NewDuplicateService reports posts as near duplicates when the fingerprints of their
+// content are at least DUPLICATE_SIMILARITY similar, a share of their bits from 0 to 1.
+func NewDuplicateService(duplicateRepository repository.DuplicateRepository) DuplicateService {
+	similarity, err := strconv.ParseFloat(os.Getenv("DUPLICATE_SIMILARITY"), 64)
+	if err != nil || similarity <= 0 || similarity > 1 {
+		similarity = defaultDuplicateSimilarity
+	}
+
+	return &duplicateService{
+		duplicateRepository: duplicateRepository,
+		maxDistance:         int(math.Round((1 - similarity) * types.FingerprintBits)),
+	}
+}
+
+// backfill fingerprints the posts saved before posts had a fingerprint. Once done, every
+// post saved has one, so it is only done once.
+func (s *duplicateService) backfill() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.backfilled {
+		return nil
+	}
+
+	posts, err := s.duplicateRepository.FindUnfingerprinted()
+	if err != nil {
+		return err
+	}
+
+	for _, post := range posts {
+		// Content without words has no fingerprint to save
+		if fingerprint := types.ContentFingerprint(post.Content); fingerprint != 0 {
+			if err := s.duplicateRepository.SaveFingerprint(post.Id, fingerprint); err != nil {
+				return err
+			}
+		}
+	}
+
+	s.backfilled = true
+	return nil
+}
+
+// FindNearDuplicates returns the other posts whose content nearly duplicates the
+// content of the post, the most similar first. Only the posts readerId may read are
+// returned, all of them when readerId is empty.
+func (s *duplicateService) FindNearDuplicates(postId string, content string, readerId string) ([]types.Duplicate, error) {
+	fingerprint := types.ContentFingerprint(content)
+	if fingerprint == 0 {
+		return nil, nil
+	}
+
+	if err := s.backfill(); err != nil {
+		return nil, err
+	}
+
+	duplicates, err := s.duplicateRepository.FindNearDuplicates(postId, fingerprint, s.maxDistance, readerId)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range duplicates {
+		duplicates[i].Similarity = types.FingerprintSimilarity(fingerprint, duplicates[i].Fingerprint)
+	}
+	return duplicates, nil
+}
+
+// FindClusters groups the posts which nearly duplicate one another, directly or through
+// other posts of the cluster. The clusters with the most recent duplicate come first.
+func (s *duplicateService) FindClusters() ([]types.DuplicateCluster, error) {
+	if err := s.backfill(); err != nil {
+		return nil, err
+	}
+
+	posts, err := s.duplicateRepository.FindFingerprinted()
+	if err != nil {
+		return nil, err
+	}
+
+	// Union-find over the pairs of near duplicates, each post starts as its own cluster.
+	// Clusters are rooted at their oldest post.
+	parents := make([]int, len(posts))
+	for i := range parents {
+		parents[i] = i
+	}
+	var root func(i int) int
+	root = func(i int) int {
+		if parents[i] != i {
+			parents[i] = root(parents[i])
+		}
+		return parents[i]
+	}
+
+	for i := range posts {
+		for j := i + 1; j < len(posts); j++ {
+			if bits.OnesCount64(posts[i].Fingerprint^posts[j].Fingerprint) > s.maxDistance {
+				continue
+			}
+			if a, b := root(i), root(j); a < b {
+				parents[b] = a
+			} else {
+				parents[a] = b
+			}
+		}
+	}
+
+	sizes := make(map[int]int)
+	for i := range posts {
+		sizes[root(i)]++
+	}
+
+	// Posts come oldest first, so each cluster does too
+	clusterIndexes := make(map[int]int)
+	clusters := []types.DuplicateCluster{}
+	for i, post := range posts {
+		r := root(i)
+		if sizes[r] == 1 {
+			continue
+		}
+
+		index, ok := clusterIndexes[r]
+		if !ok {
+			index = len(clusters)
+			clusterIndexes[r] = index
+			clusters = append(clusters, types.DuplicateCluster{})
+		}
+
+		post.Similarity = types.FingerprintSimilarity(posts[r].Fingerprint, post.Fingerprint)
+		clusters[index].Posts = append(clusters[index].Posts, post)
+	}
+
+	slices.SortStableFunc(clusters, func(a, b types.DuplicateCluster) int {
+		return b.Posts[len(b.Posts)-1].CreatedAt.Compare(a.Posts[len(a.Posts)-1].CreatedAt)
+	})
+	return clusters, nil
+}
@@ -3,6 +3,10 @@ package service
 import (
 	"fmt"
 	"go-blog/internal/types"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
 	"io"
 	"mime/multipart"
 	"os"
@@ -15,7 +19,10 @@ import (
 type FileService interface {
 	GenerateUniqueFilename(filename string) (string, error)
 	SaveFile(file *multipart.FileHeader, filename string, user types.User) error
+	SaveLocalFile(path string, filename string, user types.User) error
 	DeleteFile(filename string, user types.User) error
+	ImageSize(file *multipart.FileHeader) (width int, height int)
+	LocalImageSize(path string) (width int, height int)
 }
 
 type fileService struct {
@@ -46,17 +53,33 @@ func (s *fileService) GenerateUniqueFilename(filename string) (string, error) {
 }
 
 func (s *fileService) SaveFile(file *multipart.FileHeader, filename string, user types.User) error {
-	userDir := filepath.Join(s.uploadDir, user.Id)
-	if err := os.MkdirAll(userDir, 0755); err != nil {
-		return fmt.Errorf("failed to create user directory: %w", err)
-	}
-
 	src, err := file.Open()
 	if err != nil {
 		return fmt.Errorf("failed to open uploaded file: %w", err)
 	}
 	defer src.Close()
 
+	return s.save(src, filename, user)
+}
+
+// SaveLocalFile copies a file already on the server, such as an imported attachment,
+// into the uploads of the user.
+func (s *fileService) SaveLocalFile(path string, filename string, user types.User) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open local file: %w", err)
+	}
+	defer src.Close()
+
+	return s.save(src, filename, user)
+}
+
+func (s *fileService) save(src io.Reader, filename string, user types.User) error {
+	userDir := filepath.Join(s.uploadDir, user.Id)
+	if err := os.MkdirAll(userDir, 0755); err != nil {
+		return fmt.Errorf("failed to create user directory: %w", err)
+	}
+
 	dst, err := os.Create(filepath.Join(userDir, filename))
 	if err != nil {
 		return fmt.Errorf("failed to create destination file: %w", err)
@@ -82,3 +105,35 @@ func (s *fileService) DeleteFile(filename string, user types.User) error {
 	}
 	return nil
 }
+
+// ImageSize returns the dimensions of an uploaded GIF, JPEG or PNG image, and zero
+// for any other file.
+func (s *fileService) ImageSize(file *multipart.FileHeader) (int, int) {
+	src, err := file.Open()
+	if err != nil {
+		return 0, 0
+	}
+	defer src.Close()
+
+	return imageSize(src)
+}
+
+// LocalImageSize is ImageSize for a file already on the server.
+func (s *fileService) LocalImageSize(path string) (int, int) {
+	src, err := os.Open(path)
+	if err != nil {
+		return 0, 0
+	}
+	defer src.Close()
+
+	return imageSize(src)
+}
+
+func imageSize(src io.Reader) (int, int) {
+	config, _, err := image.DecodeConfig(src)
+	if err != nil {
+		return 0, 0
+	}
+
+	return config.Width, config.Height
+}
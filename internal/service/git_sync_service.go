@@ -0,0 +1,237 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go-blog/internal/repository"
+	"go-blog/internal/types"
+	"os/exec"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrInvalidRepository is returned when the path to sync isn't a git repository with
+// the branch.
+var ErrInvalidRepository = errors.New("invalid git repository")
+
+type GitSyncService interface {
+	Sync(repoPath string, branch string, fallbackAuthor *types.User) (*types.GitSyncReport, error)
+}
+
+type gitSyncService struct {
+	gitSyncRepository repository.GitSyncRepository
+	markdownService   *markdownService
+	// Syncs triggered together would create the same posts twice
+	mutex sync.Mutex
+}
+
+func NewGitSyncService(gitSyncRepository repository.GitSyncRepository, postRepository repository.PostRepository, categoryRepository repository.CategoryRepository, userRepository repository.UserRepository, slugService SlugService) GitSyncService {
+	return &gitSyncService{
+		gitSyncRepository: gitSyncRepository,
+		markdownService: &markdownService{
+			postRepository:     postRepository,
+			categoryRepository: categoryRepository,
+			userRepository:     userRepository,
+			slugService:        slugService,
+		},
+	}
+}
+
+// gitFile is a Markdown file of the synced commit.
+type gitFile struct {
+	path    string
+	blobSha string
+	size    int64
+}
+
+// Sync mirrors the Markdown files of the last commit of branch, in the local git
+// repository at repoPath, to posts. Files are in the format of the Markdown export and
+// are imported the same way, except that once synced a file is matched to its post by
+// path, and the post of a file removed from the branch is archived. Translations are
+// matched by name every time, and stay when their file is removed. Files whose blob
+// hasn't changed since the last sync are skipped, and files without front matter aren't
+// posts, as for Jekyll. New posts whose author_email isn't a user are credited to
+// fallbackAuthor, or not created without it. Nothing is fetched, the repository is read
+// as it is.
+func (s *gitSyncService) Sync(repoPath string, branch string, fallbackAuthor *types.User) (*types.GitSyncReport, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if branch == "" || strings.HasPrefix(branch, "-") {
+		return nil, fmt.Errorf("%w: invalid branch %q", ErrInvalidRepository, branch)
+	}
+
+	commit, err := git(repoPath, "rev-parse", "--verify", "--quiet", branch+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("%w: no branch %s in %s: %v", ErrInvalidRepository, branch, repoPath, err)
+	}
+
+	files, err := gitMarkdownFiles(repoPath, strings.TrimSpace(string(commit)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRepository, err)
+	}
+	// Translations are imported once the post they translate exists
+	slices.SortStableFunc(files, func(a, b gitFile) int {
+		return compareLocaleSuffix(a.path, b.path)
+	})
+
+	syncedPosts, err := s.gitSyncRepository.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	syncedByPath := make(map[string]types.GitSyncedPost, len(syncedPosts))
+	for _, syncedPost := range syncedPosts {
+		syncedByPath[syncedPost.Path] = syncedPost
+	}
+
+	categories, err := s.markdownService.categoryRepository.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	run := &markdownImport{
+		markdownService: s.markdownService,
+		importer:        fallbackAuthor,
+		report:          types.MarkdownImportReport{Errors: []string{}},
+		categories:      categories,
+	}
+	report := &types.GitSyncReport{Commit: strings.TrimSpace(string(commit))}
+
+	posts := make(map[string]bool, len(files))
+	for _, file := range files {
+		syncedPost, synced := syncedByPath[file.path]
+		if synced && syncedPost.BlobSha == file.blobSha {
+			posts[file.path] = true
+			run.report.Unchanged++
+			continue
+		}
+
+		isPost, err := s.syncFile(run, repoPath, report.Commit, file, syncedPost.PostId)
+		if err != nil {
+			run.report.Errors = append(run.report.Errors, fmt.Sprintf("%s: %v", file.path, err))
+		}
+		// The post of a file which failed is left as it is
+		posts[file.path] = isPost || err != nil
+	}
+
+	for _, syncedPost := range syncedPosts {
+		if posts[syncedPost.Path] {
+			continue
+		}
+
+		archived, err := s.archive(syncedPost)
+		if err != nil {
+			run.report.Errors = append(run.report.Errors, fmt.Sprintf("%s: %v", syncedPost.Path, err))
+		}
+		if archived {
+			report.Archived++
+		}
+	}
+
+	report.MarkdownImportReport = run.report
+	return report, nil
+}
+
+// syncFile imports the file into the post it was synced as before, if any, and
+// remembers the post it was imported as. It reports whether the file is a post.
+func (s *gitSyncService) syncFile(run *markdownImport, repoPath string, commit string, file gitFile, postId string) (bool, error) {
+	if file.size > maxMarkdownFileSize {
+		return true, fmt.Errorf("larger than %d bytes", maxMarkdownFileSize)
+	}
+
+	content, err := git(repoPath, "cat-file", "blob", file.blobSha)
+	if err != nil {
+		return true, err
+	}
+
+	// The post may have been deleted since, the file then creates a new one
+	var existingPost *types.Post
+	if postId != "" {
+		if post, err := s.markdownService.postRepository.FindById(postId); err == nil {
+			existingPost = post
+		}
+	}
+
+	postId, err = run.importMarkdown(file.path, content, existingPost)
+	if errors.Is(err, errNoFrontMatter) {
+		return false, nil
+	}
+	if err != nil || postId == "" {
+		return true, err
+	}
+
+	return true, s.gitSyncRepository.Save(types.GitSyncedPost{
+		Path:      file.path,
+		PostId:    postId,
+		BlobSha:   file.blobSha,
+		CommitSha: commit,
+	})
+}
+
+// archive archives the post of a file which is gone and forgets the file, so that the
+// post is found by slug if the file comes back. It reports whether the post was
+// archived, rather than already archived or deleted.
+func (s *gitSyncService) archive(syncedPost types.GitSyncedPost) (bool, error) {
+	postRepository := s.markdownService.postRepository
+
+	archived := false
+	post, err := postRepository.FindById(syncedPost.PostId)
+	if err == nil && post.Status != types.PostStatusArchived {
+		post.Status = types.PostStatusArchived
+		if _, err := postRepository.Patch(post.Id, *post); err != nil {
+			return false, err
+		}
+		archived = true
+	}
+
+	return archived, s.gitSyncRepository.Delete(syncedPost.Path)
+}
+
+// gitMarkdownFiles lists the Markdown files of the commit, leaving out hidden files.
+func gitMarkdownFiles(repoPath string, commit string) ([]gitFile, error) {
+	output, err := git(repoPath, "ls-tree", "-r", "-l", "-z", "--full-tree", commit)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []gitFile
+	for _, entry := range strings.Split(string(output), "\x00") {
+		// Entries are "<mode> <type> <object> <size>\t<path>"
+		meta, filePath, found := strings.Cut(entry, "\t")
+		fields := strings.Fields(meta)
+		if !found || len(fields) != 4 || fields[1] != "blob" {
+			continue
+		}
+
+		name := path.Base(filePath)
+		if strings.HasPrefix(name, ".") || !strings.EqualFold(path.Ext(name), ".md") {
+			continue
+		}
+
+		size, _ := strconv.ParseInt(fields[3], 10, 64)
+		files = append(files, gitFile{path: filePath, blobSha: fields[2], size: size})
+	}
+
+	return files, nil
+}
+
+// git runs the git command in the repository and returns its output.
+func git(repoPath string, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", append([]string{"-C", repoPath}, args...)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], message)
+		}
+		return nil, fmt.Errorf("git %s: %v", args[0], err)
+	}
+
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,284 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	htmlParagraphBreak = regexp.MustCompile(`\s*\n\s*\n\s*`)
+	htmlSpace          = regexp.MustCompile(`\s+`)
+	markdownBlankLines = regexp.MustCompile(`\n{3,}`)
+	// WordPress wraps captioned images in [caption] shortcodes, the image and its text are kept
+	wordpressCaption = regexp.MustCompile(`\[/?caption[^\]]*\]`)
+)
+
+// htmlToMarkdown converts the HTML of a WordPress post to the Markdown posts are written
+// in. Like WordPress, text separated by a blank line makes separate paragraphs even
+// without <p> tags. Elements Markdown has no syntax for are reduced to their text.
+func htmlToMarkdown(source string) string {
+	source = wordpressCaption.ReplaceAllString(source, "")
+
+	context := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(source), context)
+	if err != nil {
+		return strings.TrimSpace(source)
+	}
+
+	var b strings.Builder
+	for _, node := range nodes {
+		writeMarkdown(&b, node)
+	}
+
+	return normalizeMarkdown(b.String())
+}
+
+func writeMarkdown(b *strings.Builder, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		writeText(b, n.Data)
+		return
+	case html.ElementNode:
+	default:
+		// Comments, such as the ones delimiting blocks of the block editor
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Script, atom.Style, atom.Noscript:
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		level, _ := strconv.Atoi(n.Data[1:])
+		writeBlock(b, strings.Repeat("#", level)+" "+oneLine(childrenMarkdown(n)))
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Figure:
+		writeBlock(b, childrenMarkdown(n))
+	case atom.Figcaption:
+		writeBlock(b, wrapInline(oneLine(childrenMarkdown(n)), "_"))
+	case atom.Blockquote:
+		writeBlock(b, prefixLines(childrenMarkdown(n), "> ", "> "))
+	case atom.Ul, atom.Ol:
+		writeBlock(b, listMarkdown(n))
+	case atom.Pre:
+		writeBlock(b, "```"+codeLanguage(n)+"\n"+strings.Trim(textContent(n), "\n")+"\n```")
+	case atom.Table:
+		writeBlock(b, tableMarkdown(n))
+	case atom.Hr:
+		writeBlock(b, "---")
+	case atom.Br:
+		b.WriteString("\n")
+	case atom.Strong, atom.B:
+		b.WriteString(wrapInline(childrenMarkdown(n), "**"))
+	case atom.Em, atom.I:
+		b.WriteString(wrapInline(childrenMarkdown(n), "_"))
+	case atom.Del, atom.S, atom.Strike:
+		b.WriteString(wrapInline(childrenMarkdown(n), "~~"))
+	case atom.Code:
+		b.WriteString(wrapInline(textContent(n), "`"))
+	case atom.A:
+		text := oneLine(childrenMarkdown(n))
+		href := attribute(n, "href")
+		switch {
+		case href == "":
+			b.WriteString(text)
+		case text == "":
+			b.WriteString(fmt.Sprintf("<%s>", href))
+		default:
+			b.WriteString(fmt.Sprintf("[%s](%s)", text, href))
+		}
+	case atom.Img:
+		if src := attribute(n, "src"); src != "" {
+			b.WriteString(fmt.Sprintf("![%s](%s)", oneLine(attribute(n, "alt")), src))
+		}
+	case atom.Iframe, atom.Video, atom.Audio:
+		// Embeds are kept as a link to what they show
+		if src := attribute(n, "src"); src != "" {
+			writeBlock(b, fmt.Sprintf("<%s>", src))
+		}
+	default:
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			writeMarkdown(b, child)
+		}
+	}
+}
+
+// writeText writes text collapsing whitespace as browsers do, except for blank lines
+// which WordPress turns into paragraphs.
+func writeText(b *strings.Builder, text string) {
+	paragraphs := htmlParagraphBreak.Split(text, -1)
+	for i, paragraph := range paragraphs {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		paragraph = htmlSpace.ReplaceAllString(paragraph, " ")
+		if atLineStart(b) {
+			paragraph = strings.TrimLeft(paragraph, " ")
+		}
+		b.WriteString(paragraph)
+	}
+}
+
+// writeBlock writes markdown as a block of its own, separated from what's around it by
+// blank lines.
+func writeBlock(b *strings.Builder, markdown string) {
+	markdown = normalizeMarkdown(markdown)
+	if markdown == "" {
+		return
+	}
+
+	b.WriteString("\n\n")
+	b.WriteString(markdown)
+	b.WriteString("\n\n")
+}
+
+func atLineStart(b *strings.Builder) bool {
+	return b.Len() == 0 || strings.HasSuffix(b.String(), "\n")
+}
+
+func childrenMarkdown(n *html.Node) string {
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		writeMarkdown(&b, child)
+	}
+	return b.String()
+}
+
+func listMarkdown(list *html.Node) string {
+	number := 1
+	if start, err := strconv.Atoi(attribute(list, "start")); err == nil {
+		number = start
+	}
+
+	var items []string
+	for item := list.FirstChild; item != nil; item = item.NextSibling {
+		if item.DataAtom != atom.Li {
+			continue
+		}
+
+		marker := "- "
+		if list.DataAtom == atom.Ol {
+			marker = strconv.Itoa(number) + ". "
+			number++
+		}
+		indent := strings.Repeat(" ", len(marker))
+		items = append(items, prefixLines(childrenMarkdown(item), marker, indent))
+	}
+
+	return strings.Join(items, "\n")
+}
+
+func tableMarkdown(table *html.Node) string {
+	var rows []string
+	var visit func(n *html.Node)
+	visit = func(n *html.Node) {
+		if n.DataAtom != atom.Tr {
+			for child := n.FirstChild; child != nil; child = child.NextSibling {
+				visit(child)
+			}
+			return
+		}
+
+		var cells []string
+		for cell := n.FirstChild; cell != nil; cell = cell.NextSibling {
+			if cell.DataAtom == atom.Td || cell.DataAtom == atom.Th {
+				cells = append(cells, strings.ReplaceAll(oneLine(childrenMarkdown(cell)), "|", `\|`))
+			}
+		}
+		if len(cells) == 0 {
+			return
+		}
+
+		rows = append(rows, "| "+strings.Join(cells, " | ")+" |")
+		// The first row is taken as the header
+		if len(rows) == 1 {
+			rows = append(rows, "|"+strings.Repeat(" --- |", len(cells)))
+		}
+	}
+	visit(table)
+
+	return strings.Join(rows, "\n")
+}
+
+// codeLanguage returns the language of a code block from the language-* class the block
+// editor and most highlighters put on it.
+func codeLanguage(pre *html.Node) string {
+	for n := pre; n != nil; n = n.FirstChild {
+		for _, class := range strings.Fields(attribute(n, "class")) {
+			if language, found := strings.CutPrefix(class, "language-"); found {
+				return language
+			}
+		}
+	}
+	return ""
+}
+
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.DataAtom == atom.Br {
+			b.WriteString("\n")
+			continue
+		}
+		b.WriteString(textContent(child))
+	}
+	return b.String()
+}
+
+func attribute(n *html.Node, name string) string {
+	for _, attr := range n.Attr {
+		if attr.Key == name {
+			return strings.TrimSpace(attr.Val)
+		}
+	}
+	return ""
+}
+
+// wrapInline surrounds text with an inline marker, keeping the marker inside the
+// whitespace around the text so the Markdown stays valid.
+func wrapInline(text, marker string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+
+	start := strings.Index(text, trimmed)
+	return text[:start] + marker + trimmed + marker + text[start+len(trimmed):]
+}
+
+func oneLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// prefixLines prefixes the first line of the markdown with first and the others with
+// rest, leaving blank lines blank.
+func prefixLines(markdown, first, rest string) string {
+	lines := strings.Split(normalizeMarkdown(markdown), "\n")
+	for i, line := range lines {
+		prefix := rest
+		if i == 0 {
+			prefix = first
+		}
+		if line == "" {
+			prefix = strings.TrimRight(prefix, " ")
+		}
+		lines[i] = prefix + line
+	}
+	return strings.Join(lines, "\n")
+}
+
+func normalizeMarkdown(markdown string) string {
+	lines := strings.Split(markdown, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+
+	markdown = markdownBlankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return strings.Trim(markdown, "\n")
+}
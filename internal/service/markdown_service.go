@@ -0,0 +1,623 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"go-blog/internal/repository"
+	"go-blog/internal/types"
+	"io"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// markdownPostsDir is where posts are in exports, as in the content directory of Hugo
+	markdownPostsDir    = "posts"
+	maxMarkdownFileSize = 1 << 20
+)
+
+var (
+	// Hugo names translations as post.fr.md
+	markdownLocaleSuffix = regexp.MustCompile(`\.([a-z]{2})$`)
+	// Jekyll names posts as 2024-01-31-post.md
+	markdownDatePrefix = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}-`)
+
+	frontMatterDateLayouts = []string{time.RFC3339, "2006-01-02 15:04:05 -0700", "2006-01-02 15:04:05 -07:00", "2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02"}
+)
+
+// ErrInvalidArchive is returned when the file to import isn't a zip archive.
+var ErrInvalidArchive = errors.New("invalid zip archive")
+
+var errNoFrontMatter = errors.New("no YAML front matter")
+
+type MarkdownService interface {
+	Export(w io.Writer) error
+	Import(archive io.ReaderAt, size int64, importer types.User) (*types.MarkdownImportReport, error)
+}
+
+type markdownService struct {
+	postRepository     repository.PostRepository
+	categoryRepository repository.CategoryRepository
+	userRepository     repository.UserRepository
+	slugService        SlugService
+}
+
+func NewMarkdownService(postRepository repository.PostRepository, categoryRepository repository.CategoryRepository, userRepository repository.UserRepository, slugService SlugService) MarkdownService {
+	return &markdownService{
+		postRepository:     postRepository,
+		categoryRepository: categoryRepository,
+		userRepository:     userRepository,
+		slugService:        slugService,
+	}
+}
+
+// frontMatter is the YAML front matter of a post file, in the format Hugo and Jekyll
+// read. The lastmod date is only written, as the blog keeps it itself.
+type frontMatter struct {
+	Title       string          `yaml:"title"`
+	Slug        string          `yaml:"slug"`
+	Date        frontMatterDate `yaml:"date"`
+	Lastmod     frontMatterDate `yaml:"lastmod,omitempty"`
+	Draft       bool            `yaml:"draft,omitempty"`
+	Author      string          `yaml:"author,omitempty"`
+	AuthorEmail string          `yaml:"author_email,omitempty"`
+	Description string          `yaml:"description,omitempty"`
+	Categories  stringList      `yaml:"categories,omitempty"`
+	Tags        stringList      `yaml:"tags,omitempty"`
+}
+
+// frontMatterDate reads the date formats of Hugo and Jekyll, which YAML doesn't all
+// take as timestamps.
+type frontMatterDate struct {
+	time.Time
+}
+
+func (d frontMatterDate) MarshalYAML() (interface{}, error) {
+	return d.Time.Truncate(time.Second), nil
+}
+
+func (d *frontMatterDate) UnmarshalYAML(value *yaml.Node) error {
+	for _, layout := range frontMatterDateLayouts {
+		if parsed, err := time.Parse(layout, value.Value); err == nil {
+			d.Time = parsed
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown date format %q", value.Value)
+}
+
+// stringList reads a YAML list, or a string of space separated words as Jekyll allows
+// for categories and tags.
+type stringList []string
+
+func (l *stringList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*l = strings.Fields(value.Value)
+		return nil
+	}
+
+	var list []string
+	if err := value.Decode(&list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// Export writes every post, drafts included, to a zip archive of Markdown files with
+// YAML front matter, one per post under the posts directory. Posts in another language
+// than the default one are named with a language suffix, as Hugo does, and so are the
+// translations of a post, after the slug of the post.
+func (s *markdownService) Export(w io.Writer) error {
+	posts, err := s.postRepository.FindByFilter(types.PostFilter{}, 0)
+	if err != nil {
+		return err
+	}
+
+	archive := zip.NewWriter(w)
+	for _, post := range posts {
+		markdown, err := postMarkdown(post)
+		if err != nil {
+			return fmt.Errorf("error exporting post %s: %v", post.Slug, err)
+		}
+		if err := writeMarkdownFile(archive, markdownFilename(post), post.UpdatedAt, markdown); err != nil {
+			return fmt.Errorf("error writing post %s: %v", post.Slug, err)
+		}
+
+		for _, t := range post.Translations {
+			translation, err := s.postRepository.FindPostTranslation(post.Id, t.Locale)
+			if err != nil {
+				return err
+			}
+
+			markdown, err := translationMarkdown(post, *translation)
+			if err != nil {
+				return fmt.Errorf("error exporting post %s in %s: %v", post.Slug, translation.Locale, err)
+			}
+			filename := path.Join(markdownPostsDir, post.Slug+"."+translation.Locale+".md")
+			if err := writeMarkdownFile(archive, filename, translation.UpdatedAt, markdown); err != nil {
+				return fmt.Errorf("error writing post %s in %s: %v", post.Slug, translation.Locale, err)
+			}
+		}
+	}
+
+	return archive.Close()
+}
+
+func writeMarkdownFile(archive *zip.Writer, name string, modified time.Time, markdown []byte) error {
+	file, err := archive.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: modified,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(markdown)
+	return err
+}
+
+func markdownFilename(post types.Post) string {
+	if post.Locale != "" && post.Locale != types.DefaultLocale() {
+		return path.Join(markdownPostsDir, post.Slug+"."+post.Locale+".md")
+	}
+	return path.Join(markdownPostsDir, post.Slug+".md")
+}
+
+// postMarkdown writes the post with its front matter. Generated excerpts are left out,
+// so that importing the file generates them again rather than keeping them as given.
+func postMarkdown(post types.Post) ([]byte, error) {
+	matter := frontMatter{
+		Title:       post.Title,
+		Slug:        post.Slug,
+		Date:        frontMatterDate{post.CreatedAt},
+		Lastmod:     frontMatterDate{post.UpdatedAt},
+		Draft:       !post.IsPublished(),
+		Author:      strings.TrimSpace(post.Author.Name + " " + post.Author.Lastname),
+		AuthorEmail: post.Author.Email,
+	}
+	if !post.ExcerptGenerated {
+		matter.Description = post.Excerpt
+	}
+	for _, category := range post.Categories {
+		matter.Categories = append(matter.Categories, category.Title)
+	}
+	for _, tag := range post.Tags {
+		matter.Tags = append(matter.Tags, tag.Name)
+	}
+
+	return markdownFile(matter, post.Content)
+}
+
+// translationMarkdown writes the translation of the post with the front matter of the
+// post, in the language of the translation.
+func translationMarkdown(post types.Post, translation types.PostTranslation) ([]byte, error) {
+	matter := frontMatter{
+		Title:       translation.Title,
+		Slug:        translation.Slug,
+		Date:        frontMatterDate{post.CreatedAt},
+		Lastmod:     frontMatterDate{translation.UpdatedAt},
+		Draft:       !post.IsPublished(),
+		Author:      strings.TrimSpace(post.Author.Name + " " + post.Author.Lastname),
+		AuthorEmail: post.Author.Email,
+	}
+	if !excerptGenerated(translation) {
+		matter.Description = translation.Excerpt
+	}
+
+	return markdownFile(matter, translation.Content)
+}
+
+func markdownFile(matter frontMatter, content string) ([]byte, error) {
+	encoded, err := yaml.Marshal(matter)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	b.WriteString("---\n")
+	b.Write(encoded)
+	b.WriteString("---\n\n")
+	b.WriteString(strings.TrimSpace(content))
+	b.WriteString("\n")
+	return b.Bytes(), nil
+}
+
+// excerptGenerated reports whether the excerpt of the translation is the one generated
+// from its content, as translations don't remember it.
+func excerptGenerated(translation types.PostTranslation) bool {
+	generated := types.PostTranslation{Content: translation.Content}
+	generated.Summarize()
+	return translation.Excerpt == generated.Excerpt
+}
+
+// markdownImport holds what one run of the import has looked up so far.
+type markdownImport struct {
+	*markdownService
+	importer   *types.User
+	report     types.MarkdownImportReport
+	categories []types.Category
+}
+
+// Import reads the Markdown files of a zip archive in the format of Export, wherever
+// they are in the archive. A post with the slug of a file, in the language of its
+// suffix, is updated with the title, content, description, draft flag, categories and
+// tags of the file, other files create posts. A file named after the slug of a post in
+// another language than its suffix is the translation of that post instead, and only
+// sets its title, slug, content and description. New posts are credited to the user
+// with the author_email of the file, or else to importer. Categories are matched by
+// title and created when missing.
+func (s *markdownService) Import(archive io.ReaderAt, size int64, importer types.User) (*types.MarkdownImportReport, error) {
+	reader, err := zip.NewReader(archive, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+
+	categories, err := s.categoryRepository.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	run := &markdownImport{
+		markdownService: s,
+		importer:        &importer,
+		report:          types.MarkdownImportReport{Errors: []string{}},
+		categories:      categories,
+	}
+
+	// Translations are imported once the post they translate exists
+	files := slices.Clone(reader.File)
+	slices.SortStableFunc(files, func(a, b *zip.File) int {
+		return compareLocaleSuffix(a.Name, b.Name)
+	})
+
+	for _, file := range files {
+		// Skips directories and the resource forks macOS adds to archives
+		name := path.Base(file.Name)
+		if file.FileInfo().IsDir() || strings.HasPrefix(name, ".") || !strings.EqualFold(path.Ext(name), ".md") {
+			continue
+		}
+
+		if err := run.importFile(file); err != nil {
+			run.report.Errors = append(run.report.Errors, fmt.Sprintf("%s: %v", file.Name, err))
+		}
+	}
+
+	return &run.report, nil
+}
+
+func (run *markdownImport) importFile(file *zip.File) error {
+	content, err := readZipFile(file)
+	if err != nil {
+		return err
+	}
+
+	_, err = run.importMarkdown(file.Name, content, nil)
+	return err
+}
+
+// markdownName splits the name of a Markdown file into its name without extension and
+// the language of its suffix, if any.
+func markdownName(filename string) (string, string) {
+	name := strings.TrimSuffix(path.Base(filename), path.Ext(filename))
+	if match := markdownLocaleSuffix.FindStringSubmatch(name); match != nil {
+		return strings.TrimSuffix(name, match[0]), match[1]
+	}
+	return name, ""
+}
+
+// compareLocaleSuffix orders the files without a language suffix first.
+func compareLocaleSuffix(a, b string) int {
+	_, localeA := markdownName(a)
+	_, localeB := markdownName(b)
+	switch {
+	case localeA == "" && localeB != "":
+		return -1
+	case localeA != "" && localeB == "":
+		return 1
+	}
+	return 0
+}
+
+// importMarkdown creates or updates the post of the Markdown file with the given name,
+// and returns its id. Without existingPost, the post to update is found by slug. The
+// translation of a post is saved as such, and leaves the id empty.
+func (run *markdownImport) importMarkdown(filename string, content []byte, existingPost *types.Post) (string, error) {
+	matter, body, err := parseFrontMatter(content)
+	if err != nil {
+		return "", err
+	}
+
+	name, locale := markdownName(filename)
+	if locale != "" && existingPost == nil {
+		// The file translates the post it is named after when that post is in another language
+		if post, err := run.postRepository.FindBySlug(name, locale); err == nil && post.Slug == name && post.Locale != locale {
+			return "", run.importTranslation(post, locale, matter, body)
+		}
+	}
+	if locale == "" {
+		locale = types.DefaultLocale()
+	}
+
+	slug := matter.Slug
+	if slug == "" {
+		slug = markdownDatePrefix.ReplaceAllString(name, "")
+	}
+	slug = run.slugService.Generate(slug, "")
+
+	status := types.PostStatusPublished
+	if matter.Draft {
+		status = types.PostStatusDraft
+	}
+
+	categoryIds, err := run.categoryIds(matter.Categories)
+	if err != nil {
+		return "", err
+	}
+	tags := run.tags(matter.Tags)
+
+	if existingPost == nil {
+		// FindBySlug also finds posts by the slug of a translation, which aren't the post
+		// of the file when in another language
+		if post, err := run.postRepository.FindBySlug(slug, locale); err == nil && post.Locale == locale {
+			existingPost = post
+		}
+	}
+	if existingPost != nil {
+		return existingPost.Id, run.update(existingPost, matter, body, status, categoryIds, tags)
+	}
+
+	post := types.Post{
+		Title:     matter.Title,
+		Slug:      slug,
+		Locale:    locale,
+		Status:    status,
+		Content:   body,
+		Excerpt:   matter.Description,
+		CreatedAt: matter.Date.Time,
+	}
+	if run.importer != nil {
+		post.Author = *run.importer
+	}
+	if matter.AuthorEmail != "" {
+		if author, err := run.userRepository.FindByEmail(matter.AuthorEmail); err == nil {
+			post.Author = *author
+		}
+	}
+	if post.Author.Id == "" {
+		return "", fmt.Errorf("unknown author %q", matter.AuthorEmail)
+	}
+	post.Summarize()
+
+	if fails := post.Validate(); fails != nil {
+		return "", fmt.Errorf("invalid %v", fails)
+	}
+
+	createdPost, err := run.postRepository.Create(post)
+	if err != nil {
+		return "", err
+	}
+	run.report.Created++
+
+	if len(categoryIds) > 0 {
+		if err := run.postRepository.UpdatePostCategories(createdPost.Id, categoryIds); err != nil {
+			return createdPost.Id, err
+		}
+	}
+	if len(tags) > 0 {
+		if _, err := run.postRepository.UpdatePostTags(createdPost.Id, tags); err != nil {
+			return createdPost.Id, err
+		}
+	}
+
+	return createdPost.Id, nil
+}
+
+// update saves what the file changes in the post, which keeps its slug, language, owner
+// and publish date.
+func (run *markdownImport) update(existingPost *types.Post, matter frontMatter, body string, status string, categoryIds []string, tags []types.Tag) error {
+	post := *existingPost
+	post.Title = matter.Title
+	post.Content = body
+	post.Excerpt = matter.Description
+	// Exports written before generated excerpts were left out still have them
+	if existingPost.ExcerptGenerated && strings.TrimSpace(post.Excerpt) == existingPost.Excerpt {
+		post.Excerpt = ""
+	}
+	// Drafts in the file leave posts under review where they are in the workflow
+	if status != types.PostStatusDraft || !existingPost.IsInReview() {
+		post.Status = status
+	}
+	post.Summarize()
+
+	if fails := post.Validate(); fails != nil {
+		return fmt.Errorf("invalid %v", fails)
+	}
+
+	patchedPost, err := run.postRepository.Patch(existingPost.Id, post)
+	if err != nil {
+		return err
+	}
+	changed := patchedPost.Version != existingPost.Version
+
+	var existingCategoryIds []string
+	for _, category := range existingPost.Categories {
+		existingCategoryIds = append(existingCategoryIds, category.Id)
+	}
+	if !sameSet(categoryIds, existingCategoryIds) {
+		if err := run.postRepository.UpdatePostCategories(existingPost.Id, categoryIds); err != nil {
+			return err
+		}
+		changed = true
+	}
+
+	var tagSlugs, existingTagSlugs []string
+	for _, tag := range tags {
+		tagSlugs = append(tagSlugs, tag.Slug)
+	}
+	for _, tag := range existingPost.Tags {
+		existingTagSlugs = append(existingTagSlugs, tag.Slug)
+	}
+	if !sameSet(tagSlugs, existingTagSlugs) {
+		if _, err := run.postRepository.UpdatePostTags(existingPost.Id, tags); err != nil {
+			return err
+		}
+		changed = true
+	}
+
+	if changed {
+		run.report.Updated++
+	} else {
+		run.report.Unchanged++
+	}
+	return nil
+}
+
+// importTranslation creates or updates the translation of the post in the language of
+// the file.
+func (run *markdownImport) importTranslation(post *types.Post, locale string, matter frontMatter, body string) error {
+	slug := matter.Slug
+	if slug == "" {
+		slug = matter.Title
+	}
+
+	translation := types.PostTranslation{
+		Locale:  locale,
+		Title:   matter.Title,
+		Slug:    run.slugService.Generate(slug, ""),
+		Content: body,
+		Excerpt: matter.Description,
+	}
+
+	var existingTranslation *types.PostTranslation
+	if found, err := run.postRepository.FindPostTranslation(post.Id, locale); err == nil {
+		existingTranslation = found
+	}
+	if existingTranslation != nil && excerptGenerated(*existingTranslation) && strings.TrimSpace(translation.Excerpt) == existingTranslation.Excerpt {
+		translation.Excerpt = ""
+	}
+	translation.Summarize()
+
+	if fails := translation.Validate(); fails != nil {
+		return fmt.Errorf("invalid %v", fails)
+	}
+
+	if existingTranslation != nil && translation.Title == existingTranslation.Title && translation.Slug == existingTranslation.Slug &&
+		translation.Content == existingTranslation.Content && translation.Excerpt == existingTranslation.Excerpt {
+		run.report.Unchanged++
+		return nil
+	}
+
+	if _, err := run.postRepository.SavePostTranslation(post.Id, translation); err != nil {
+		return err
+	}
+	if existingTranslation != nil {
+		run.report.Updated++
+	} else {
+		run.report.Created++
+	}
+	return nil
+}
+
+// categoryIds returns the ids of the categories with the given titles, creating the
+// ones that don't exist yet.
+func (run *markdownImport) categoryIds(titles []string) ([]string, error) {
+	var ids []string
+	for _, title := range titles {
+		title = strings.TrimSpace(title)
+		slug := run.slugService.Generate(title, "")
+
+		index := slices.IndexFunc(run.categories, func(category types.Category) bool {
+			return strings.EqualFold(category.Title, title) || category.Slug == slug
+		})
+		if index < 0 {
+			category := types.Category{Title: title, Slug: slug}
+			if fails := category.Validate(); fails != nil {
+				return nil, fmt.Errorf("invalid category %s: %v", title, fails)
+			}
+
+			createdCategory, err := run.categoryRepository.Create(category)
+			if err != nil {
+				return nil, err
+			}
+			run.categories = append(run.categories, *createdCategory)
+			index = len(run.categories) - 1
+		}
+
+		if id := run.categories[index].Id; !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
+
+func (run *markdownImport) tags(names []string) []types.Tag {
+	var tags []types.Tag
+	for _, name := range names {
+		tag := types.Tag{Name: strings.TrimSpace(name)}
+		tag.Slug = run.slugService.Generate(tag.Name, "")
+		if tag.Validate() != nil || slices.ContainsFunc(tags, func(t types.Tag) bool { return t.Slug == tag.Slug }) {
+			continue
+		}
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+func readZipFile(file *zip.File) ([]byte, error) {
+	src, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	content, err := io.ReadAll(io.LimitReader(src, maxMarkdownFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(content) > maxMarkdownFileSize {
+		return nil, fmt.Errorf("larger than %d bytes", maxMarkdownFileSize)
+	}
+
+	return content, nil
+}
+
+// parseFrontMatter splits a Markdown file into its YAML front matter, between --- lines
+// at the top, and the Markdown after it.
+func parseFrontMatter(content []byte) (frontMatter, string, error) {
+	var matter frontMatter
+
+	text := strings.TrimPrefix(string(content), "\ufeff")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	lines := strings.SplitAfter(text, "\n")
+	if strings.TrimSpace(lines[0]) != "---" {
+		return matter, "", errNoFrontMatter
+	}
+
+	for i := 1; i < len(lines); i++ {
+		if line := strings.TrimSpace(lines[i]); line == "---" || line == "..." {
+			if err := yaml.Unmarshal([]byte(strings.Join(lines[1:i], "")), &matter); err != nil {
+				return matter, "", fmt.Errorf("invalid front matter: %v", err)
+			}
+			return matter, strings.TrimSpace(strings.Join(lines[i+1:], "")), nil
+		}
+	}
+
+	return matter, "", errors.New("the front matter isn't closed")
+}
+
+func sameSet(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"go-blog/internal/types"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	defaultPostUnlockTTL      = 24 * time.Hour
+	defaultPostUnlockAttempts = 10
+	defaultPostUnlockWindow   = 15 * time.Minute
+)
+
+// ErrWrongPassword is returned when unlocking a post with another password than its own.
+var ErrWrongPassword = errors.New("wrong password")
+
+// ErrTooManyAttempts is returned when unlocking a post after too many wrong passwords.
+var ErrTooManyAttempts = errors.New("too many attempts")
+
+type PostUnlockService interface {
+	Unlock(post types.Post, password string) (*fiber.Cookie, error)
+	IsUnlocked(c *fiber.Ctx, post types.Post) bool
+}
+
+type postUnlockService struct {
+	secret      []byte
+	ttl         time.Duration
+	maxFailures int
+	window      time.Duration
+
+	mu       sync.Mutex
+	failures map[string]unlockFailures
+}
+
+// unlockFailures counts the wrong passwords given for a post since the window started.
+type unlockFailures struct {
+	count int
+	since time.Time
+}
+
+// NewPostUnlockService signs the cookies unlocking password protected posts with
+// JWT_SECRET. They last POST_UNLOCK_TTL. Each post takes ten times POST_UNLOCK_ATTEMPTS
+// wrong passwords per POST_UNLOCK_WINDOW, from all IPs together.
+func NewPostUnlockService() PostUnlockService {
+	ttl, err := time.ParseDuration(os.Getenv("POST_UNLOCK_TTL"))
+	if err != nil || ttl <= 0 {
+		ttl = defaultPostUnlockTTL
+	}
+	attempts, err := strconv.Atoi(os.Getenv("POST_UNLOCK_ATTEMPTS"))
+	if err != nil || attempts <= 0 {
+		attempts = defaultPostUnlockAttempts
+	}
+	window, err := time.ParseDuration(os.Getenv("POST_UNLOCK_WINDOW"))
+	if err != nil || window <= 0 {
+		window = defaultPostUnlockWindow
+	}
+
+	return &postUnlockService{
+		secret:      []byte(os.Getenv("JWT_SECRET")),
+		ttl:         ttl,
+		maxFailures: 10 * attempts,
+		window:      window,
+		failures:    make(map[string]unlockFailures),
+	}
+}
+
+func postUnlockCookieName(postId string) string {
+	return "post_unlock_" + postId
+}
+
+// Unlock checks the password of the post and returns the cookie unlocking it. The
+// cookie only unlocks that post, and only until its password changes. Once the post took
+// too many wrong passwords, it is not compared again until the window ends.
+func (s *postUnlockService) Unlock(post types.Post, password string) (*fiber.Cookie, error) {
+	if s.throttled(post.Id) {
+		return nil, ErrTooManyAttempts
+	}
+	if post.PasswordHash == "" || bcrypt.CompareHashAndPassword([]byte(post.PasswordHash), []byte(password)) != nil {
+		s.fail(post.Id)
+		return nil, ErrWrongPassword
+	}
+
+	expires := time.Now().Add(s.ttl)
+	return &fiber.Cookie{
+		Name:     postUnlockCookieName(post.Id),
+		Value:    s.token(post, expires.Unix()),
+		Path:     "/api/posts",
+		Expires:  expires,
+		HTTPOnly: true,
+		Secure:   true,
+		SameSite: "Lax",
+	}, nil
+}
+
+// throttled reports whether the post took its share of wrong passwords in the current window.
+func (s *postUnlockService) throttled(postId string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	failures, found := s.failures[postId]
+	return found && time.Since(failures.since) < s.window && failures.count >= s.maxFailures
+}
+
+// fail counts a wrong password for the post, forgetting the posts whose window ended.
+func (s *postUnlockService) fail(postId string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for id, failures := range s.failures {
+		if now.Sub(failures.since) >= s.window {
+			delete(s.failures, id)
+		}
+	}
+
+	failures, found := s.failures[postId]
+	if !found {
+		failures.since = now
+	}
+	failures.count++
+	s.failures[postId] = failures
+}
+
+// IsUnlocked reports whether the request carries an unexpired cookie unlocking the post.
+func (s *postUnlockService) IsUnlocked(c *fiber.Ctx, post types.Post) bool {
+	if post.PasswordHash == "" {
+		return false
+	}
+
+	value := c.Cookies(postUnlockCookieName(post.Id))
+	expiresText, _, found := strings.Cut(value, ".")
+	expires, err := strconv.ParseInt(expiresText, 10, 64)
+	if !found || err != nil || time.Now().Unix() > expires {
+		return false
+	}
+
+	return hmac.Equal([]byte(value), []byte(s.token(post, expires)))
+}
+
+// token signs the post, its password hash and the expiry, which the token starts with.
+func (s *postUnlockService) token(post types.Post, expires int64) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(strings.Join([]string{post.Id, post.PasswordHash, strconv.FormatInt(expires, 10)}, "|")))
+	return strconv.FormatInt(expires, 10) + "." + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"go-blog/internal/repository"
+	"go-blog/internal/types"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPreviewLinkTTL = 7 * 24 * time.Hour
+	maxPreviewLinkTTL     = 30 * 24 * time.Hour
+)
+
+// ErrInvalidPreviewToken is returned for tokens the service didn't sign, and for the
+// ones of links which expired or were revoked.
+var ErrInvalidPreviewToken = errors.New("invalid or expired preview token")
+
+type PreviewLinkService interface {
+	Create(postId string, createdBy string, ttl time.Duration) (*types.PreviewLink, error)
+	FindActiveByPost(postId string) ([]types.PreviewLink, error)
+	Revoke(postId string, id string) error
+	Resolve(token string) (*types.PreviewLink, error)
+}
+
+type previewLinkService struct {
+	previewLinkRepository repository.PreviewLinkRepository
+	secret                []byte
+	ttl                   time.Duration
+}
+
+// NewPreviewLinkService signs the tokens of preview links with JWT_SECRET. Links last
+// PREVIEW_LINK_TTL unless created for another duration.
+func NewPreviewLinkService(previewLinkRepository repository.PreviewLinkRepository) PreviewLinkService {
+	ttl, err := time.ParseDuration(os.Getenv("PREVIEW_LINK_TTL"))
+	if err != nil || ttl <= 0 {
+		ttl = defaultPreviewLinkTTL
+	}
+
+	return &previewLinkService{
+		previewLinkRepository: previewLinkRepository,
+		secret:                []byte(os.Getenv("JWT_SECRET")),
+		ttl:                   ttl,
+	}
+}
+
+// Create creates a link to the post lasting ttl, or the default duration when ttl is 0.
+// Links last 30 days at most.
+func (s *previewLinkService) Create(postId string, createdBy string, ttl time.Duration) (*types.PreviewLink, error) {
+	if ttl <= 0 {
+		ttl = s.ttl
+	}
+	ttl = min(ttl, maxPreviewLinkTTL)
+
+	link, err := s.previewLinkRepository.Create(types.PreviewLink{
+		PostId:    postId,
+		CreatedBy: createdBy,
+		ExpiresAt: time.Now().Add(ttl).Truncate(time.Second),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	link.Token = s.token(*link)
+	return link, nil
+}
+
+// FindActiveByPost returns the links to the post which haven't expired, with their token.
+func (s *previewLinkService) FindActiveByPost(postId string) ([]types.PreviewLink, error) {
+	links, err := s.previewLinkRepository.FindActiveByPost(postId)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range links {
+		links[i].Token = s.token(links[i])
+	}
+	return links, nil
+}
+
+// Revoke deletes the link, after which its token no longer resolves.
+func (s *previewLinkService) Revoke(postId string, id string) error {
+	return s.previewLinkRepository.Delete(postId, id)
+}
+
+// Resolve returns the link of the token, as long as it is signed by the service and the
+// link hasn't expired or been revoked.
+func (s *previewLinkService) Resolve(token string) (*types.PreviewLink, error) {
+	id, _, found := strings.Cut(token, ".")
+	if !found {
+		return nil, ErrInvalidPreviewToken
+	}
+
+	link, err := s.previewLinkRepository.FindById(id)
+	if err != nil || !hmac.Equal([]byte(token), []byte(s.token(*link))) || time.Now().After(link.ExpiresAt) {
+		return nil, ErrInvalidPreviewToken
+	}
+
+	return link, nil
+}
+
+// token signs the link and its expiry, the token starts with the id of the link.
+func (s *previewLinkService) token(link types.PreviewLink) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(strings.Join([]string{link.Id, link.PostId, strconv.FormatInt(link.ExpiresAt.Unix(), 10)}, "|")))
+	return link.Id + "." + hex.EncodeToString(mac.Sum(nil))
+}
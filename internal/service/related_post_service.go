@@ -0,0 +1,86 @@
+package service
+
+import (
+	"go-blog/internal/repository"
+	"go-blog/internal/types"
+	"os"
+	"sync"
+	"time"
+)
+
+const defaultRelatedPostsCacheTTL = 10 * time.Minute
+
+type RelatedPostService interface {
+	FindRelated(postId string, limit int) ([]types.Post, error)
+	Invalidate()
+}
+
+type relatedPosts struct {
+	posts     []types.Post
+	expiresAt time.Time
+}
+
+type relatedPostKey struct {
+	postId string
+	limit  int
+}
+
+type relatedPostService struct {
+	postRepository repository.PostRepository
+	ttl            time.Duration
+	mu             sync.RWMutex
+	cache          map[relatedPostKey]relatedPosts
+	generation     int
+}
+
+// NewRelatedPostService caches the related posts of each post for RELATED_POSTS_CACHE_TTL,
+// or until the cache is invalidated.
+func NewRelatedPostService(postRepository repository.PostRepository) RelatedPostService {
+	ttl, err := time.ParseDuration(os.Getenv("RELATED_POSTS_CACHE_TTL"))
+	if err != nil || ttl <= 0 {
+		ttl = defaultRelatedPostsCacheTTL
+	}
+
+	return &relatedPostService{
+		postRepository: postRepository,
+		ttl:            ttl,
+		cache:          make(map[relatedPostKey]relatedPosts),
+	}
+}
+
+// FindRelated returns the related posts of the post. The returned slice is a copy, so
+// callers may change the posts without touching the cache.
+func (s *relatedPostService) FindRelated(postId string, limit int) ([]types.Post, error) {
+	key := relatedPostKey{postId, limit}
+
+	s.mu.RLock()
+	cached, ok := s.cache[key]
+	generation := s.generation
+	s.mu.RUnlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return append([]types.Post{}, cached.posts...), nil
+	}
+
+	posts, err := s.postRepository.FindRelated(postId, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Posts found before an invalidation may already be stale, so they aren't cached
+	s.mu.Lock()
+	if s.generation == generation {
+		s.cache[key] = relatedPosts{posts: posts, expiresAt: time.Now().Add(s.ttl)}
+	}
+	s.mu.Unlock()
+
+	return append([]types.Post{}, posts...), nil
+}
+
+// Invalidate drops every cached entry. A change to one post can change the related posts
+// of any other, so the whole cache goes rather than the entries of the changed post.
+func (s *relatedPostService) Invalidate() {
+	s.mu.Lock()
+	s.cache = make(map[relatedPostKey]relatedPosts)
+	s.generation++
+	s.mu.Unlock()
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const MaxSlugLength = 80
+
+// ReservedSlugs can't be used as slugs since they collide with routes or would be confusing.
+var ReservedSlugs = map[string]bool{
+	"admin": true, "api": true, "categories": true, "comments": true, "edit": true,
+	"feed": true, "jsonld": true, "login": true, "logout": true, "new": true,
+	"posts": true, "reactions": true, "rss": true, "search": true, "sitemap": true,
+	"stats": true, "tags": true, "users": true,
+}
+
+var (
+	customSlugRegexp = regexp.MustCompile("^[a-z0-9]+(-[a-z0-9]+)*$")
+	nonSlugRegexp    = regexp.MustCompile("[^a-z0-9]+")
+	turkishLetters   = regexp.MustCompile("[ıİşŞğĞ]")
+	germanLetters    = regexp.MustCompile("[ßẞ]")
+)
+
+// Language specific transliterations, applied before diacritics are stripped.
+var transliterations = map[string]*strings.Replacer{
+	"tr": strings.NewReplacer(
+		"İ", "i", "I", "i", "ı", "i", "Ş", "s", "ş", "s", "Ğ", "g", "ğ", "g",
+		"Ç", "c", "ç", "c", "Ö", "o", "ö", "o", "Ü", "u", "ü", "u",
+	),
+	"de": strings.NewReplacer(
+		"Ä", "ae", "ä", "ae", "Ö", "oe", "ö", "oe", "Ü", "ue", "ü", "ue", "ß", "ss", "ẞ", "ss",
+	),
+}
+
+// Letters which don't decompose into a base letter and a diacritic.
+var specialLetters = strings.NewReplacer(
+	"ß", "ss", "ẞ", "ss", "Æ", "ae", "æ", "ae", "Ø", "o", "ø", "o", "Œ", "oe", "œ", "oe",
+	"Ł", "l", "ł", "l", "Đ", "d", "đ", "d", "Þ", "th", "þ", "th", "ı", "i",
+)
+
+var cyrillic = strings.NewReplacer(
+	"А", "a", "а", "a", "Б", "b", "б", "b", "В", "v", "в", "v", "Г", "g", "г", "g",
+	"Ґ", "g", "ґ", "g", "Д", "d", "д", "d", "Е", "e", "е", "e", "Ё", "yo", "ё", "yo",
+	"Є", "ye", "є", "ye", "Ж", "zh", "ж", "zh", "З", "z", "з", "z", "И", "i", "и", "i",
+	"І", "i", "і", "i", "Ї", "yi", "ї", "yi", "Й", "y", "й", "y", "К", "k", "к", "k",
+	"Л", "l", "л", "l", "М", "m", "м", "m", "Н", "n", "н", "n", "О", "o", "о", "o",
+	"П", "p", "п", "p", "Р", "r", "р", "r", "С", "s", "с", "s", "Т", "t", "т", "t",
+	"У", "u", "у", "u", "Ф", "f", "ф", "f", "Х", "kh", "х", "kh", "Ц", "ts", "ц", "ts",
+	"Ч", "ch", "ч", "ch", "Ш", "sh", "ш", "sh", "Щ", "shch", "щ", "shch", "Ъ", "", "ъ", "",
+	"Ы", "y", "ы", "y", "Ь", "", "ь", "", "Э", "e", "э", "e", "Ю", "yu", "ю", "yu",
+	"Я", "ya", "я", "ya",
+)
+
+type SlugService interface {
+	Generate(text string, language string) string
+	ValidateCustom(slug string) error
+}
+
+type slugService struct {
+	defaultLanguage string
+}
+
+// NewSlugService creates the slug service. SLUG_LANGUAGE sets the transliteration
+// used when the language of a text is unknown and can't be guessed.
+func NewSlugService() SlugService {
+	return &slugService{defaultLanguage: strings.ToLower(os.Getenv("SLUG_LANGUAGE"))}
+}
+
+// Generate turns text into a slug, transliterating it according to language or, when
+// empty, to the language guessed from the text. Texts without any transliterable
+// letter get a slug derived from their hash rather than an empty one.
+func (s *slugService) Generate(text string, language string) string {
+	if language == "" {
+		language = s.guessLanguage(text)
+	}
+
+	if replacer, ok := transliterations[language]; ok {
+		text = replacer.Replace(text)
+	}
+	text = cyrillic.Replace(specialLetters.Replace(text))
+	text = stripDiacritics(text)
+
+	slug := strings.Trim(nonSlugRegexp.ReplaceAllString(strings.ToLower(text), "-"), "-")
+	slug = truncateSlug(slug, MaxSlugLength)
+
+	if slug == "" {
+		sum := sha256.Sum256([]byte(strings.TrimSpace(text)))
+		return "n-" + hex.EncodeToString(sum[:4])
+	}
+
+	if ReservedSlugs[slug] {
+		return slug + "-1"
+	}
+
+	return slug
+}
+
+// ValidateCustom checks a slug chosen by an author.
+func (s *slugService) ValidateCustom(slug string) error {
+	if len(slug) > MaxSlugLength {
+		return fmt.Errorf("slug must be at most %d characters long", MaxSlugLength)
+	}
+
+	if !customSlugRegexp.MatchString(slug) {
+		return fmt.Errorf("slug may only contain lowercase letters, digits and single hyphens between them")
+	}
+
+	if ReservedSlugs[slug] {
+		return fmt.Errorf("slug %s is reserved", slug)
+	}
+
+	return nil
+}
+
+func (s *slugService) guessLanguage(text string) string {
+	switch {
+	case turkishLetters.MatchString(text):
+		return "tr"
+	case germanLetters.MatchString(text):
+		return "de"
+	default:
+		return s.defaultLanguage
+	}
+}
+
+func stripDiacritics(text string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(text) {
+		if !unicode.Is(unicode.Mn, r) {
+			b.WriteRune(r)
+		}
+	}
+	return norm.NFC.String(b.String())
+}
+
+// truncateSlug shortens slug to max characters, cutting at a hyphen when possible.
+func truncateSlug(slug string, max int) string {
+	if len(slug) <= max {
+		return slug
+	}
+
+	slug = slug[:max]
+	if i := strings.LastIndex(slug, "-"); i > 0 {
+		slug = slug[:i]
+	}
+
+	return strings.Trim(slug, "-")
+}
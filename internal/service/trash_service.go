@@ -0,0 +1,102 @@
+package service
+
+import (
+	"go-blog/internal/repository"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	defaultTrashRetention     = 30 * 24 * time.Hour
+	defaultTrashPurgeInterval = time.Hour
+)
+
+type TrashService interface {
+	Purge() error
+	Stop() error
+}
+
+type trashService struct {
+	postRepository     repository.PostRepository
+	categoryRepository repository.CategoryRepository
+	retention          time.Duration
+	stop               chan struct{}
+	done               chan struct{}
+	once               sync.Once
+}
+
+// NewTrashService starts a background job which, every TRASH_PURGE_INTERVAL, permanently
+// deletes the posts and categories which have been in the trash for longer than
+// TRASH_RETENTION.
+func NewTrashService(postRepository repository.PostRepository, categoryRepository repository.CategoryRepository) TrashService {
+	retention, err := time.ParseDuration(os.Getenv("TRASH_RETENTION"))
+	if err != nil || retention <= 0 {
+		retention = defaultTrashRetention
+	}
+
+	purgeInterval, err := time.ParseDuration(os.Getenv("TRASH_PURGE_INTERVAL"))
+	if err != nil || purgeInterval <= 0 {
+		purgeInterval = defaultTrashPurgeInterval
+	}
+
+	s := &trashService{
+		postRepository:     postRepository,
+		categoryRepository: categoryRepository,
+		retention:          retention,
+		stop:               make(chan struct{}),
+		done:               make(chan struct{}),
+	}
+
+	go s.run(purgeInterval)
+
+	return s
+}
+
+// Purge permanently deletes the trash older than the retention.
+func (s *trashService) Purge() error {
+	before := time.Now().Add(-s.retention)
+
+	posts, err := s.postRepository.PurgeDeletedBefore(before)
+	if err != nil {
+		return err
+	}
+
+	categories, err := s.categoryRepository.PurgeDeletedBefore(before)
+	if err != nil {
+		return err
+	}
+
+	if posts > 0 || categories > 0 {
+		log.Printf("purged %d post(s) and %d category(ies) from the trash", posts, categories)
+	}
+
+	return nil
+}
+
+// Stop stops the background job, waiting for a running purge to finish.
+func (s *trashService) Stop() error {
+	s.once.Do(func() { close(s.stop) })
+
+	<-s.done
+	return nil
+}
+
+func (s *trashService) run(purgeInterval time.Duration) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(purgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			if err := s.Purge(); err != nil {
+				log.Printf("error purging trash: %v", err)
+			}
+		}
+	}
+}
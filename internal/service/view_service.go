@@ -0,0 +1,175 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"go-blog/internal/repository"
+	"go-blog/internal/types"
+	"log"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultViewBatchSize     = 100
+	defaultViewFlushInterval = 10 * time.Second
+)
+
+var botMarkers = []string{"bot", "crawler", "spider", "slurp", "curl", "wget"}
+
+type ViewService interface {
+	Record(postId, ip, userAgent, referrer string)
+	Stop() error
+}
+
+type viewService struct {
+	viewRepository repository.ViewRepository
+	secret         []byte
+	batchSize      int
+	views          chan types.PostView
+	done           chan struct{}
+	mu             sync.RWMutex
+	stopped        bool
+}
+
+// NewViewService starts a background worker writing recorded views in batches,
+// either once VIEW_BATCH_SIZE views are buffered or every VIEW_FLUSH_INTERVAL.
+func NewViewService(viewRepository repository.ViewRepository) ViewService {
+	batchSize, err := strconv.Atoi(os.Getenv("VIEW_BATCH_SIZE"))
+	if err != nil || batchSize < 1 {
+		batchSize = defaultViewBatchSize
+	}
+
+	flushInterval, err := time.ParseDuration(os.Getenv("VIEW_FLUSH_INTERVAL"))
+	if err != nil || flushInterval <= 0 {
+		flushInterval = defaultViewFlushInterval
+	}
+
+	secret := os.Getenv("VIEW_HASH_SECRET")
+	if secret == "" {
+		secret = os.Getenv("JWT_SECRET")
+	}
+
+	s := &viewService{
+		viewRepository: viewRepository,
+		secret:         []byte(secret),
+		batchSize:      batchSize,
+		views:          make(chan types.PostView, batchSize*10),
+		done:           make(chan struct{}),
+	}
+
+	go s.run(flushInterval)
+
+	return s
+}
+
+// Record queues a view without blocking the request. Views are dropped when the
+// buffer is full rather than slowing down reads.
+func (s *viewService) Record(postId, ip, userAgent, referrer string) {
+	if isBot(userAgent) {
+		return
+	}
+
+	day := time.Now().UTC().Truncate(24 * time.Hour)
+	view := types.PostView{
+		PostId:         postId,
+		Day:            day,
+		VisitorHash:    s.visitorHash(postId, ip, userAgent, day),
+		ReferrerDomain: referrerDomain(referrer),
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.stopped {
+		return
+	}
+
+	select {
+	case s.views <- view:
+	default:
+		log.Printf("view buffer full, dropping view of post %s", postId)
+	}
+}
+
+// Stop flushes the buffered views and stops the worker.
+func (s *viewService) Stop() error {
+	s.mu.Lock()
+	if !s.stopped {
+		s.stopped = true
+		close(s.views)
+	}
+	s.mu.Unlock()
+
+	<-s.done
+	return nil
+}
+
+func (s *viewService) run(flushInterval time.Duration) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]types.PostView, 0, s.batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := s.viewRepository.SaveViews(batch); err != nil {
+			log.Printf("error saving %d views: %v", len(batch), err)
+		}
+		batch = make([]types.PostView, 0, s.batchSize)
+	}
+
+	for {
+		select {
+		case view, ok := <-s.views:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, view)
+			if len(batch) >= s.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// visitorHash identifies a visitor for a single post and day. The day is part of
+// the hash so visitors can't be followed across days.
+func (s *viewService) visitorHash(postId, ip, userAgent string, day time.Time) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(strings.Join([]string{postId, ip, userAgent, day.Format("2006-01-02")}, "|")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func referrerDomain(referrer string) string {
+	if referrer == "" {
+		return ""
+	}
+
+	parsed, err := url.Parse(referrer)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+}
+
+func isBot(userAgent string) bool {
+	userAgent = strings.ToLower(userAgent)
+	for _, marker := range botMarkers {
+		if strings.Contains(userAgent, marker) {
+			return true
+		}
+	}
+	return false
+}
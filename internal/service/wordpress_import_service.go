@@ -0,0 +1,622 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"go-blog/internal/repository"
+	"go-blog/internal/types"
+	"html"
+	"io"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// wordpressDateLayout is how WXR files write post dates.
+const wordpressDateLayout = "2006-01-02 15:04:05"
+
+var (
+	wordpressURL = regexp.MustCompile(`https?://[^\s()<>"']+`)
+	// WordPress links resized copies of images, as photo-300x200.jpg for photo.jpg
+	wordpressImageSize = regexp.MustCompile(`-\d+x\d+(\.\w+)$`)
+)
+
+// ErrInvalidExport is returned when the file to import isn't a WXR export.
+var ErrInvalidExport = errors.New("invalid WXR export")
+
+type WordPressImportService interface {
+	Import(wxr io.Reader, uploadsDir string, fallbackAuthor *types.User) (*types.ImportReport, error)
+}
+
+type wordPressImportService struct {
+	importRepository   repository.WordPressImportRepository
+	userRepository     repository.UserRepository
+	categoryRepository repository.CategoryRepository
+	postRepository     repository.PostRepository
+	fileRepository     repository.FileRepository
+	fileService        FileService
+	slugService        SlugService
+}
+
+func NewWordPressImportService(
+	importRepository repository.WordPressImportRepository,
+	userRepository repository.UserRepository,
+	categoryRepository repository.CategoryRepository,
+	postRepository repository.PostRepository,
+	fileRepository repository.FileRepository,
+	fileService FileService,
+	slugService SlugService,
+) WordPressImportService {
+	return &wordPressImportService{
+		importRepository:   importRepository,
+		userRepository:     userRepository,
+		categoryRepository: categoryRepository,
+		postRepository:     postRepository,
+		fileRepository:     fileRepository,
+		fileService:        fileService,
+		slugService:        slugService,
+	}
+}
+
+// The parts of a WordPress eXtended RSS (WXR) export the import reads. Elements are
+// matched by local name, so exports of any WXR version can be read.
+type wxrDocument struct {
+	Channel struct {
+		Link        string        `xml:"link"`
+		BaseBlogUrl string        `xml:"base_blog_url"`
+		BaseSiteUrl string        `xml:"base_site_url"`
+		Authors     []wxrAuthor   `xml:"author"`
+		Categories  []wxrCategory `xml:"category"`
+		Items       []wxrItem     `xml:"item"`
+	} `xml:"channel"`
+}
+
+type wxrAuthor struct {
+	Id          int64  `xml:"author_id"`
+	Login       string `xml:"author_login"`
+	Email       string `xml:"author_email"`
+	DisplayName string `xml:"author_display_name"`
+	FirstName   string `xml:"author_first_name"`
+	LastName    string `xml:"author_last_name"`
+}
+
+type wxrCategory struct {
+	Id       int64  `xml:"term_id"`
+	Nicename string `xml:"category_nicename"`
+	Parent   string `xml:"category_parent"`
+	Name     string `xml:"cat_name"`
+}
+
+type wxrItem struct {
+	Title         string       `xml:"title"`
+	PubDate       string       `xml:"pubDate"`
+	Creator       string       `xml:"creator"`
+	Encoded       []wxrEncoded `xml:"encoded"`
+	Id            int64        `xml:"post_id"`
+	Date          string       `xml:"post_date"`
+	DateGmt       string       `xml:"post_date_gmt"`
+	Name          string       `xml:"post_name"`
+	Status        string       `xml:"status"`
+	Type          string       `xml:"post_type"`
+	AttachmentUrl string       `xml:"attachment_url"`
+	Meta          []wxrMeta    `xml:"postmeta"`
+	Terms         []wxrTerm    `xml:"category"`
+}
+
+// wxrEncoded is the content:encoded or excerpt:encoded element of an item, told apart
+// by their namespace.
+type wxrEncoded struct {
+	XMLName xml.Name
+	Text    string `xml:",chardata"`
+}
+
+type wxrMeta struct {
+	Key   string `xml:"meta_key"`
+	Value string `xml:"meta_value"`
+}
+
+type wxrTerm struct {
+	Domain   string `xml:"domain,attr"`
+	Nicename string `xml:"nicename,attr"`
+	Name     string `xml:",chardata"`
+}
+
+func (item wxrItem) encoded(namespaceFragment string) string {
+	for _, encoded := range item.Encoded {
+		if strings.Contains(encoded.XMLName.Space, namespaceFragment) {
+			return encoded.Text
+		}
+	}
+	return ""
+}
+
+func (item wxrItem) meta(key string) string {
+	for _, meta := range item.Meta {
+		if meta.Key == key {
+			return meta.Value
+		}
+	}
+	return ""
+}
+
+// site tells the WordPress site of the export by its URL, without the scheme so that a
+// site moved to HTTPS stays the same. The blog URL comes first, as the blogs of a
+// multisite network share the site URL. Exports without a URL share the empty site.
+func (document wxrDocument) site() string {
+	site := strings.TrimSpace(firstNonEmpty(document.Channel.BaseBlogUrl, document.Channel.Link, document.Channel.BaseSiteUrl))
+	if i := strings.Index(site, "://"); i >= 0 {
+		site = site[i+3:]
+	}
+	return strings.ToLower(strings.TrimRight(site, "/"))
+}
+
+// wordPressImport holds what one run of the import has mapped so far.
+type wordPressImport struct {
+	*wordPressImportService
+	site           string
+	uploadsDir     string
+	fallbackAuthor *types.User
+	report         types.ImportReport
+	authors        map[string]types.User
+	categories     map[string]string
+	attachments    map[int64]types.File
+	attachmentUrls map[string]string
+	tags           map[string]bool
+}
+
+// Import reads a WXR export and creates its authors as users, its categories with their
+// hierarchy, its attachments found under uploadsDir as files, and its posts with their
+// categories, tags, cover image and publish date. Nothing is downloaded: attachments
+// are looked up under uploadsDir by their path in the wp-content/uploads directory of
+// the site. Posts whose author can't be imported are credited to fallbackAuthor, when
+// given. Everything imported is remembered by its site and WordPress id, so importing
+// the same export again only imports what failed or was added since.
+func (s *wordPressImportService) Import(wxr io.Reader, uploadsDir string, fallbackAuthor *types.User) (*types.ImportReport, error) {
+	decoder := xml.NewDecoder(wxr)
+	// Exports often contain HTML entities, which XML doesn't define
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+
+	var document wxrDocument
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidExport, err)
+	}
+
+	run := &wordPressImport{
+		wordPressImportService: s,
+		site:                   document.site(),
+		uploadsDir:             uploadsDir,
+		fallbackAuthor:         fallbackAuthor,
+		report:                 types.ImportReport{Errors: []string{}},
+		authors:                make(map[string]types.User),
+		categories:             make(map[string]string),
+		attachments:            make(map[int64]types.File),
+		attachmentUrls:         make(map[string]string),
+		tags:                   make(map[string]bool),
+	}
+
+	if err := run.importAuthors(document.Channel.Authors); err != nil {
+		return nil, err
+	}
+	if err := run.importCategories(document.Channel.Categories); err != nil {
+		return nil, err
+	}
+
+	// Attachments first, so posts can link to them wherever they come in the export
+	for _, item := range document.Channel.Items {
+		if item.Type == "attachment" {
+			if err := run.importAttachment(item); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, item := range document.Channel.Items {
+		switch item.Type {
+		case "attachment":
+		case "post":
+			if err := run.importPost(item); err != nil {
+				return nil, err
+			}
+		default:
+			// Pages, menus and the like have no counterpart
+			run.report.Skipped++
+		}
+	}
+
+	run.report.Tags = len(run.tags)
+	return &run.report, nil
+}
+
+func (run *wordPressImport) fail(format string, args ...interface{}) {
+	run.report.Errors = append(run.report.Errors, fmt.Sprintf(format, args...))
+}
+
+func (run *wordPressImport) importAuthors(authors []wxrAuthor) error {
+	for _, author := range authors {
+		localId, err := run.importRepository.FindImported(run.site, types.WordPressUser, author.Id)
+		if err != nil {
+			return err
+		}
+		if localId != "" {
+			if user, err := run.userRepository.FindById(localId); err == nil {
+				run.authors[author.Login] = *user
+				run.report.Users.Existing++
+				continue
+			}
+		}
+
+		if author.Email == "" {
+			run.fail("author %s: no email", author.Login)
+			continue
+		}
+
+		// Authors who already have an account keep it
+		if user, err := run.userRepository.FindByEmail(author.Email); err == nil {
+			if err := run.importRepository.RecordImport(run.site, types.WordPressUser, author.Id, user.Id); err != nil {
+				return err
+			}
+			run.authors[author.Login] = *user
+			run.report.Users.Existing++
+			continue
+		}
+
+		name := firstNonEmpty(author.FirstName, author.DisplayName, author.Login)
+		user := types.User{
+			Name:     html.UnescapeString(strings.TrimSpace(name)),
+			Lastname: html.UnescapeString(strings.TrimSpace(author.LastName)),
+			Email:    strings.TrimSpace(author.Email),
+			Password: randomPassword(),
+		}
+		if fails := user.Validate(); fails != nil {
+			run.fail("author %s: invalid %v", author.Login, fails)
+			continue
+		}
+
+		createdUser, err := run.userRepository.Create(user)
+		if err != nil {
+			run.fail("author %s: %v", author.Login, err)
+			continue
+		}
+		if err := run.importRepository.RecordImport(run.site, types.WordPressUser, author.Id, createdUser.Id); err != nil {
+			return err
+		}
+
+		run.authors[author.Login] = *createdUser
+		run.report.Users.Created++
+	}
+
+	return nil
+}
+
+func (run *wordPressImport) importCategories(categories []wxrCategory) error {
+	byNicename := make(map[string]wxrCategory, len(categories))
+	for _, category := range categories {
+		byNicename[category.Nicename] = category
+	}
+
+	visiting := make(map[string]bool)
+	var importCategory func(category wxrCategory) error
+	importCategory = func(category wxrCategory) error {
+		if _, done := run.categories[category.Nicename]; done || visiting[category.Nicename] {
+			return nil
+		}
+		visiting[category.Nicename] = true
+
+		localId, err := run.importRepository.FindImported(run.site, types.WordPressCategory, category.Id)
+		if err != nil {
+			return err
+		}
+		if localId != "" {
+			if _, err := run.categoryRepository.FindById(localId); err == nil {
+				run.categories[category.Nicename] = localId
+				run.report.Categories.Existing++
+				return nil
+			}
+		}
+
+		// Parents are imported first, a category whose parent fails is imported at the top
+		var parentId *string
+		if parent, found := byNicename[category.Parent]; found {
+			if err := importCategory(parent); err != nil {
+				return err
+			}
+			if id, imported := run.categories[parent.Nicename]; imported {
+				parentId = &id
+			}
+		}
+
+		newCategory := types.Category{
+			Title:    html.UnescapeString(strings.TrimSpace(category.Name)),
+			Slug:     run.slugService.Generate(unescapeSlug(category.Nicename, category.Name), ""),
+			ParentId: parentId,
+		}
+		if fails := newCategory.Validate(); fails != nil {
+			run.fail("category %s: invalid %v", category.Nicename, fails)
+			return nil
+		}
+
+		createdCategory, err := run.categoryRepository.Create(newCategory)
+		if err != nil {
+			run.fail("category %s: %v", category.Nicename, err)
+			return nil
+		}
+		if err := run.importRepository.RecordImport(run.site, types.WordPressCategory, category.Id, createdCategory.Id); err != nil {
+			return err
+		}
+
+		run.categories[category.Nicename] = createdCategory.Id
+		run.report.Categories.Created++
+		return nil
+	}
+
+	for _, category := range categories {
+		if err := importCategory(category); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (run *wordPressImport) importAttachment(item wxrItem) error {
+	localId, err := run.importRepository.FindImported(run.site, types.WordPressAttachment, item.Id)
+	if err != nil {
+		return err
+	}
+	if localId != "" {
+		if file, err := run.fileRepository.FindById(localId); err == nil {
+			run.addAttachment(item, *file)
+			run.report.Attachments.Existing++
+			return nil
+		}
+	}
+
+	path := run.attachmentPath(item)
+	if path == "" {
+		run.fail("attachment %d: no path to look it up by", item.Id)
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		run.fail("attachment %d: %s not found", item.Id, path)
+		return nil
+	}
+
+	owner, ok := run.author(item)
+	if !ok {
+		run.fail("attachment %d: unknown author %s", item.Id, item.Creator)
+		return nil
+	}
+
+	filename, err := run.fileService.GenerateUniqueFilename(filepath.Base(path))
+	if err == nil {
+		err = run.fileService.SaveLocalFile(path, filename, owner)
+	}
+	if err != nil {
+		run.fail("attachment %d: %v", item.Id, err)
+		return nil
+	}
+
+	width, height := run.fileService.LocalImageSize(path)
+	file, err := run.fileRepository.Create(types.File{
+		UserId:      owner.Id,
+		Filename:    filename,
+		ContentType: mime.TypeByExtension(strings.ToLower(filepath.Ext(path))),
+		Size:        info.Size(),
+		Width:       width,
+		Height:      height,
+	})
+	if err != nil {
+		run.fileService.DeleteFile(filename, owner)
+		run.fail("attachment %d: %v", item.Id, err)
+		return nil
+	}
+	if err := run.importRepository.RecordImport(run.site, types.WordPressAttachment, item.Id, file.Id); err != nil {
+		return err
+	}
+
+	run.addAttachment(item, *file)
+	run.report.Attachments.Created++
+	return nil
+}
+
+// attachmentPath returns where the attachment should be under the uploads directory,
+// from its path relative to wp-content/uploads.
+func (run *wordPressImport) attachmentPath(item wxrItem) string {
+	relative := item.meta("_wp_attached_file")
+	if relative == "" {
+		if attachmentUrl, err := url.Parse(item.AttachmentUrl); err == nil {
+			if _, after, found := strings.Cut(attachmentUrl.Path, "/uploads/"); found {
+				relative = after
+			}
+		}
+	}
+	if relative == "" {
+		return ""
+	}
+
+	// Cleaning the path as an absolute one keeps it inside the uploads directory
+	return filepath.Join(run.uploadsDir, filepath.FromSlash(filepath.Clean("/"+relative)))
+}
+
+func (run *wordPressImport) addAttachment(item wxrItem, file types.File) {
+	run.attachments[item.Id] = file
+	if item.AttachmentUrl != "" {
+		run.attachmentUrls[item.AttachmentUrl] = file.Url
+	}
+}
+
+// localUrls points the links to attachments in the markdown to the imported files,
+// including links to resized copies of images.
+func (run *wordPressImport) localUrls(markdown string) string {
+	return wordpressURL.ReplaceAllStringFunc(markdown, func(link string) string {
+		if local, found := run.attachmentUrls[link]; found {
+			return local
+		}
+		if local, found := run.attachmentUrls[wordpressImageSize.ReplaceAllString(link, "$1")]; found {
+			return local
+		}
+		return link
+	})
+}
+
+func (run *wordPressImport) author(item wxrItem) (types.User, bool) {
+	if author, found := run.authors[item.Creator]; found {
+		return author, true
+	}
+	if run.fallbackAuthor != nil {
+		return *run.fallbackAuthor, true
+	}
+	return types.User{}, false
+}
+
+func (run *wordPressImport) importPost(item wxrItem) error {
+	var status string
+	switch item.Status {
+	case "publish":
+		status = types.PostStatusPublished
+	case "draft", "pending", "future", "private":
+		status = types.PostStatusDraft
+	default:
+		// Trashed posts, auto drafts and revisions
+		run.report.Skipped++
+		return nil
+	}
+
+	localId, err := run.importRepository.FindImported(run.site, types.WordPressPost, item.Id)
+	if err != nil {
+		return err
+	}
+	if localId != "" {
+		run.report.Posts.Existing++
+		return nil
+	}
+
+	author, ok := run.author(item)
+	if !ok {
+		run.fail("post %d: unknown author %s", item.Id, item.Creator)
+		return nil
+	}
+
+	title := html.UnescapeString(strings.TrimSpace(item.Title))
+	post := types.Post{
+		Title:     title,
+		Slug:      run.slugService.Generate(unescapeSlug(item.Name, title), ""),
+		Locale:    types.DefaultLocale(),
+		Status:    status,
+		Content:   run.localUrls(htmlToMarkdown(item.encoded("/content/"))),
+		Excerpt:   oneLine(types.StripMarkdown(htmlToMarkdown(item.encoded("/excerpt/")))),
+		CreatedAt: publishDate(item),
+		Author:    author,
+	}
+	if thumbnailId, err := strconv.ParseInt(item.meta("_thumbnail_id"), 10, 64); err == nil {
+		if file, found := run.attachments[thumbnailId]; found && file.IsImage() {
+			post.CoverImage = &types.CoverImage{Id: file.Id}
+		}
+	}
+	post.Summarize()
+
+	if fails := post.Validate(); fails != nil {
+		run.fail("post %d: invalid %v", item.Id, fails)
+		return nil
+	}
+
+	createdPost, err := run.postRepository.Create(post)
+	if err != nil {
+		run.fail("post %d: %v", item.Id, err)
+		return nil
+	}
+	if err := run.importRepository.RecordImport(run.site, types.WordPressPost, item.Id, createdPost.Id); err != nil {
+		return err
+	}
+	run.report.Posts.Created++
+
+	var categoryIds []string
+	var tags []types.Tag
+	seenTags := make(map[string]bool)
+	for _, term := range item.Terms {
+		switch term.Domain {
+		case "category":
+			if categoryId, found := run.categories[term.Nicename]; found {
+				categoryIds = append(categoryIds, categoryId)
+			}
+		case "post_tag":
+			tag := types.Tag{Name: html.UnescapeString(strings.TrimSpace(term.Name))}
+			tag.Slug = run.slugService.Generate(tag.Name, "")
+			if tag.Validate() != nil || seenTags[tag.Slug] {
+				continue
+			}
+			seenTags[tag.Slug] = true
+			tags = append(tags, tag)
+		}
+	}
+
+	if len(categoryIds) > 0 {
+		if err := run.postRepository.UpdatePostCategories(createdPost.Id, categoryIds); err != nil {
+			run.fail("post %d: categories: %v", item.Id, err)
+		}
+	}
+	if len(tags) > 0 {
+		if _, err := run.postRepository.UpdatePostTags(createdPost.Id, tags); err != nil {
+			run.fail("post %d: tags: %v", item.Id, err)
+		}
+		for slug := range seenTags {
+			run.tags[slug] = true
+		}
+	}
+
+	return nil
+}
+
+// publishDate returns when the item was published. Drafts have no date in GMT, their
+// local date is taken as UTC.
+func publishDate(item wxrItem) time.Time {
+	for _, date := range []string{item.DateGmt, item.Date} {
+		if parsed, err := time.Parse(wordpressDateLayout, strings.TrimSpace(date)); err == nil && parsed.Year() > 1 {
+			return parsed
+		}
+	}
+
+	if parsed, err := time.Parse(time.RFC1123Z, strings.TrimSpace(item.PubDate)); err == nil {
+		return parsed.UTC()
+	}
+
+	return time.Time{}
+}
+
+// unescapeSlug decodes a WordPress slug, which WordPress stores percent-encoded when it
+// isn't ASCII, falling back to the text it was made from.
+func unescapeSlug(slug, text string) string {
+	if unescaped, err := url.PathUnescape(slug); err == nil && unescaped != "" {
+		return unescaped
+	}
+	return text
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// randomPassword gives imported users a password nobody knows, they sign in with the
+// Google account of their email.
+func randomPassword() string {
+	bytes := make([]byte, 24)
+	if _, err := rand.Read(bytes); err != nil {
+		panic(fmt.Sprintf("failed to generate a password: %v", err))
+	}
+	return hex.EncodeToString(bytes)
+}
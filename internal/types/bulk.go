@@ -0,0 +1,67 @@
+package types
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	BulkActionDelete           = "delete"
+	BulkActionPublish          = "publish"
+	BulkActionUnpublish        = "unpublish"
+	BulkActionAssignCategories = "assign-categories"
+	BulkActionRemoveCategories = "remove-categories"
+	BulkActionChangeAuthor     = "change-author"
+)
+
+const (
+	BulkResultDone      = "done"
+	BulkResultNotFound  = "not_found"
+	BulkResultForbidden = "forbidden"
+	BulkResultFailed    = "failed"
+)
+
+// PostFilter selects the posts a bulk action applies to. Empty fields don't filter, and
+// bulk requests need at least one set.
+type PostFilter struct {
+	CategoryId    string     `json:"categoryId" validate:"omitempty,uuid"`
+	Tag           string     `json:"tag"`
+	AuthorId      string     `json:"authorId" validate:"omitempty,uuid"`
+	Status        string     `json:"status" validate:"omitempty,oneof=draft in_review changes_requested approved published archived"`
+	CreatedAfter  *time.Time `json:"createdAfter"`
+	CreatedBefore *time.Time `json:"createdBefore"`
+}
+
+// BulkRequest applies one action to the posts listed by id or matched by the filter.
+// CategoryIds go with the category actions and AuthorId with change-author.
+type BulkRequest struct {
+	Action      string      `json:"action" validate:"required,oneof=delete publish unpublish assign-categories remove-categories change-author"`
+	Ids         []string    `json:"ids" validate:"required_without=Filter,excluded_with=Filter,omitempty,dive,uuid"`
+	Filter      *PostFilter `json:"filter"`
+	CategoryIds []string    `json:"categoryIds" validate:"required_if=Action assign-categories,required_if=Action remove-categories,omitempty,dive,uuid"`
+	AuthorId    string      `json:"authorId" validate:"required_if=Action change-author,omitempty,uuid"`
+}
+
+// BulkResult reports what became of one post of a bulk action.
+type BulkResult struct {
+	Id     string `json:"id"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+func (r BulkRequest) Validate() map[string]string {
+	v := validator.New()
+	err := v.Struct(r)
+	if err == nil {
+		return nil
+	}
+
+	errorsMap := make(map[string]string)
+
+	for _, err := range err.(validator.ValidationErrors) {
+		errorsMap[err.Field()] = err.Tag()
+	}
+
+	return errorsMap
+}
@@ -1,16 +1,34 @@
 package types
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 )
 
 type Category struct {
-	Id        string    `json:"id,omitempty"`
-	Title     string    `json:"title,omitempty" validate:"required,min=3,max=50"`
-	Slug      string    `json:"slug,omitempty"`
-	CreatedAt time.Time `json:"createdAt,omitempty"`
+	Id        string     `json:"id,omitempty"`
+	Title     string     `json:"title,omitempty" validate:"required,min=3,max=50"`
+	Slug      string     `json:"slug,omitempty"`
+	ParentId  *string    `json:"parentId" validate:"omitempty,uuid"`
+	CreatedAt time.Time  `json:"createdAt,omitempty"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+	Version   int        `json:"version,omitempty"`
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+}
+
+// UnmarshalJSON accepts either a full category object or a bare category ID, so posts
+// can be patched with `"categories": ["1", "2"]`.
+func (c *Category) UnmarshalJSON(data []byte) error {
+	var id string
+	if err := json.Unmarshal(data, &id); err == nil {
+		*c = Category{Id: id}
+		return nil
+	}
+
+	type Alias Category
+	return json.Unmarshal(data, (*Alias)(c))
 }
 
 func (c Category) Validate() map[string]string {
@@ -0,0 +1,50 @@
+package types
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	CommentPending  = "pending"
+	CommentApproved = "approved"
+	CommentSpam     = "spam"
+	CommentDeleted  = "deleted"
+)
+
+type Comment struct {
+	Id        string    `json:"id,omitempty"`
+	PostId    string    `json:"postId,omitempty"`
+	ParentId  string    `json:"parentId,omitempty"`
+	Content   string    `json:"content,omitempty" validate:"required,min=1,max=5000"`
+	Status    string    `json:"status,omitempty"`
+	Author    User      `json:"author,omitempty" validate:"-"`
+	CreatedAt time.Time `json:"createdAt,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt,omitempty"`
+	Replies   []Comment `json:"replies,omitempty" validate:"-"`
+}
+
+func IsCommentStatus(status string) bool {
+	switch status {
+	case CommentPending, CommentApproved, CommentSpam, CommentDeleted:
+		return true
+	}
+	return false
+}
+
+func (c Comment) Validate() map[string]string {
+	v := validator.New()
+	err := v.Struct(c)
+	if err == nil {
+		return nil
+	}
+
+	errorsMap := make(map[string]string)
+
+	for _, err := range err.(validator.ValidationErrors) {
+		errorsMap[err.Field()] = err.Tag()
+	}
+
+	return errorsMap
+}
@@ -0,0 +1,49 @@
+package types
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Cursor points at a post in the (created_at, id) ordering used by keyset pagination.
+type Cursor struct {
+	CreatedAt time.Time
+	Id        string
+}
+
+func NewCursor(post Post) Cursor {
+	return Cursor{CreatedAt: post.CreatedAt, Id: post.Id}
+}
+
+// Encode returns the opaque, URL safe representation handed out to clients.
+func (c Cursor) Encode() string {
+	raw := fmt.Sprintf("%s|%s", c.CreatedAt.UTC().Format(time.RFC3339Nano), c.Id)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeCursor(encoded string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor encoding: %v", err)
+	}
+
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid cursor format")
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor timestamp: %v", err)
+	}
+
+	if _, err := uuid.Parse(parts[1]); err != nil {
+		return nil, fmt.Errorf("invalid cursor id: %v", err)
+	}
+
+	return &Cursor{CreatedAt: createdAt, Id: parts[1]}, nil
+}
@@ -0,0 +1,75 @@
+package types
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// FingerprintBits is the size of content fingerprints. Near duplicates differ in few of
+// their bits, and posts sharing nothing in about half of them.
+const FingerprintBits = 64
+
+// shingleWords is the number of consecutive words hashed together, so that reordered
+// sentences make different content.
+const shingleWords = 3
+
+// Duplicate is a post whose content nearly duplicates the content of another.
+type Duplicate struct {
+	Id          string    `json:"id"`
+	Title       string    `json:"title"`
+	Slug        string    `json:"slug"`
+	Status      string    `json:"status"`
+	CreatedAt   time.Time `json:"createdAt"`
+	Similarity  float64   `json:"similarity"`
+	Fingerprint uint64    `json:"-"`
+}
+
+// DuplicateCluster groups posts which nearly duplicate one another, oldest first. The
+// similarity of each post is to the oldest, the one the others likely copy.
+type DuplicateCluster struct {
+	Posts []Duplicate `json:"posts"`
+}
+
+// ContentFingerprint computes the SimHash of the normalised text of Markdown content:
+// its words, lowercased and without punctuation or markup. Content without words has no
+// fingerprint, 0.
+func ContentFingerprint(content string) uint64 {
+	words := strings.FieldsFunc(strings.ToLower(StripMarkdown(content)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	if len(words) == 0 {
+		return 0
+	}
+
+	var weights [FingerprintBits]int
+	for i := 0; i+shingleWords <= max(len(words), shingleWords); i++ {
+		hash := fnv.New64a()
+		hash.Write([]byte(strings.Join(words[i:min(i+shingleWords, len(words))], " ")))
+		sum := hash.Sum64()
+
+		for bit := range weights {
+			if sum&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	var fingerprint uint64
+	for bit, weight := range weights {
+		if weight > 0 {
+			fingerprint |= 1 << bit
+		}
+	}
+	return fingerprint
+}
+
+// FingerprintSimilarity is the share of the bits of two fingerprints which are equal,
+// from 0 to 1.
+func FingerprintSimilarity(a, b uint64) float64 {
+	return 1 - float64(bits.OnesCount64(a^b))/FingerprintBits
+}
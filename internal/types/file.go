@@ -0,0 +1,46 @@
+package types
+
+import (
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// File is an upload stored under the uploads directory of its owner.
+type File struct {
+	Id          string    `json:"id"`
+	UserId      string    `json:"userId"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"contentType"`
+	Size        int64     `json:"size"`
+	Width       int       `json:"width"`
+	Height      int       `json:"height"`
+	Url         string    `json:"url"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// CoverImage is the uploaded image shown at the top of a post.
+type CoverImage struct {
+	Id     string `json:"id" validate:"required"`
+	Url    string `json:"url,omitempty"`
+	Width  int    `json:"width,omitempty"`
+	Height int    `json:"height,omitempty"`
+	Alt    string `json:"alt" validate:"max=250"`
+}
+
+// IsImage reports whether the dimensions of the file could be read.
+func (f File) IsImage() bool {
+	return f.Width > 0 && f.Height > 0
+}
+
+// FileURL returns the public URL of an uploaded file. UPLOADS_URL sets the base
+// URL and defaults to the /uploads route of the API.
+func FileURL(userId, filename string) string {
+	base := os.Getenv("UPLOADS_URL")
+	if base == "" {
+		base = "/uploads"
+	}
+
+	return strings.TrimRight(base, "/") + "/" + url.PathEscape(userId) + "/" + url.PathEscape(filename)
+}
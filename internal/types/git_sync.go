@@ -0,0 +1,21 @@
+package types
+
+import "time"
+
+// GitSyncedPost is the post a Markdown file of the synced git repository was imported
+// as, with the blob it was imported from and the commit it was last synced at.
+type GitSyncedPost struct {
+	Path      string    `json:"path"`
+	PostId    string    `json:"postId"`
+	BlobSha   string    `json:"blobSha"`
+	CommitSha string    `json:"commitSha"`
+	SyncedAt  time.Time `json:"syncedAt"`
+}
+
+// GitSyncReport sums up a sync of the posts with a commit of a git repository. Posts
+// whose file is gone from the commit are archived.
+type GitSyncReport struct {
+	Commit string `json:"commit"`
+	MarkdownImportReport
+	Archived int `json:"archived"`
+}
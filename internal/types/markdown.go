@@ -0,0 +1,10 @@
+package types
+
+// MarkdownImportReport sums up an import of posts written as Markdown files. Files that
+// couldn't be imported are left out and described in Errors.
+type MarkdownImportReport struct {
+	Created   int      `json:"created"`
+	Updated   int      `json:"updated"`
+	Unchanged int      `json:"unchanged"`
+	Errors    []string `json:"errors"`
+}
@@ -2,19 +2,78 @@ package types
 
 import (
 	"encoding/json"
+	"slices"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 )
 
+const (
+	PostStatusDraft     = "draft"
+	PostStatusPublished = "published"
+	// Archived posts are hidden like drafts, the git sync archives the posts whose file
+	// was removed
+	PostStatusArchived = "archived"
+	// Posts under review are hidden like drafts. Authors submit them for review, editors
+	// request changes or approve them, and authors publish them once approved.
+	PostStatusInReview         = "in_review"
+	PostStatusChangesRequested = "changes_requested"
+	PostStatusApproved         = "approved"
+)
+
+// authorStatusChanges lists the status changes authors may make to their posts, by
+// current status. Editors may make any.
+var authorStatusChanges = map[string][]string{
+	PostStatusDraft:            {PostStatusInReview},
+	PostStatusInReview:         {PostStatusDraft},
+	PostStatusChangesRequested: {PostStatusInReview, PostStatusDraft},
+	PostStatusApproved:         {PostStatusPublished, PostStatusDraft},
+	PostStatusPublished:        {PostStatusDraft, PostStatusArchived},
+	PostStatusArchived:         {PostStatusDraft},
+}
+
+// Who may read a published post. Unlisted posts are read by anyone with their link but
+// left out of listings, password protected posts by whoever unlocked them with their
+// password and members only posts by signed in users. Others get a teaser of the post,
+// its excerpt. Authors and editors may always read their posts.
+const (
+	VisibilityPublic   = "public"
+	VisibilityUnlisted = "unlisted"
+	VisibilityPassword = "password"
+	VisibilityMembers  = "members"
+)
+
 type Post struct {
-	Id         string     `json:"id,omitempty"`
-	Title      string     `json:"title,omitempty" validate:"required,min=3,max=50"`
-	Slug       string     `json:"slug,omitempty"`
-	Content    string     `json:"content,omitempty"  validate:"required,min=3"`
-	CreatedAt  time.Time  `json:"createdAt,omitempty"`
-	Author     User       `json:"author,omitempty" validate:"-"`
-	Categories []Category `json:"categories" validate:"-"`
+	Id                 string        `json:"id,omitempty"`
+	Title              string        `json:"title,omitempty" validate:"required,min=3,max=50"`
+	Slug               string        `json:"slug,omitempty"`
+	Locale             string        `json:"locale" validate:"omitempty,len=2,lowercase,alpha"`
+	Status             string        `json:"status" validate:"omitempty,oneof=draft in_review changes_requested approved published archived"`
+	ReviewerId         string        `json:"reviewerId,omitempty" validate:"-"`
+	Visibility         string        `json:"visibility" validate:"omitempty,oneof=public unlisted password members"`
+	Password           string        `json:"password,omitempty" validate:"omitempty,min=4,max=72"`
+	PasswordHash       string        `json:"-" validate:"-"`
+	Locked             bool          `json:"locked,omitempty" validate:"-"`
+	Content            string        `json:"content,omitempty"  validate:"required,min=3"`
+	Excerpt            string        `json:"excerpt" validate:"max=300"`
+	ExcerptGenerated   bool          `json:"excerptGenerated" validate:"-"`
+	WordCount          int           `json:"wordCount" validate:"-"`
+	ReadingTimeMinutes int           `json:"readingTimeMinutes" validate:"-"`
+	Seo                PostSeo       `json:"seo"`
+	CoverImage         *CoverImage   `json:"coverImage"`
+	CreatedAt          time.Time     `json:"createdAt,omitempty"`
+	UpdatedAt          time.Time     `json:"updatedAt" validate:"-"`
+	Version            int           `json:"version" validate:"-"`
+	DeletedAt          *time.Time    `json:"deletedAt,omitempty" validate:"-"`
+	Author             User          `json:"author,omitempty" validate:"-"`
+	Authors            []PostAuthor  `json:"authors" validate:"-"`
+	Categories         []Category    `json:"categories" validate:"-"`
+	Tags               []Tag         `json:"tags" validate:"-"`
+	CommentCount       int           `json:"commentCount" validate:"-"`
+	Reactions          []Reaction    `json:"reactions" validate:"-"`
+	Series             *PostSeries   `json:"series,omitempty" validate:"-"`
+	Translations       []Translation `json:"translations" validate:"-"`
+	NearDuplicates     []Duplicate   `json:"nearDuplicates,omitempty" validate:"-"`
 }
 
 func (p Post) MarshalJSON() ([]byte, error) {
@@ -28,6 +87,27 @@ func (p Post) MarshalJSON() ([]byte, error) {
 	})
 }
 
+// IsPublished reports whether the post is visible to readers.
+func (p Post) IsPublished() bool {
+	return p.Status == PostStatusPublished
+}
+
+// IsInReview reports whether the post is somewhere in the review workflow, submitted
+// and not yet published.
+func (p Post) IsInReview() bool {
+	return p.Status == PostStatusInReview || p.Status == PostStatusChangesRequested || p.Status == PostStatusApproved
+}
+
+// CanChangeStatus reports whether the user may move the post to the status. Authors go
+// through review, only editors approve posts or publish them without approval.
+func (p Post) CanChangeStatus(user User, status string) bool {
+	if status == p.Status || user.HasRole(RoleEditor) {
+		return true
+	}
+
+	return slices.Contains(authorStatusChanges[p.Status], status)
+}
+
 func (p Post) Validate() map[string]string {
 	v := validator.New()
 	err := v.Struct(p)
@@ -0,0 +1,57 @@
+package types
+
+import (
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	AuthorRoleAuthor      = "author"
+	AuthorRoleContributor = "contributor"
+	AuthorRoleEditor      = "editor"
+)
+
+// PostAuthor is a user credited on a post. Authors are listed in byline order.
+type PostAuthor struct {
+	Id       string `json:"id" validate:"required"`
+	Name     string `json:"name,omitempty"`
+	Lastname string `json:"lastname,omitempty"`
+	Email    string `json:"email,omitempty"`
+	Role     string `json:"role" validate:"required,oneof=author contributor editor"`
+}
+
+func (a PostAuthor) Validate() map[string]string {
+	v := validator.New()
+	err := v.Struct(a)
+	if err == nil {
+		return nil
+	}
+
+	errorsMap := make(map[string]string)
+
+	for _, err := range err.(validator.ValidationErrors) {
+		errorsMap[err.Field()] = err.Tag()
+	}
+
+	return errorsMap
+}
+
+// IsOwner reports whether the user created the post. Only the owner may delete
+// the post or change its authors.
+func (p Post) IsOwner(userId string) bool {
+	return p.Author.Id == userId
+}
+
+// IsAuthor reports whether the user is the owner or any of the listed authors.
+func (p Post) IsAuthor(userId string) bool {
+	if p.IsOwner(userId) {
+		return true
+	}
+
+	for _, author := range p.Authors {
+		if author.Id == userId {
+			return true
+		}
+	}
+
+	return false
+}
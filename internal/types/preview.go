@@ -0,0 +1,14 @@
+package types
+
+import "time"
+
+// PreviewLink shares a post, usually a draft, with people without an account until it
+// expires or is revoked. The token of the link is only known to the service signing it.
+type PreviewLink struct {
+	Id        string    `json:"id"`
+	PostId    string    `json:"postId"`
+	Token     string    `json:"token,omitempty"`
+	CreatedBy string    `json:"createdBy"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,19 @@
+package types
+
+// ReactionTypes is the fixed set of reactions a user can leave on a post.
+var ReactionTypes = []string{"like", "heart", "laugh", "wow", "sad", "clap"}
+
+type Reaction struct {
+	Type    string `json:"type"`
+	Count   int    `json:"count"`
+	Reacted bool   `json:"reacted"`
+}
+
+func IsReactionType(reactionType string) bool {
+	for _, t := range ReactionTypes {
+		if t == reactionType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package types
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Review is an entry of the review history of a post, by one of its authors or an
+// editor, only shown to them. It moves the post to Status along the review workflow, as
+// when requesting changes or approving it, leaves a comment, or both. Without a status
+// the post stays where it is, and the status returned is the one it was left at.
+type Review struct {
+	Id        string    `json:"id,omitempty"`
+	PostId    string    `json:"postId,omitempty"`
+	Status    string    `json:"status,omitempty" validate:"omitempty,oneof=draft in_review changes_requested approved published archived"`
+	Comment   string    `json:"comment,omitempty" validate:"required_without=Status,max=5000"`
+	Author    User      `json:"author,omitempty" validate:"-"`
+	CreatedAt time.Time `json:"createdAt,omitempty"`
+}
+
+func (r Review) Validate() map[string]string {
+	v := validator.New()
+	err := v.Struct(r)
+	if err == nil {
+		return nil
+	}
+
+	errorsMap := make(map[string]string)
+
+	for _, err := range err.(validator.ValidationErrors) {
+		errorsMap[err.Field()] = err.Tag()
+	}
+
+	return errorsMap
+}
@@ -0,0 +1,101 @@
+package types
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	MetaTitleLength       = 70
+	MetaDescriptionLength = 160
+)
+
+// PostSeo holds the search engine metadata of a post. Empty fields fall back to
+// values derived from the post itself, see Post.ResolvedSeo.
+type PostSeo struct {
+	MetaTitle       string `json:"metaTitle" validate:"max=70"`
+	MetaDescription string `json:"metaDescription" validate:"max=160"`
+	CanonicalUrl    string `json:"canonicalUrl" validate:"omitempty,url,max=2048"`
+	OgImage         string `json:"ogImage" validate:"omitempty,url,max=2048"`
+	Noindex         bool   `json:"noindex"`
+}
+
+// ResolvedSeo fills the empty SEO fields of the post from its title, excerpt, cover
+// image and public URL on the client.
+func (p Post) ResolvedSeo() PostSeo {
+	seo := p.Seo
+
+	if seo.MetaTitle == "" {
+		seo.MetaTitle = truncateWords(p.Title, MetaTitleLength)
+	}
+
+	if seo.MetaDescription == "" {
+		seo.MetaDescription = truncateWords(p.Excerpt, MetaDescriptionLength)
+	}
+
+	if seo.CanonicalUrl == "" && p.Slug != "" {
+		if clientUrl := os.Getenv("CLIENT_URL"); clientUrl != "" {
+			seo.CanonicalUrl = strings.TrimRight(clientUrl, "/") + "/posts/" + p.Slug
+		}
+	}
+
+	if seo.OgImage == "" && p.CoverImage != nil {
+		seo.OgImage = p.CoverImage.Url
+	}
+
+	return seo
+}
+
+type JsonLdPerson struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
+
+// BlogPosting is the schema.org structured data of a post.
+type BlogPosting struct {
+	Context          string       `json:"@context"`
+	Type             string       `json:"@type"`
+	Headline         string       `json:"headline"`
+	Description      string       `json:"description,omitempty"`
+	Url              string       `json:"url,omitempty"`
+	MainEntityOfPage string       `json:"mainEntityOfPage,omitempty"`
+	Image            string       `json:"image,omitempty"`
+	DatePublished    string       `json:"datePublished"`
+	WordCount        int          `json:"wordCount"`
+	Author           JsonLdPerson `json:"author"`
+	ArticleSection   []string     `json:"articleSection,omitempty"`
+	Keywords         string       `json:"keywords,omitempty"`
+}
+
+func (p Post) BlogPosting() BlogPosting {
+	seo := p.ResolvedSeo()
+
+	posting := BlogPosting{
+		Context:          "https://schema.org",
+		Type:             "BlogPosting",
+		Headline:         p.Title,
+		Description:      seo.MetaDescription,
+		Url:              seo.CanonicalUrl,
+		MainEntityOfPage: seo.CanonicalUrl,
+		Image:            seo.OgImage,
+		DatePublished:    p.CreatedAt.Format(time.RFC3339),
+		WordCount:        p.WordCount,
+		Author: JsonLdPerson{
+			Type: "Person",
+			Name: strings.TrimSpace(p.Author.Name + " " + p.Author.Lastname),
+		},
+	}
+
+	for _, category := range p.Categories {
+		posting.ArticleSection = append(posting.ArticleSection, category.Title)
+	}
+
+	keywords := make([]string, len(p.Tags))
+	for i, tag := range p.Tags {
+		keywords[i] = tag.Name
+	}
+	posting.Keywords = strings.Join(keywords, ", ")
+
+	return posting
+}
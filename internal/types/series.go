@@ -0,0 +1,81 @@
+package types
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type Series struct {
+	Id          string       `json:"id,omitempty"`
+	Title       string       `json:"title,omitempty" validate:"required,min=3,max=100"`
+	Slug        string       `json:"slug,omitempty"`
+	Description string       `json:"description" validate:"max=500"`
+	OwnerId     string       `json:"ownerId,omitempty" validate:"-"`
+	PartCount   int          `json:"partCount" validate:"-"`
+	Parts       []SeriesPart `json:"parts,omitempty" validate:"-"`
+	CreatedAt   time.Time    `json:"createdAt,omitempty"`
+}
+
+// SeriesPart is a post of a series. Parts are numbered from 1 in reading order.
+type SeriesPart struct {
+	Id    string `json:"id"`
+	Title string `json:"title"`
+	Slug  string `json:"slug"`
+	Part  int    `json:"part"`
+}
+
+// PostSeries tells where a post stands within its series.
+type PostSeries struct {
+	Title string      `json:"title"`
+	Slug  string      `json:"slug"`
+	Part  int         `json:"part"`
+	Total int         `json:"total"`
+	Prev  *SeriesPart `json:"prev"`
+	Next  *SeriesPart `json:"next"`
+}
+
+func (s Series) Validate() map[string]string {
+	v := validator.New()
+	err := v.Struct(s)
+	if err == nil {
+		return nil
+	}
+
+	errorsMap := make(map[string]string)
+
+	for _, err := range err.(validator.ValidationErrors) {
+		errorsMap[err.Field()] = err.Tag()
+	}
+
+	return errorsMap
+}
+
+// Navigation returns the position of the post within the series along with its
+// neighbours, or nil when the post isn't part of it.
+func (s Series) Navigation(postId string) *PostSeries {
+	for i, part := range s.Parts {
+		if part.Id != postId {
+			continue
+		}
+
+		navigation := &PostSeries{
+			Title: s.Title,
+			Slug:  s.Slug,
+			Part:  part.Part,
+			Total: len(s.Parts),
+		}
+		if i > 0 {
+			prev := s.Parts[i-1]
+			navigation.Prev = &prev
+		}
+		if i < len(s.Parts)-1 {
+			next := s.Parts[i+1]
+			navigation.Next = &next
+		}
+
+		return navigation
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package types
+
+import "time"
+
+// PostView is a single deduplicated read of a post. Visitors are only known by
+// a hash of their request fingerprint for the day, raw IPs are never stored.
+type PostView struct {
+	PostId         string
+	Day            time.Time
+	VisitorHash    string
+	ReferrerDomain string
+}
+
+type DailyViews struct {
+	Date  string `json:"date"`
+	Views int    `json:"views"`
+}
+
+type ReferrerViews struct {
+	Domain string `json:"domain"`
+	Views  int    `json:"views"`
+}
+
+type PostStats struct {
+	PostId     string          `json:"postId"`
+	TotalViews int             `json:"totalViews"`
+	Series     []DailyViews    `json:"series"`
+	Referrers  []ReferrerViews `json:"referrers"`
+}
+
+type TopPost struct {
+	Id     string       `json:"id"`
+	Title  string       `json:"title"`
+	Slug   string       `json:"slug"`
+	Views  int          `json:"views"`
+	Series []DailyViews `json:"series"`
+}
@@ -0,0 +1,83 @@
+package types
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	ExcerptLength  = 200
+	WordsPerMinute = 200
+)
+
+var (
+	markdownCodeFence  = regexp.MustCompile("(?s)```.*?```")
+	markdownImage      = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	markdownLink       = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	markdownLinePrefix = regexp.MustCompile(`(?m)^[ \t]{0,3}(#{1,6}\s+|>\s?|[-*+]\s+|\d+\.\s+)`)
+	markdownEmphasis   = regexp.MustCompile("[*_~`]+")
+	markdownRule       = regexp.MustCompile(`(?m)^\s*([-*_]\s*){3,}$`)
+	blankLines         = regexp.MustCompile(`\n\s*\n`)
+)
+
+// StripMarkdown turns Markdown into plain text, keeping the text of links and
+// dropping images and code blocks.
+func StripMarkdown(markdown string) string {
+	text := strings.ReplaceAll(markdown, "\r\n", "\n")
+	text = markdownCodeFence.ReplaceAllString(text, "")
+	text = markdownImage.ReplaceAllString(text, "")
+	text = markdownLink.ReplaceAllString(text, "$1")
+	text = markdownRule.ReplaceAllString(text, "")
+	text = markdownLinePrefix.ReplaceAllString(text, "")
+	text = markdownEmphasis.ReplaceAllString(text, "")
+	return strings.TrimSpace(text)
+}
+
+// Summarize computes the word count and reading time of the post, and generates
+// an excerpt from its first paragraph unless one was given explicitly. ExcerptGenerated
+// tells which it was.
+func (p *Post) Summarize() {
+	text := StripMarkdown(p.Content)
+
+	p.WordCount = len(strings.Fields(text))
+	p.ReadingTimeMinutes = 0
+	if p.WordCount > 0 {
+		p.ReadingTimeMinutes = (p.WordCount + WordsPerMinute - 1) / WordsPerMinute
+	}
+
+	p.Excerpt = strings.TrimSpace(p.Excerpt)
+	p.ExcerptGenerated = p.Excerpt == ""
+	if !p.ExcerptGenerated {
+		return
+	}
+
+	for _, paragraph := range blankLines.Split(p.Content, -1) {
+		// Headings describe the post rather than summarize it
+		if strings.HasPrefix(strings.TrimSpace(paragraph), "#") {
+			continue
+		}
+		if paragraph = strings.Join(strings.Fields(StripMarkdown(paragraph)), " "); paragraph != "" {
+			p.Excerpt = truncateWords(paragraph, ExcerptLength)
+			return
+		}
+	}
+
+	p.Excerpt = truncateWords(strings.Join(strings.Fields(text), " "), ExcerptLength)
+}
+
+// truncateWords shortens text to at most max characters without cutting words in half.
+func truncateWords(text string, max int) string {
+	if utf8.RuneCountInString(text) <= max {
+		return text
+	}
+
+	runes := []rune(text)
+	// Leave room for the ellipsis
+	cut := string(runes[:max-1])
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+
+	return strings.TrimRight(cut, " ,.;:") + "…"
+}
@@ -0,0 +1,45 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type Tag struct {
+	Id        string    `json:"id,omitempty"`
+	Name      string    `json:"name,omitempty" validate:"required,min=1,max=50"`
+	Slug      string    `json:"slug,omitempty"`
+	PostCount int       `json:"postCount,omitempty"`
+	CreatedAt time.Time `json:"createdAt,omitempty"`
+}
+
+// UnmarshalJSON accepts either a full tag object or a bare tag name, so posts
+// can be submitted with `"tags": ["go", "fiber"]`.
+func (t *Tag) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*t = Tag{Name: name}
+		return nil
+	}
+
+	type Alias Tag
+	return json.Unmarshal(data, (*Alias)(t))
+}
+
+func (t Tag) Validate() map[string]string {
+	v := validator.New()
+	err := v.Struct(t)
+	if err == nil {
+		return nil
+	}
+
+	errorsMap := make(map[string]string)
+
+	for _, err := range err.(validator.ValidationErrors) {
+		errorsMap[err.Field()] = err.Tag()
+	}
+
+	return errorsMap
+}
@@ -0,0 +1,126 @@
+package types
+
+import (
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// PostTranslation is a post written in another language than its own.
+type PostTranslation struct {
+	Locale             string    `json:"locale" validate:"required,len=2,lowercase,alpha"`
+	Title              string    `json:"title" validate:"required,min=3,max=50"`
+	Slug               string    `json:"slug"`
+	Content            string    `json:"content" validate:"required,min=3"`
+	Excerpt            string    `json:"excerpt" validate:"max=300"`
+	WordCount          int       `json:"wordCount" validate:"-"`
+	ReadingTimeMinutes int       `json:"readingTimeMinutes" validate:"-"`
+	UpdatedAt          time.Time `json:"updatedAt"`
+}
+
+// Translation links a post to one of its other languages.
+type Translation struct {
+	Locale string `json:"locale"`
+	Title  string `json:"title"`
+	Slug   string `json:"slug"`
+	Url    string `json:"url"`
+}
+
+// DefaultLocale is the language of posts created without one. DEFAULT_LOCALE sets it
+// and defaults to en.
+func DefaultLocale() string {
+	if locale := strings.ToLower(os.Getenv("DEFAULT_LOCALE")); locale != "" {
+		return locale
+	}
+	return "en"
+}
+
+// TranslationURL returns the API URL of the post in the given language.
+func TranslationURL(slug, locale string) string {
+	return "/api/posts/" + url.PathEscape(slug) + "?lang=" + url.QueryEscape(locale)
+}
+
+func (t PostTranslation) Validate() map[string]string {
+	v := validator.New()
+	err := v.Struct(t)
+	if err == nil {
+		return nil
+	}
+
+	errorsMap := make(map[string]string)
+
+	for _, err := range err.(validator.ValidationErrors) {
+		errorsMap[err.Field()] = err.Tag()
+	}
+
+	return errorsMap
+}
+
+// Summarize computes the word count, reading time and, unless given, the excerpt of
+// the translation the same way as for posts.
+func (t *PostTranslation) Summarize() {
+	post := Post{Content: t.Content, Excerpt: t.Excerpt}
+	post.Summarize()
+
+	t.Excerpt = post.Excerpt
+	t.WordCount = post.WordCount
+	t.ReadingTimeMinutes = post.ReadingTimeMinutes
+}
+
+// Locales lists the languages the post is available in, its own first.
+func (p Post) Locales() []string {
+	locales := []string{p.Locale}
+	for _, translation := range p.Translations {
+		locales = append(locales, translation.Locale)
+	}
+	return locales
+}
+
+// SlugLocale returns the language of the translation known by slug, or an empty
+// string when no translation has it.
+func (p Post) SlugLocale(slug string) string {
+	for _, translation := range p.Translations {
+		if translation.Slug == slug {
+			return translation.Locale
+		}
+	}
+	return ""
+}
+
+// HasSlug reports whether slug is the current slug of the post in any language.
+func (p Post) HasSlug(slug string) bool {
+	return p.Slug == slug || p.SlugLocale(slug) != ""
+}
+
+// Localized returns the post in the language of the translation. Its translations then
+// link to every other language, including the one the post is written in. The meta
+// title and description are left to be derived from the translation.
+func (p Post) Localized(translation PostTranslation) Post {
+	translations := []Translation{{
+		Locale: p.Locale,
+		Title:  p.Title,
+		Slug:   p.Slug,
+		Url:    TranslationURL(p.Slug, p.Locale),
+	}}
+	for _, t := range p.Translations {
+		if t.Locale != translation.Locale {
+			translations = append(translations, t)
+		}
+	}
+
+	p.Translations = translations
+	p.Locale = translation.Locale
+	p.Title = translation.Title
+	p.Slug = translation.Slug
+	p.Content = translation.Content
+	p.Excerpt = translation.Excerpt
+	p.WordCount = translation.WordCount
+	p.ReadingTimeMinutes = translation.ReadingTimeMinutes
+	p.Seo.MetaTitle = ""
+	p.Seo.MetaDescription = ""
+
+	return p
+}
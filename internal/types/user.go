@@ -9,6 +9,12 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+const (
+	RoleUser   = "user"
+	RoleEditor = "editor"
+	RoleAdmin  = "admin"
+)
+
 type User struct {
 	Id             string    `json:"id,omitempty" db:"id"`
 	Name           string    `json:"name,omitempty" validate:"required,min=3,max=50" db:"name"`
@@ -18,6 +24,7 @@ type User struct {
 	GoogleID       string    `json:"google_id,omitempty" db:"google_id"`
 	ProfilePicture string    `json:"profile_picture,omitempty" db:"profile_picture"`
 	AuthProvider   string    `json:"auth_provider,omitempty" db:"auth_provider"`
+	Role           string    `json:"role,omitempty" db:"role"`
 	CreatedAt      time.Time `json:"created_at,omitempty" db:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at,omitempty" db:"updated_at"`
 }
@@ -38,6 +45,21 @@ func (u User) Validate() map[string]string {
 	return errorsMap
 }
 
+// HasRole reports whether the user holds one of the given roles. Admins hold every role.
+func (u User) HasRole(roles ...string) bool {
+	if u.Role == RoleAdmin {
+		return true
+	}
+
+	for _, role := range roles {
+		if u.Role == role {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (u User) MarshalJSON() ([]byte, error) {
 	type Alias User
 	aux := struct {
@@ -0,0 +1,28 @@
+package types
+
+// The kinds of WordPress objects an import remembers by their WordPress id.
+const (
+	WordPressUser       = "user"
+	WordPressCategory   = "category"
+	WordPressAttachment = "attachment"
+	WordPressPost       = "post"
+)
+
+// ImportCount tells how many objects of a kind an import created, and how many it
+// skipped because an earlier import had already created them.
+type ImportCount struct {
+	Created  int `json:"created"`
+	Existing int `json:"existing"`
+}
+
+// ImportReport sums up a WordPress import. Objects that couldn't be imported are left
+// out and described in Errors, running the import again retries them.
+type ImportReport struct {
+	Users       ImportCount `json:"users"`
+	Categories  ImportCount `json:"categories"`
+	Attachments ImportCount `json:"attachments"`
+	Posts       ImportCount `json:"posts"`
+	Tags        int         `json:"tags"`
+	Skipped     int         `json:"skipped"`
+	Errors      []string    `json:"errors"`
+}
@@ -0,0 +1,144 @@
+package handler_test
+
+import (
+	"go-blog/internal/handler"
+	"go-blog/internal/repository"
+	"go-blog/internal/service"
+	"go-blog/internal/types"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCommentRepository struct {
+	repository.CommentRepository
+	mock.Mock
+}
+
+func (m *MockCommentRepository) FindByPost(postId string, statuses []string) ([]types.Comment, error) {
+	args := m.Called(postId, statuses)
+	return args.Get(0).([]types.Comment), args.Error(1)
+}
+
+func (m *MockCommentRepository) Create(comment types.Comment) (*types.Comment, error) {
+	args := m.Called(comment)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.Comment), args.Error(1)
+}
+
+func (m *MockCommentRepository) FindById(id string) (*types.Comment, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.Comment), args.Error(1)
+}
+
+func (m *MockCommentRepository) Update(id string, content string, status string) (*types.Comment, error) {
+	args := m.Called(id, content, status)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.Comment), args.Error(1)
+}
+
+// commentApp serves the comments as the given user, or to anonymous readers when the
+// user has no id.
+func commentApp(commentRepo *MockCommentRepository, postRepo *MockPostRepository, user types.User) *fiber.App {
+	commentHandler := handler.NewCommentHandler(commentRepo, postRepo, service.NewPostUnlockService())
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		if user.Id != "" {
+			c.Locals("user", user)
+		}
+		return c.Next()
+	})
+	app.Get("/posts/:postId/comments", commentHandler.GetCommentsHandler)
+	app.Post("/posts/:postId/comments", commentHandler.CreateCommentHandler)
+	app.Put("/comments/:id", commentHandler.UpdateCommentHandler)
+	return app
+}
+
+func TestCommentHandlers_PostVisibility(t *testing.T) {
+	reader := types.User{Id: "u2", Role: types.RoleUser}
+	post := func(status, visibility string) *types.Post {
+		return &types.Post{Id: "p1", Status: status, Visibility: visibility, Author: types.User{Id: "u1"}, Authors: []types.PostAuthor{{Id: "u1"}}}
+	}
+
+	tests := []struct {
+		name       string
+		post       *types.Post
+		user       types.User
+		wantStatus int
+	}{
+		{"Drafts are hidden from readers", post(types.PostStatusDraft, types.VisibilityPublic), reader, fiber.StatusNotFound},
+		{"Posts in review are hidden from readers", post(types.PostStatusInReview, types.VisibilityPublic), types.User{}, fiber.StatusNotFound},
+		{"Members only posts are locked to anonymous readers", post(types.PostStatusPublished, types.VisibilityMembers), types.User{}, fiber.StatusForbidden},
+		{"Password protected posts are locked until unlocked", post(types.PostStatusPublished, types.VisibilityPassword), reader, fiber.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commentRepo, postRepo := new(MockCommentRepository), new(MockPostRepository)
+			postRepo.On("FindById", "p1").Return(tt.post, nil)
+
+			resp, _ := commentApp(commentRepo, postRepo, tt.user).Test(httptest.NewRequest("GET", "/posts/p1/comments", nil))
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+
+			if tt.user.Id != "" {
+				req := httptest.NewRequest("POST", "/posts/p1/comments", strings.NewReader(`{"content":"First!"}`))
+				req.Header.Set("Content-Type", "application/json")
+				resp, _ = commentApp(commentRepo, postRepo, tt.user).Test(req)
+				assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			}
+
+			commentRepo.AssertNotCalled(t, "FindByPost", mock.Anything, mock.Anything)
+			commentRepo.AssertNotCalled(t, "Create", mock.Anything)
+		})
+	}
+
+	t.Run("Members read the comments of members only posts", func(t *testing.T) {
+		commentRepo, postRepo := new(MockCommentRepository), new(MockPostRepository)
+		postRepo.On("FindById", "p1").Return(post(types.PostStatusPublished, types.VisibilityMembers), nil)
+		commentRepo.On("FindByPost", "p1", mock.Anything).Return([]types.Comment{}, nil).Once()
+
+		resp, _ := commentApp(commentRepo, postRepo, reader).Test(httptest.NewRequest("GET", "/posts/p1/comments", nil))
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		commentRepo.AssertExpectations(t)
+	})
+}
+
+func TestUpdateCommentHandler_Moderation(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       types.User
+		wantStatus string
+	}{
+		{"Edits of readers are moderated again", types.User{Id: "u2", Role: types.RoleUser}, types.CommentPending},
+		{"Edits of editors stay approved", types.User{Id: "u2", Role: types.RoleEditor}, types.CommentApproved},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commentRepo := new(MockCommentRepository)
+			commentRepo.On("FindById", "c1").Return(&types.Comment{Id: "c1", PostId: "p1", Author: types.User{Id: "u2"}, Status: types.CommentApproved, CreatedAt: time.Now()}, nil)
+			commentRepo.On("Update", "c1", "Edited", tt.wantStatus).Return(&types.Comment{Id: "c1", Content: "Edited", Status: tt.wantStatus}, nil).Once()
+
+			req := httptest.NewRequest("PUT", "/comments/c1", strings.NewReader(`{"content":"Edited"}`))
+			req.Header.Set("Content-Type", "application/json")
+			resp, _ := commentApp(commentRepo, new(MockPostRepository), tt.user).Test(req)
+
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			commentRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,353 @@
+package handler_test
+
+import (
+	"go-blog/internal/handler"
+	"go-blog/internal/repository"
+	"go-blog/internal/service"
+	"go-blog/internal/types"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockDuplicateService struct {
+	service.DuplicateService
+	mock.Mock
+}
+
+func (m *MockDuplicateService) FindNearDuplicates(postId string, content string, readerId string) ([]types.Duplicate, error) {
+	args := m.Called(postId, content, readerId)
+	return args.Get(0).([]types.Duplicate), args.Error(1)
+}
+
+func (m *MockPostRepository) Update(id string, post types.Post) (*types.Post, error) {
+	args := m.Called(id, post)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.Post), args.Error(1)
+}
+
+type MockSeriesRepository struct {
+	repository.SeriesRepository
+	mock.Mock
+}
+
+func (m *MockSeriesRepository) FindByPost(postId string) (*types.Series, error) {
+	args := m.Called(postId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.Series), args.Error(1)
+}
+
+type MockReactionRepository struct {
+	repository.ReactionRepository
+	mock.Mock
+}
+
+func (m *MockReactionRepository) FindUserReactions(userId string, postIds []string) (map[string]map[string]bool, error) {
+	args := m.Called(userId, postIds)
+	return args.Get(0).(map[string]map[string]bool), args.Error(1)
+}
+
+type MockViewService struct {
+	service.ViewService
+	mock.Mock
+}
+
+func (m *MockViewService) Record(postId, ip, userAgent, referrer string) {
+	m.Called(postId, ip, userAgent, referrer)
+}
+
+func (m *MockPostRepository) AssignCategoryToPost(postId string, categoryId string) error {
+	args := m.Called(postId, categoryId)
+	return args.Error(0)
+}
+
+func (m *MockPostRepository) UnassignCategoryFromPost(postId string, categoryId string) error {
+	args := m.Called(postId, categoryId)
+	return args.Error(0)
+}
+
+func (m *MockPostRepository) UpdatePostCategories(postId string, categoryIds []string) error {
+	args := m.Called(postId, categoryIds)
+	return args.Error(0)
+}
+
+// postApp serves the posts as the given user, or to anonymous readers when the user has
+// no id.
+func postApp(postRepo *MockPostRepository, reactionRepo *MockReactionRepository, user types.User) *fiber.App {
+	seriesRepo := new(MockSeriesRepository)
+	seriesRepo.On("FindByPost", mock.Anything).Return(nil, nil).Maybe()
+	viewService := new(MockViewService)
+	viewService.On("Record", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Maybe()
+	relatedPostService := new(MockRelatedPostService)
+	relatedPostService.On("Invalidate").Maybe()
+	duplicateService := new(MockDuplicateService)
+	duplicateService.On("FindNearDuplicates", mock.Anything, mock.Anything, mock.Anything).Return([]types.Duplicate{}, nil).Maybe()
+
+	postHandler := handler.NewPostHandler(postRepo, reactionRepo, seriesRepo, nil, viewService, service.NewSlugService(), relatedPostService, nil, nil, duplicateService)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		if user.Id != "" {
+			c.Locals("user", user)
+		}
+		return c.Next()
+	})
+	app.Post("/posts/bulk", postHandler.BulkPostsHandler)
+	app.Get("/posts/:slugOrId", postHandler.GetPostHandler)
+	app.Put("/posts/:id", postHandler.UpdatePostHandler)
+	app.Patch("/posts/:id", postHandler.PatchPostHandler)
+	app.Post("/posts/:postId/categories/:categoryId", postHandler.AssignCategoryToPostHandler)
+	app.Delete("/posts/:postId/categories/:categoryId", postHandler.UnassignCategoryFromPostHandler)
+	app.Put("/posts/:postId/categories", postHandler.UpdatePostCategoriesHandler)
+	return app
+}
+
+func TestGetPostHandler_ETag(t *testing.T) {
+	post := func(commentCount int) *types.Post {
+		return &types.Post{Id: "p1", Title: "Tagged", Slug: "tagged", Locale: "en", Status: types.PostStatusPublished, Visibility: types.VisibilityPublic,
+			Content: "Some content", CommentCount: commentCount, Reactions: []types.Reaction{{Type: "like", Count: 1}}, Tags: []types.Tag{{Id: "t1", Name: "Go", Slug: "go"}}, Version: 1}
+	}
+	get := func(app *fiber.App, ifNoneMatch string) (int, string) {
+		req := httptest.NewRequest("GET", "/posts/tagged", nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode, resp.Header.Get("ETag")
+	}
+
+	postRepo := new(MockPostRepository)
+	postRepo.On("FindBySlug", "tagged", "").Return(post(1), nil).Twice()
+	app := postApp(postRepo, nil, types.User{})
+
+	status, etag := get(app, "")
+	assert.Equal(t, fiber.StatusOK, status)
+	status, _ = get(app, etag)
+	assert.Equal(t, fiber.StatusNotModified, status)
+
+	// A new comment leaves the version as it is, but not the representation
+	postRepo.On("FindBySlug", "tagged", "").Return(post(2), nil).Once()
+	status, commentedEtag := get(app, etag)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.NotEqual(t, etag, commentedEtag)
+
+	// Nor does renaming one of its tags
+	renamed := post(1)
+	renamed.Tags = []types.Tag{{Id: "t1", Name: "Golang", Slug: "golang"}}
+	postRepo.On("FindBySlug", "tagged", "").Return(renamed, nil).Once()
+	status, renamedEtag := get(app, etag)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.NotEqual(t, etag, renamedEtag)
+
+	// Nor does the reader who left the reaction get the representation of anonymous readers
+	reactionRepo := new(MockReactionRepository)
+	reactionRepo.On("FindUserReactions", "u1", []string{"p1"}).Return(map[string]map[string]bool{"p1": {"like": true}}, nil).Once()
+	postRepo.On("FindBySlug", "tagged", "").Return(post(1), nil).Once()
+	status, _ = get(postApp(postRepo, reactionRepo, types.User{Id: "u1", Role: types.RoleUser}), etag)
+	assert.Equal(t, fiber.StatusOK, status)
+	postRepo.AssertExpectations(t)
+}
+
+func TestPatchPostHandler_TagsOnly(t *testing.T) {
+	author := types.User{Id: "u1", Role: types.RoleUser}
+	post := func(version int, tags []types.Tag) *types.Post {
+		return &types.Post{Id: "p1", Title: "Tagged", Slug: "tagged", Locale: "en", Status: types.PostStatusPublished, Visibility: types.VisibilityPublic,
+			Content: "Some content", Author: author, Authors: []types.PostAuthor{{Id: "u1"}}, Tags: tags, Version: version}
+	}
+
+	postRepo := new(MockPostRepository)
+	postRepo.On("FindById", "p1").Return(post(1, []types.Tag{}), nil).Once()
+	// Nothing but the tags differ, so the post itself stays at its version
+	postRepo.On("Patch", "p1", mock.Anything).Return(post(1, []types.Tag{}), nil).Once()
+	postRepo.On("UpdatePostTags", "p1", []types.Tag{{Name: "Go", Slug: "go"}}).Return([]types.Tag{{Id: "t1", Name: "Go", Slug: "go"}}, nil).Once()
+	postRepo.On("FindById", "p1").Return(post(2, []types.Tag{{Id: "t1", Name: "Go", Slug: "go"}}), nil).Once()
+
+	req := httptest.NewRequest("PATCH", "/posts/p1", strings.NewReader(`{"tags":[{"name":"Go"}]}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req.Header.Set("If-Match", `"1-en"`)
+	resp, _ := postApp(postRepo, nil, author).Test(req)
+
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	// Clients holding the tag of the untagged post must see the change
+	assert.Equal(t, `"2-en"`, resp.Header.Get("ETag"))
+	postRepo.AssertExpectations(t)
+}
+
+func TestPostHandlers_GeneratedExcerpt(t *testing.T) {
+	author := types.User{Id: "u1", Role: types.RoleUser}
+	existingPost := func(excerpt string, generated bool) *types.Post {
+		return &types.Post{Id: "p1", Title: "Excerpts", Slug: "excerpts", Locale: "en", Status: types.PostStatusPublished, Visibility: types.VisibilityPublic,
+			Content: "Old content", Excerpt: excerpt, ExcerptGenerated: generated, Author: author, Authors: []types.PostAuthor{{Id: "u1"}}, Version: 1}
+	}
+
+	tests := []struct {
+		name          string
+		method        string
+		post          *types.Post
+		body          string
+		wantExcerpt   string
+		wantGenerated bool
+	}{
+		{"Patching the content generates the excerpt again", "PATCH", existingPost("Old content", true), `{"content":"New content"}`, "New content", true},
+		{"Putting back the served excerpt generates it again", "PUT", existingPost("Old content", true), `{"title":"Excerpts","content":"New content","excerpt":"Old content"}`, "New content", true},
+		{"Given excerpts are kept when patching the content", "PATCH", existingPost("Hand written", false), `{"content":"New content"}`, "Hand written", false},
+		{"Given excerpts are kept when putting the content", "PUT", existingPost("Hand written", false), `{"title":"Excerpts","content":"New content","excerpt":"Hand written"}`, "Hand written", false},
+		{"A new excerpt replaces the generated one", "PATCH", existingPost("Old content", true), `{"content":"New content","excerpt":"Hand written"}`, "Hand written", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saved := mock.MatchedBy(func(post types.Post) bool {
+				return post.Excerpt == tt.wantExcerpt && post.ExcerptGenerated == tt.wantGenerated
+			})
+
+			postRepo := new(MockPostRepository)
+			postRepo.On("FindById", "p1").Return(tt.post, nil)
+			if tt.method == "PUT" {
+				postRepo.On("Update", "p1", saved).Return(tt.post, nil).Once()
+			} else {
+				postRepo.On("Patch", "p1", saved).Return(tt.post, nil).Once()
+			}
+
+			req := httptest.NewRequest(tt.method, "/posts/p1", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.method == "PATCH" {
+				req.Header.Set("Content-Type", "application/merge-patch+json")
+			}
+			resp, _ := postApp(postRepo, nil, author).Test(req)
+
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			postRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPostHandlers_ApprovedPostChanges(t *testing.T) {
+	author := types.User{Id: "u1", Role: types.RoleUser}
+	editor := types.User{Id: "u1", Role: types.RoleEditor}
+	approvedPost := func() *types.Post {
+		return &types.Post{Id: "p1", Title: "Reviewed", Slug: "reviewed", Locale: "en", Status: types.PostStatusApproved, Visibility: types.VisibilityPublic,
+			Content: "Reviewed content", Excerpt: "Reviewed content", ExcerptGenerated: true, Author: author, Authors: []types.PostAuthor{{Id: "u1"}}, Version: 1}
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		user       types.User
+		body       string
+		wantStatus string
+	}{
+		{"Publishing unreviewed content in a patch", "PATCH", author, `{"status":"published","content":"Unreviewed content"}`, types.PostStatusInReview},
+		{"Publishing unreviewed content in a put", "PUT", author, `{"title":"Reviewed","status":"published","content":"Unreviewed content"}`, types.PostStatusInReview},
+		{"Changing the title of an approved post", "PATCH", author, `{"title":"Unreviewed"}`, types.PostStatusInReview},
+		{"Publishing the reviewed content", "PATCH", author, `{"status":"published"}`, types.PostStatusPublished},
+		{"Publishing the reviewed content in a put", "PUT", author, `{"title":"Reviewed","status":"published","content":"Reviewed content","excerpt":"Reviewed content"}`, types.PostStatusPublished},
+		{"Taking back unreviewed content to draft", "PATCH", author, `{"status":"draft","content":"Unreviewed content"}`, types.PostStatusDraft},
+		{"Editors publish their changes", "PATCH", editor, `{"status":"published","content":"Edited content"}`, types.PostStatusPublished},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saved := mock.MatchedBy(func(post types.Post) bool {
+				return post.Status == tt.wantStatus
+			})
+
+			postRepo := new(MockPostRepository)
+			postRepo.On("FindById", "p1").Return(approvedPost(), nil)
+			if tt.method == "PUT" {
+				postRepo.On("Update", "p1", saved).Return(approvedPost(), nil).Once()
+			} else {
+				postRepo.On("Patch", "p1", saved).Return(approvedPost(), nil).Once()
+			}
+
+			req := httptest.NewRequest(tt.method, "/posts/p1", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.method == "PATCH" {
+				req.Header.Set("Content-Type", "application/merge-patch+json")
+			}
+			resp, _ := postApp(postRepo, nil, tt.user).Test(req)
+
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			postRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPostCategoriesHandlers_Permissions(t *testing.T) {
+	author := types.User{Id: "u1", Role: types.RoleUser}
+	other := types.User{Id: "u2", Role: types.RoleUser}
+	editor := types.User{Id: "u3", Role: types.RoleEditor}
+	post := &types.Post{Id: "p1", Title: "Categorized", Slug: "categorized", Status: types.PostStatusDraft, Author: author, Authors: []types.PostAuthor{{Id: "u1"}}}
+
+	requests := []struct {
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"POST", "/posts/p1/categories/c1", "", fiber.StatusCreated},
+		{"DELETE", "/posts/p1/categories/c1", "", fiber.StatusNoContent},
+		{"PUT", "/posts/p1/categories", `{"categoryIds":["c1"]}`, fiber.StatusOK},
+	}
+	users := []struct {
+		name    string
+		user    types.User
+		allowed bool
+	}{
+		{"Authors change the categories of their posts", author, true},
+		{"Editors change the categories of any post", editor, true},
+		{"Other users may not change them", other, false},
+	}
+
+	for _, tu := range users {
+		for _, tr := range requests {
+			t.Run(tu.name+" "+tr.method, func(t *testing.T) {
+				postRepo := new(MockPostRepository)
+				postRepo.On("FindById", "p1").Return(post, nil)
+				postRepo.On("AssignCategoryToPost", "p1", "c1").Return(nil).Maybe()
+				postRepo.On("UnassignCategoryFromPost", "p1", "c1").Return(nil).Maybe()
+				postRepo.On("UpdatePostCategories", "p1", []string{"c1"}).Return(nil).Maybe()
+
+				req := httptest.NewRequest(tr.method, tr.path, strings.NewReader(tr.body))
+				req.Header.Set("Content-Type", "application/json")
+				resp, _ := postApp(postRepo, nil, tu.user).Test(req)
+
+				if tu.allowed {
+					assert.Equal(t, tr.status, resp.StatusCode)
+				} else {
+					assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+					postRepo.AssertNotCalled(t, "AssignCategoryToPost", mock.Anything, mock.Anything)
+					postRepo.AssertNotCalled(t, "UnassignCategoryFromPost", mock.Anything, mock.Anything)
+					postRepo.AssertNotCalled(t, "UpdatePostCategories", mock.Anything, mock.Anything)
+				}
+			})
+		}
+	}
+}
+
+func TestBulkPostsHandler_EmptyFilter(t *testing.T) {
+	editor := types.User{Id: "u1", Role: types.RoleEditor}
+	postRepo := new(MockPostRepository)
+
+	// An empty filter would match every post
+	req := httptest.NewRequest("POST", "/posts/bulk", strings.NewReader(`{"action":"delete","filter":{}}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := postApp(postRepo, nil, editor).Test(req)
+
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	assert.JSONEq(t, `{"error":"Validation failed","fails":{"Filter":"required"}}`, string(body))
+	postRepo.AssertNotCalled(t, "FindByFilter", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,175 @@
+package handler_test
+
+import (
+	"go-blog/internal/handler"
+	"go-blog/internal/repository"
+	"go-blog/internal/service"
+	"go-blog/internal/types"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPostRepository struct {
+	repository.PostRepository
+	mock.Mock
+}
+
+func (m *MockPostRepository) FindById(id string) (*types.Post, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.Post), args.Error(1)
+}
+
+func (m *MockPostRepository) FindBySlug(slug string, locale string) (*types.Post, error) {
+	args := m.Called(slug, locale)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.Post), args.Error(1)
+}
+
+func (m *MockPostRepository) Patch(id string, post types.Post) (*types.Post, error) {
+	args := m.Called(id, post)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.Post), args.Error(1)
+}
+
+func (m *MockPostRepository) UpdatePostTags(postId string, tags []types.Tag) ([]types.Tag, error) {
+	args := m.Called(postId, tags)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.Tag), args.Error(1)
+}
+
+type MockReviewRepository struct {
+	mock.Mock
+}
+
+func (m *MockReviewRepository) FindByPost(postId string) ([]types.Review, error) {
+	args := m.Called(postId)
+	return args.Get(0).([]types.Review), args.Error(1)
+}
+
+func (m *MockReviewRepository) Create(review types.Review) (*types.Review, error) {
+	args := m.Called(review)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.Review), args.Error(1)
+}
+
+func (m *MockReviewRepository) CreateWithStatus(review types.Review, version int) (*types.Review, error) {
+	args := m.Called(review, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.Review), args.Error(1)
+}
+
+type MockRelatedPostService struct {
+	service.RelatedPostService
+	mock.Mock
+}
+
+func (m *MockRelatedPostService) Invalidate() {
+	m.Called()
+}
+
+// reviewApp serves the review of the posts as the given user.
+func reviewApp(reviewRepo *MockReviewRepository, postRepo *MockPostRepository, user types.User) *fiber.App {
+	reviewHandler := handler.NewReviewHandler(reviewRepo, postRepo, new(MockUserRepository), new(MockRelatedPostService))
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user", user)
+		return c.Next()
+	})
+	app.Post("/posts/:id/reviews", reviewHandler.CreateReviewHandler)
+	return app
+}
+
+func TestCreateReviewHandler(t *testing.T) {
+	author := types.User{Id: "u1", Role: types.RoleUser}
+	editor := types.User{Id: "u2", Role: types.RoleEditor}
+	submittedPost := func() *types.Post {
+		return &types.Post{Id: "p1", Status: types.PostStatusInReview, Author: types.User{Id: "u1"}, Version: 2}
+	}
+
+	t.Run("Authors can't approve their posts", func(t *testing.T) {
+		reviewRepo, postRepo := new(MockReviewRepository), new(MockPostRepository)
+		postRepo.On("FindById", "p1").Return(submittedPost(), nil)
+
+		req := httptest.NewRequest("POST", "/posts/p1/reviews", strings.NewReader(`{"status":"approved"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := reviewApp(reviewRepo, postRepo, author).Test(req)
+
+		assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+		reviewRepo.AssertNotCalled(t, "CreateWithStatus", mock.Anything, mock.Anything)
+		reviewRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Editors request changes with a comment", func(t *testing.T) {
+		reviewRepo, postRepo := new(MockReviewRepository), new(MockPostRepository)
+		postRepo.On("FindById", "p1").Return(submittedPost(), nil)
+		reviewRepo.On("CreateWithStatus", types.Review{PostId: "p1", Status: types.PostStatusChangesRequested, Comment: "Shorten the intro", Author: editor}, 2).
+			Return(&types.Review{Id: "r1"}, nil).Once()
+
+		req := httptest.NewRequest("POST", "/posts/p1/reviews", strings.NewReader(`{"status":"changes_requested","comment":"Shorten the intro"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := reviewApp(reviewRepo, postRepo, editor).Test(req)
+
+		assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		postRepo.AssertExpectations(t)
+		reviewRepo.AssertExpectations(t)
+	})
+
+	t.Run("Comments leave the post where it is", func(t *testing.T) {
+		reviewRepo, postRepo := new(MockReviewRepository), new(MockPostRepository)
+		postRepo.On("FindById", "p1").Return(submittedPost(), nil)
+		reviewRepo.On("Create", types.Review{PostId: "p1", Status: types.PostStatusInReview, Comment: "Ready when you are", Author: author}).
+			Return(&types.Review{Id: "r2"}, nil).Once()
+
+		req := httptest.NewRequest("POST", "/posts/p1/reviews", strings.NewReader(`{"comment":"Ready when you are"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := reviewApp(reviewRepo, postRepo, author).Test(req)
+
+		assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		reviewRepo.AssertNotCalled(t, "CreateWithStatus", mock.Anything, mock.Anything)
+		reviewRepo.AssertExpectations(t)
+	})
+
+	t.Run("The status doesn't change over a newer version", func(t *testing.T) {
+		reviewRepo, postRepo := new(MockReviewRepository), new(MockPostRepository)
+		postRepo.On("FindById", "p1").Return(submittedPost(), nil)
+		reviewRepo.On("CreateWithStatus", mock.Anything, 2).Return(nil, repository.ErrVersionConflict).Once()
+
+		req := httptest.NewRequest("POST", "/posts/p1/reviews", strings.NewReader(`{"status":"approved"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := reviewApp(reviewRepo, postRepo, editor).Test(req)
+
+		assert.Equal(t, fiber.StatusPreconditionFailed, resp.StatusCode)
+		reviewRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Other users can't review the post", func(t *testing.T) {
+		reviewRepo, postRepo := new(MockReviewRepository), new(MockPostRepository)
+		postRepo.On("FindById", "p1").Return(submittedPost(), nil)
+
+		req := httptest.NewRequest("POST", "/posts/p1/reviews", strings.NewReader(`{"comment":"Nice"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := reviewApp(reviewRepo, postRepo, types.User{Id: "u3", Role: types.RoleUser}).Test(req)
+
+		assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+		reviewRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+}
@@ -0,0 +1,71 @@
+package handler_test
+
+import (
+	"go-blog/internal/handler"
+	"go-blog/internal/repository"
+	"go-blog/internal/service"
+	"go-blog/internal/types"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTagRepository struct {
+	repository.TagRepository
+	mock.Mock
+}
+
+func (m *MockTagRepository) FindById(id string) (*types.Tag, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.Tag), args.Error(1)
+}
+
+func (m *MockTagRepository) Merge(sourceId string, targetId string) error {
+	return m.Called(sourceId, targetId).Error(0)
+}
+
+func tagApp(tagRepo *MockTagRepository) *fiber.App {
+	relatedPostService := new(MockRelatedPostService)
+	relatedPostService.On("Invalidate").Maybe()
+	tagHandler := handler.NewTagHandler(tagRepo, new(MockPostRepository), service.NewSlugService(), relatedPostService, nil)
+
+	app := fiber.New()
+	app.Post("/tags/:id/merge", tagHandler.MergeTagHandler)
+	return app
+}
+
+func TestMergeTagHandler(t *testing.T) {
+	merge := func(tagRepo *MockTagRepository, targetId string) int {
+		req := httptest.NewRequest("POST", "/tags/t1/merge", strings.NewReader(`{"targetId":"`+targetId+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := tagApp(tagRepo).Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode
+	}
+
+	t.Run("Into another tag", func(t *testing.T) {
+		tagRepo := new(MockTagRepository)
+		tagRepo.On("FindById", "t1").Return(&types.Tag{Id: "t1", Name: "golang", Slug: "golang"}, nil)
+		tagRepo.On("FindById", "t2").Return(&types.Tag{Id: "t2", Name: "Go", Slug: "go"}, nil)
+		tagRepo.On("Merge", "t1", "t2").Return(nil).Once()
+
+		assert.Equal(t, fiber.StatusOK, merge(tagRepo, "t2"))
+		tagRepo.AssertExpectations(t)
+	})
+
+	t.Run("Into itself", func(t *testing.T) {
+		tagRepo := new(MockTagRepository)
+
+		assert.Equal(t, fiber.StatusBadRequest, merge(tagRepo, "t1"))
+		tagRepo.AssertNotCalled(t, "Merge", mock.Anything, mock.Anything)
+	})
+}
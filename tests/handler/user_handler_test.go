@@ -6,6 +6,7 @@ import (
 	"go-blog/internal/handler"
 	"go-blog/internal/types"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gofiber/fiber/v2"
@@ -45,6 +46,11 @@ func (m *MockUserRepository) Update(id string, user types.User) (*types.User, er
 	return args.Get(0).(*types.User), args.Error(1)
 }
 
+func (m *MockUserRepository) Patch(id string, user types.User) (*types.User, error) {
+	args := m.Called(id, user)
+	return args.Get(0).(*types.User), args.Error(1)
+}
+
 func (m *MockUserRepository) Delete(id string) error {
 	args := m.Called(id)
 	return args.Error(0)
@@ -144,3 +150,86 @@ func TestGetUserHandler(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 }
+
+func TestPatchMeHandler(t *testing.T) {
+	newApp := func(mockRepo *MockUserRepository) *fiber.App {
+		app := fiber.New()
+		app.Patch("/users/me", func(c *fiber.Ctx) error {
+			c.Locals("user", types.User{Id: "1"})
+			return c.Next()
+		}, handler.NewUserHandler(mockRepo).PatchMeHandler)
+		return app
+	}
+
+	t.Run("Patch only the lastname", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+
+		existing := &types.User{Id: "1", Name: "John", Lastname: "Doe", Email: "john@example.com", Role: types.RoleUser}
+		patched := types.User{Id: "1", Name: "John", Lastname: "Roe", Email: "john@example.com", Role: types.RoleUser}
+
+		mockRepo.On("FindById", "1").Return(existing, nil)
+		mockRepo.On("Patch", "1", patched).Return(&patched, nil)
+
+		req := httptest.NewRequest("PATCH", "/users/me", strings.NewReader(`{"lastname":"Roe"}`))
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		resp, _ := newApp(mockRepo).Test(req)
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var result types.User
+		json.NewDecoder(resp.Body).Decode(&result)
+
+		assert.Equal(t, "John", result.Name)
+		assert.Equal(t, "Roe", result.Lastname)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Removing a required member fails validation", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+
+		mockRepo.On("FindById", "1").Return(&types.User{Id: "1", Name: "John", Email: "john@example.com"}, nil)
+
+		req := httptest.NewRequest("PATCH", "/users/me", strings.NewReader(`{"name":null}`))
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		resp, _ := newApp(mockRepo).Test(req)
+
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+		var result map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&result)
+
+		assert.Equal(t, "Validation failed", result["error"])
+		assert.Equal(t, map[string]interface{}{"Name": "required"}, result["fails"])
+		mockRepo.AssertNotCalled(t, "Patch", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Read-only members can't be changed", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+
+		mockRepo.On("FindById", "1").Return(&types.User{Id: "1", Name: "John", Email: "john@example.com", Role: types.RoleUser}, nil)
+
+		req := httptest.NewRequest("PATCH", "/users/me", strings.NewReader(`{"role":"admin"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := newApp(mockRepo).Test(req)
+
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+		var result map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&result)
+
+		assert.Equal(t, map[string]interface{}{"role": "readonly"}, result["fails"])
+		mockRepo.AssertNotCalled(t, "Patch", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Other media types are refused", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+
+		mockRepo.On("FindById", "1").Return(&types.User{Id: "1", Name: "John", Email: "john@example.com"}, nil)
+
+		req := httptest.NewRequest("PATCH", "/users/me", strings.NewReader(`[{"op":"replace","path":"/name","value":"Jack"}]`))
+		req.Header.Set("Content-Type", "application/json-patch+json")
+		resp, _ := newApp(mockRepo).Test(req)
+
+		assert.Equal(t, fiber.StatusUnsupportedMediaType, resp.StatusCode)
+	})
+}
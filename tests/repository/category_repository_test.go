@@ -0,0 +1,230 @@
+package repository_test
+
+import (
+	"go-blog/internal/repository"
+	"go-blog/internal/types"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCategoryRepository_FindAll(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewCategoryRepository(db)
+
+	rows := sqlmock.NewRows([]string{"id", "title", "slug", "parent_id", "created_at", "version", "updated_at"}).
+		AddRow("1", "Category 1", "category-1", nil, time.Now(), 1, time.Now()).
+		AddRow("2", "Category 2", "category-2", nil, time.Now(), 1, time.Now())
+
+	mock.ExpectQuery("SELECT id, title, slug, parent_id, created_at, version, updated_at FROM categories").WillReturnRows(rows)
+
+	categories, err := repo.FindAll()
+
+	assert.NoError(t, err)
+	assert.Len(t, categories, 2)
+	assert.Equal(t, "Category 1", categories[0].Title)
+	assert.Equal(t, "category-2", categories[1].Slug)
+}
+
+func TestCategoryRepository_FindBySlug(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewCategoryRepository(db)
+
+	rows := sqlmock.NewRows([]string{"id", "title", "slug", "parent_id", "created_at", "version", "updated_at"}).
+		AddRow("1", "Category 1", "category-1", nil, time.Now(), 1, time.Now())
+
+	mock.ExpectQuery("SELECT id, title, slug, parent_id, created_at, version, updated_at FROM categories WHERE").
+		WithArgs("category-1").
+		WillReturnRows(rows)
+
+	category, err := repo.FindBySlug("category-1")
+
+	assert.NoError(t, err)
+	assert.NotNil(t, category)
+	assert.Equal(t, "Category 1", category.Title)
+}
+
+func TestCategoryRepository_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewCategoryRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SELECT pg_advisory_xact_lock").WithArgs("categories.slug").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT slug FROM categories WHERE (.+) UNION SELECT slug FROM category_slug_history").WillReturnRows(sqlmock.NewRows([]string{"slug"}))
+
+	rows := sqlmock.NewRows([]string{"id", "title", "slug", "parent_id", "created_at", "version", "updated_at"}).
+		AddRow("1", "New Category", "new-category", nil, time.Now(), 1, time.Now())
+
+	mock.ExpectQuery("INSERT INTO categories").
+		WithArgs("New Category", "new-category", nil).
+		WillReturnRows(rows)
+	mock.ExpectCommit()
+
+	newCategory := types.Category{
+		Title: "New Category",
+		Slug:  "new-category",
+	}
+
+	createdCategory, err := repo.Create(newCategory)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, createdCategory)
+	assert.Equal(t, "New Category", createdCategory.Title)
+	assert.Equal(t, "new-category", createdCategory.Slug)
+}
+
+func TestCategoryRepository_Update(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewCategoryRepository(db)
+
+	mock.ExpectQuery("SELECT id, title, slug, parent_id, created_at, version, updated_at FROM categories WHERE").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "slug", "parent_id", "created_at", "version", "updated_at"}).AddRow("1", "Category", "category", nil, time.Now(), 1, time.Now()))
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SELECT pg_advisory_xact_lock").WithArgs("categories.slug").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT slug FROM categories WHERE (.+) UNION SELECT slug FROM category_slug_history").WillReturnRows(sqlmock.NewRows([]string{"slug"}))
+
+	rows := sqlmock.NewRows([]string{"id", "title", "slug", "parent_id", "created_at", "version", "updated_at"}).
+		AddRow("1", "Updated Category", "updated-category", nil, time.Now(), 1, time.Now())
+
+	mock.ExpectQuery("UPDATE categories").
+		WithArgs("Updated Category", "updated-category", nil, "1", 1).
+		WillReturnRows(rows)
+	mock.ExpectExec("INSERT INTO category_slug_history").
+		WithArgs("category", "1").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("DELETE FROM category_slug_history").
+		WithArgs("updated-category").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	updatedCategory := types.Category{
+		Title: "Updated Category",
+		Slug:  "updated-category",
+	}
+
+	result, err := repo.Update("1", updatedCategory)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Equal(t, "Updated Category", result.Title)
+	assert.Equal(t, "updated-category", result.Slug)
+}
+
+func TestCategoryRepository_Patch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewCategoryRepository(db)
+
+	// A new title alone leaves the slug untouched
+	mock.ExpectQuery("SELECT id, title, slug, parent_id, created_at, version, updated_at FROM categories WHERE").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "slug", "parent_id", "created_at", "version", "updated_at"}).AddRow("1", "Category", "category", nil, time.Now(), 1, time.Now()))
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE categories SET title = \\$1, version = version \\+ 1, updated_at = CURRENT_TIMESTAMP WHERE id = \\$2 AND version = \\$3").
+		WithArgs("Renamed Category", "1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "slug", "parent_id", "created_at", "version", "updated_at"}).AddRow("1", "Renamed Category", "category", nil, time.Now(), 2, time.Now()))
+	mock.ExpectCommit()
+
+	patchedCategory, err := repo.Patch("1", types.Category{Title: "Renamed Category", Slug: "category"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Renamed Category", patchedCategory.Title)
+	assert.Equal(t, "category", patchedCategory.Slug)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCategoryRepository_PatchParentCycle(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewCategoryRepository(db)
+
+	// Category 2 is a child of category 1, so it can't become its parent
+	mock.ExpectQuery("SELECT id, title, slug, parent_id, created_at, version, updated_at FROM categories WHERE").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "slug", "parent_id", "created_at", "version", "updated_at"}).AddRow("1", "Category", "category", nil, time.Now(), 1, time.Now()))
+	mock.ExpectBegin()
+	mock.ExpectQuery("WITH RECURSIVE ancestors").
+		WithArgs("2", "1").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+
+	parentId := "2"
+	_, err = repo.Patch("1", types.Category{Title: "Category", Slug: "category", ParentId: &parentId})
+
+	assert.ErrorIs(t, err, repository.ErrCategoryCycle)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCategoryRepository_Delete(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewCategoryRepository(db)
+
+	mock.ExpectExec("UPDATE categories SET deleted_at = CURRENT_TIMESTAMP").
+		WithArgs("1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.Delete("1")
+
+	assert.NoError(t, err)
+}
+
+func TestCategoryRepository_FindById(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewCategoryRepository(db)
+
+	rows := sqlmock.NewRows([]string{"id", "title", "slug", "parent_id", "created_at", "version", "updated_at"}).
+		AddRow("1", "Category 1", "category-1", nil, time.Now(), 1, time.Now())
+
+	mock.ExpectQuery("SELECT id, title, slug, parent_id, created_at, version, updated_at FROM categories WHERE").
+		WithArgs("1").
+		WillReturnRows(rows)
+
+	category, err := repo.FindById("1")
+
+	assert.NoError(t, err)
+	assert.NotNil(t, category)
+	assert.Equal(t, "Category 1", category.Title)
+	assert.Equal(t, "category-1", category.Slug)
+}
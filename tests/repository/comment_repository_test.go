@@ -0,0 +1,134 @@
+package repository_test
+
+import (
+	"go-blog/internal/repository"
+	"go-blog/internal/types"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var commentColumns = []string{"id", "post_id", "parent_id", "content", "status", "created_at", "updated_at", "user_id", "name", "lastname"}
+
+func TestCommentRepository_FindByPost(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewCommentRepository(db)
+
+	rows := sqlmock.NewRows(commentColumns).
+		AddRow("1", "10", nil, "First!", "approved", time.Now(), time.Now(), "1", "John", "Doe").
+		AddRow("2", "10", "1", "Reply", "approved", time.Now(), time.Now(), "2", "Jane", "Doe")
+
+	mock.ExpectQuery("SELECT comments.id, (.+) FROM comments JOIN users ON comments.user_id = users.id WHERE comments.post_id = \\$1 AND comments.status IN \\(\\$2,\\$3\\) ORDER BY comments.created_at ASC").
+		WithArgs("10", "approved", "deleted").
+		WillReturnRows(rows)
+
+	comments, err := repo.FindByPost("10", []string{types.CommentApproved, types.CommentDeleted})
+
+	assert.NoError(t, err)
+	assert.Len(t, comments, 2)
+	assert.Equal(t, "", comments[0].ParentId)
+	assert.Equal(t, "1", comments[1].ParentId)
+	assert.Equal(t, "Jane", comments[1].Author.Name)
+}
+
+func TestCommentRepository_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewCommentRepository(db)
+
+	t.Run("Top level comment", func(t *testing.T) {
+		mock.ExpectQuery("INSERT INTO comments").
+			WithArgs("10", "1", nil, "Nice post", "pending").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow("1", time.Now(), time.Now()))
+
+		comment, err := repo.Create(types.Comment{PostId: "10", Author: types.User{Id: "1"}, Content: "Nice post", Status: types.CommentPending})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "1", comment.Id)
+		assert.Equal(t, "pending", comment.Status)
+	})
+
+	t.Run("Reply", func(t *testing.T) {
+		mock.ExpectQuery("INSERT INTO comments").
+			WithArgs("10", "2", "1", "Thanks", "approved").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow("2", time.Now(), time.Now()))
+
+		comment, err := repo.Create(types.Comment{PostId: "10", ParentId: "1", Author: types.User{Id: "2"}, Content: "Thanks", Status: types.CommentApproved})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "2", comment.Id)
+		assert.Equal(t, "1", comment.ParentId)
+	})
+}
+
+func TestCommentRepository_UpdateStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewCommentRepository(db)
+
+	t.Run("Updated", func(t *testing.T) {
+		mock.ExpectExec("UPDATE comments SET status = \\$1, updated_at = CURRENT_TIMESTAMP WHERE id = \\$2").
+			WithArgs("spam", "1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("SELECT comments.id, (.+) FROM comments").
+			WithArgs("1").
+			WillReturnRows(sqlmock.NewRows(commentColumns).
+				AddRow("1", "10", nil, "Buy now", "spam", time.Now(), time.Now(), "1", "John", "Doe"))
+
+		comment, err := repo.UpdateStatus("1", types.CommentSpam)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "spam", comment.Status)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		mock.ExpectExec("UPDATE comments").
+			WithArgs("approved", "404").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		_, err := repo.UpdateStatus("404", types.CommentApproved)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "no comment found with id 404")
+	})
+}
+
+func TestCommentRepository_Update(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewCommentRepository(db)
+
+	mock.ExpectExec("UPDATE comments SET content = \\$1, status = \\$2, updated_at = CURRENT_TIMESTAMP WHERE id = \\$3").
+		WithArgs("Edited", "pending", "1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT comments.id, (.+) FROM comments").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows(commentColumns).
+			AddRow("1", "10", nil, "Edited", "pending", time.Now(), time.Now(), "1", "John", "Doe"))
+
+	comment, err := repo.Update("1", "Edited", types.CommentPending)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Edited", comment.Content)
+	assert.Equal(t, "pending", comment.Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
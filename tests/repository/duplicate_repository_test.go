@@ -0,0 +1,81 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"go-blog/internal/repository"
+)
+
+func TestDuplicateRepository_FindNearDuplicates(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewDuplicateRepository(db)
+
+	// Fingerprints with the high bit set are negative BIGINTs
+	fingerprint := uint64(1<<63 | 0b1010)
+	mock.ExpectQuery("SELECT posts.id, posts.title, posts.slug, posts.status, posts.created_at, posts.content_fingerprint FROM posts "+
+		"WHERE posts.deleted_at IS NULL AND posts.id <> \\$1 AND bit_count\\(\\(posts.content_fingerprint # \\$2\\)::bit\\(64\\)\\) <= \\$3 "+
+		"ORDER BY bit_count\\(\\(posts.content_fingerprint # \\$4\\)::bit\\(64\\)\\) ASC, posts.created_at ASC").
+		WithArgs("p1", int64(fingerprint), 6, int64(fingerprint)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "slug", "status", "created_at", "content_fingerprint"}).
+			AddRow("p2", "Bike lanes", "bike-lanes", "published", time.Now(), int64(fingerprint^1)))
+
+	duplicates, err := repo.FindNearDuplicates("p1", fingerprint, 6, "")
+
+	assert.NoError(t, err)
+	assert.Len(t, duplicates, 1)
+	assert.Equal(t, "bike-lanes", duplicates[0].Slug)
+	assert.Equal(t, fingerprint^1, duplicates[0].Fingerprint)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDuplicateRepository_FindNearDuplicatesReadable(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewDuplicateRepository(db)
+
+	// Readers other than editors only learn of the posts they may read
+	mock.ExpectQuery("SELECT posts.id, posts.title, posts.slug, posts.status, posts.created_at, posts.content_fingerprint FROM posts "+
+		"WHERE posts.deleted_at IS NULL AND posts.id <> \\$1 AND bit_count\\(\\(posts.content_fingerprint # \\$2\\)::bit\\(64\\)\\) <= \\$3 "+
+		"AND \\(\\(posts.status = 'published' AND posts.visibility <> 'unlisted'\\) OR EXISTS\\(SELECT 1 FROM post_authors pa WHERE pa.post_id = posts.id AND pa.user_id = \\$4\\)\\) "+
+		"ORDER BY bit_count\\(\\(posts.content_fingerprint # \\$5\\)::bit\\(64\\)\\) ASC, posts.created_at ASC").
+		WithArgs("p1", int64(42), 6, "u1", int64(42)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "slug", "status", "created_at", "content_fingerprint"}).
+			AddRow("p2", "My draft", "my-draft", "draft", time.Now(), int64(43)))
+
+	duplicates, err := repo.FindNearDuplicates("p1", 42, 6, "u1")
+
+	assert.NoError(t, err)
+	assert.Len(t, duplicates, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDuplicateRepository_SaveFingerprint(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewDuplicateRepository(db)
+
+	// The post itself doesn't change, so neither does its version
+	mock.ExpectExec("UPDATE posts SET content_fingerprint = \\$1 WHERE id = \\$2").
+		WithArgs(int64(42), "p1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.SaveFingerprint("p1", 42))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
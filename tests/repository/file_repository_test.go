@@ -0,0 +1,74 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"go-blog/internal/repository"
+	"go-blog/internal/types"
+)
+
+var fileColumns = []string{"id", "user_id", "filename", "content_type", "size", "width", "height", "created_at"}
+
+func TestFileRepository_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewFileRepository(db)
+
+	mock.ExpectQuery("INSERT INTO files").
+		WithArgs("1", "cover.png", "image/png", int64(2048), 1200, 630).
+		WillReturnRows(sqlmock.NewRows(fileColumns).AddRow("f1", "1", "cover.png", "image/png", 2048, 1200, 630, time.Now()))
+
+	file, err := repo.Create(types.File{UserId: "1", Filename: "cover.png", ContentType: "image/png", Size: 2048, Width: 1200, Height: 630})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "f1", file.Id)
+	assert.Equal(t, "/uploads/1/cover.png", file.Url)
+	assert.True(t, file.IsImage())
+}
+
+func TestFileRepository_FindByFilename(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewFileRepository(db)
+
+	mock.ExpectQuery("SELECT (.+) FROM files WHERE filename = \\$1 AND user_id = \\$2").
+		WithArgs("notes.txt", "1").
+		WillReturnRows(sqlmock.NewRows(fileColumns).AddRow("f2", "1", "notes.txt", "text/plain", 12, 0, 0, time.Now()))
+
+	file, err := repo.FindByFilename("1", "notes.txt")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "f2", file.Id)
+	assert.False(t, file.IsImage())
+}
+
+func TestFileRepository_CountPostsUsing(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewFileRepository(db)
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM posts WHERE cover_image_id = \\$1").
+		WithArgs("f1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+	count, err := repo.CountPostsUsing("f1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
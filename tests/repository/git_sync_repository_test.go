@@ -0,0 +1,71 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"go-blog/internal/repository"
+	"go-blog/internal/types"
+)
+
+func TestGitSyncRepository_FindAll(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewGitSyncRepository(db)
+
+	syncedAt := time.Now()
+	mock.ExpectQuery("SELECT path, post_id, blob_sha, commit_sha, synced_at FROM git_synced_posts ORDER BY path").
+		WillReturnRows(sqlmock.NewRows([]string{"path", "post_id", "blob_sha", "commit_sha", "synced_at"}).
+			AddRow("posts/hello.md", "p1", "b1", "c1", syncedAt))
+
+	syncedPosts, err := repo.FindAll()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []types.GitSyncedPost{{Path: "posts/hello.md", PostId: "p1", BlobSha: "b1", CommitSha: "c1", SyncedAt: syncedAt}}, syncedPosts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGitSyncRepository_Save(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewGitSyncRepository(db)
+
+	mock.ExpectExec("INSERT INTO git_synced_posts (.+) ON CONFLICT \\(path\\) DO UPDATE").
+		WithArgs("posts/hello.md", "p1", "b2", "c2").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.Save(types.GitSyncedPost{Path: "posts/hello.md", PostId: "p1", BlobSha: "b2", CommitSha: "c2"})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGitSyncRepository_Delete(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewGitSyncRepository(db)
+
+	mock.ExpectExec("DELETE FROM git_synced_posts WHERE path = \\$1").
+		WithArgs("posts/hello.md").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.Delete("posts/hello.md")
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
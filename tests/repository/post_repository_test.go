@@ -2,18 +2,47 @@ package repository_test
 
 import (
 	"database/sql"
+	"database/sql/driver"
 	"errors"
-	"fmt"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
 
 	"go-blog/internal/repository"
 	"go-blog/internal/types"
 )
 
+var postColumns = []string{"id", "title", "slug", "content", "excerpt", "word_count", "reading_time_minutes", "meta_title", "meta_description", "canonical_url", "og_image", "noindex", "created_at", "user_id", "name", "lastname", "email", "categories", "tags", "comment_count", "reactions", "authors", "cover_image", "deleted_at", "locale", "translations", "version", "updated_at", "status", "visibility", "password_hash", "reviewer_id", "excerpt_generated"}
+
+// expectTakenSlugs expects the slug lock of table and returns slugs as already taken.
+func expectTakenSlugs(mock sqlmock.Sqlmock, table string, slugs ...string) {
+	mock.ExpectExec("SELECT pg_advisory_xact_lock\\(hashtext\\(\\$1\\)\\)").
+		WithArgs(table + ".slug").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	rows := sqlmock.NewRows([]string{"slug"})
+	for _, slug := range slugs {
+		rows.AddRow(slug)
+	}
+	mock.ExpectQuery("SELECT slug FROM " + table + " WHERE (.+) UNION SELECT slug FROM (.+)_slug_history").
+		WillReturnRows(rows)
+}
+
+// expectVersionBump expects the version of the row of table to go up by one.
+func expectVersionBump(mock sqlmock.Sqlmock, table, id string) {
+	mock.ExpectExec("UPDATE " + table + " SET version = version \\+ 1, updated_at = CURRENT_TIMESTAMP WHERE id = \\$1").
+		WithArgs(id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+// postRow returns a row shaped like the ones selected by the post repository.
+func postRow(id, title, slug, content, categories string) []driver.Value {
+	return []driver.Value{id, title, slug, content, content, 1, 1, "", "", "", "", false, time.Now(), "1", "John", "Doe", "john@example.com", categories, "[]", 0, "[]", `[{"id":"1","name":"John","lastname":"Doe","email":"john@example.com","role":"author"}]`, nil, nil, "en", "[]", 1, time.Now(), "published", "public", nil, nil, false}
+}
+
 func TestPostRepository_FindAll(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -23,12 +52,11 @@ func TestPostRepository_FindAll(t *testing.T) {
 
 	repo := repository.NewPostRepository(db)
 
-	rows := sqlmock.NewRows([]string{"id", "title", "slug", "content", "created_at", "user_id", "name", "lastname", "email", "category_id", "category_title", "category_slug", "category_created_at"}).
-		AddRow("1", "Test Post", "test-post", "Content", time.Now(), "1", "John", "Doe", "john@example.com", "1", "Category 1", "category-1", time.Now()).
-		AddRow("1", "Test Post", "test-post", "Content", time.Now(), "1", "John", "Doe", "john@example.com", "2", "Category 2", "category-2", time.Now()).
-		AddRow("2", "Another Post", "another-post", "More Content", time.Now(), "2", "Jane", "Doe", "jane@example.com", nil, nil, nil, nil)
+	rows := sqlmock.NewRows(postColumns).
+		AddRow(postRow("1", "Test Post", "test-post", "Content", `[{"id":"1","title":"Category 1","slug":"category-1","createdAt":"2024-09-13T20:26:54+00:00"},{"id":"2","title":"Category 2","slug":"category-2","createdAt":"2024-09-13T20:26:54+00:00"}]`)...).
+		AddRow(postRow("2", "Another Post", "another-post", "More Content", "[]")...)
 
-	mock.ExpectQuery("SELECT DISTINCT posts.id, posts.title, posts.slug, posts.content, posts.created_at, users.id, users.name, users.lastname, users.email, categories.id, categories.title, categories.slug, categories.created_at FROM posts").WillReturnRows(rows)
+	mock.ExpectQuery("SELECT posts.id, posts.title, posts.slug, posts.content, posts.excerpt, posts.word_count, posts.reading_time_minutes, posts.meta_title, posts.meta_description, posts.canonical_url, posts.og_image, posts.noindex, posts.created_at, users.id, users.name, users.lastname, users.email, COALESCE\\(\\(SELECT json_agg(.+) FROM posts JOIN users ON posts.user_id = users.id WHERE posts.deleted_at IS NULL AND posts.status = 'published' AND posts.visibility <> 'unlisted' ORDER BY posts.created_at DESC, posts.id DESC").WillReturnRows(rows)
 
 	posts, err := repo.FindAll()
 
@@ -37,6 +65,7 @@ func TestPostRepository_FindAll(t *testing.T) {
 	assert.Equal(t, "Test Post", posts[0].Title)
 	assert.Equal(t, "Another Post", posts[1].Title)
 	assert.Len(t, posts[0].Categories, 2)
+	assert.Equal(t, "category-2", posts[0].Categories[1].Slug)
 	assert.Len(t, posts[1].Categories, 0)
 }
 
@@ -52,20 +81,51 @@ func TestPostRepository_FindAllPaginated(t *testing.T) {
 	countRows := sqlmock.NewRows([]string{"count"}).AddRow(10)
 	mock.ExpectQuery("SELECT COUNT").WillReturnRows(countRows)
 
-	rows := sqlmock.NewRows([]string{"id", "title", "slug", "content", "created_at", "user_id", "name", "lastname", "email", "category_id", "category_title", "category_slug", "category_created_at"}).
-		AddRow("1", "Test Post", "test-post", "Content", time.Now(), "1", "John", "Doe", "john@example.com", "1", "Category 1", "category-1", time.Now()).
-		AddRow("2", "Another Post", "another-post", "More Content", time.Now(), "2", "Jane", "Doe", "jane@example.com", nil, nil, nil, nil)
+	rows := sqlmock.NewRows(postColumns).
+		AddRow(postRow("1", "Test Post", "test-post", "Content", `[{"id":"1","title":"Category 1","slug":"category-1","createdAt":"2024-09-13T20:26:54+00:00"}]`)...).
+		AddRow(postRow("2", "Another Post", "another-post", "More Content", "[]")...)
 
-	mock.ExpectQuery("SELECT DISTINCT posts.id, posts.title, posts.slug, posts.content, posts.created_at, users.id, users.name, users.lastname, users.email, categories.id, categories.title, categories.slug, categories.created_at FROM posts").WillReturnRows(rows)
+	mock.ExpectQuery("SELECT posts.id, (.+) FROM posts JOIN users ON posts.user_id = users.id WHERE posts.deleted_at IS NULL AND posts.status = 'published' AND posts.visibility <> 'unlisted' ORDER BY posts.created_at DESC, posts.id DESC LIMIT 5 OFFSET 5").WillReturnRows(rows)
 
-	posts, totalCount, err := repo.FindAllPaginated(1, 5)
+	posts, totalCount, err := repo.FindAllPaginated(2, 5)
 
 	assert.NoError(t, err)
 	assert.Len(t, posts, 2)
+	assert.Equal(t, "Test Post", posts[0].Title)
+	assert.Len(t, posts[0].Categories, 1)
 	assert.Equal(t, 10, totalCount)
 }
 
-func TestPostRepository_FindBySlug(t *testing.T) {
+func TestPostRepository_FindAllAfter(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPostRepository(db)
+
+	cursor := types.Cursor{CreatedAt: time.Now(), Id: "b7f6a2a4-3f43-4a8e-9d67-0a6a1a8e4d10"}
+
+	rows := sqlmock.NewRows(postColumns).
+		AddRow(postRow("1", "First", "first", "Content", "[]")...).
+		AddRow(postRow("2", "Second", "second", "Content", "[]")...).
+		AddRow(postRow("3", "Third", "third", "Content", "[]")...)
+
+	mock.ExpectQuery("SELECT posts.id, (.+) FROM posts JOIN users ON posts.user_id = users.id WHERE posts.deleted_at IS NULL AND posts.status = 'published' AND posts.visibility <> 'unlisted' AND \\(posts.created_at, posts.id\\) < \\(\\$1, \\$2\\) ORDER BY posts.created_at DESC, posts.id DESC LIMIT 3").
+		WithArgs(cursor.CreatedAt, cursor.Id).
+		WillReturnRows(rows)
+
+	posts, hasMore, err := repo.FindAllAfter(cursor, 2)
+
+	assert.NoError(t, err)
+	assert.True(t, hasMore)
+	assert.Len(t, posts, 2)
+	assert.Equal(t, "First", posts[0].Title)
+	assert.Equal(t, "Second", posts[1].Title)
+}
+
+func TestPostRepository_FindAllBefore(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
@@ -74,17 +134,43 @@ func TestPostRepository_FindBySlug(t *testing.T) {
 
 	repo := repository.NewPostRepository(db)
 
-	rows := sqlmock.NewRows([]string{"id", "title", "slug", "content", "created_at", "user_id", "name", "lastname", "email"}).
-		AddRow("1", "Test Post", "test-post", "Content", time.Now(), "1", "John", "Doe", "john@example.com")
+	cursor := types.Cursor{CreatedAt: time.Now(), Id: "b7f6a2a4-3f43-4a8e-9d67-0a6a1a8e4d10"}
 
-	mock.ExpectQuery("SELECT posts.id, posts.title, posts.slug, posts.content, posts.created_at, users.id, users.name, users.lastname, users.email FROM posts").WithArgs("test-post").WillReturnRows(rows)
+	// Rows come back in ascending order and must be flipped to the listing order
+	rows := sqlmock.NewRows(postColumns).
+		AddRow(postRow("3", "Third", "third", "Content", "[]")...).
+		AddRow(postRow("2", "Second", "second", "Content", "[]")...)
 
-	categoryRows := sqlmock.NewRows([]string{"id", "title", "slug", "created_at"}).
-		AddRow("1", "Category 1", "category-1", time.Now())
+	mock.ExpectQuery("SELECT posts.id, (.+) FROM posts JOIN users ON posts.user_id = users.id WHERE posts.deleted_at IS NULL AND posts.status = 'published' AND posts.visibility <> 'unlisted' AND \\(posts.created_at, posts.id\\) > \\(\\$1, \\$2\\) ORDER BY posts.created_at ASC, posts.id ASC LIMIT 3").
+		WithArgs(cursor.CreatedAt, cursor.Id).
+		WillReturnRows(rows)
 
-	mock.ExpectQuery("SELECT categories.id, categories.title, categories.slug, categories.created_at FROM categories").WillReturnRows(categoryRows)
+	posts, hasMore, err := repo.FindAllBefore(cursor, 2)
 
-	post, err := repo.FindBySlug("test-post")
+	assert.NoError(t, err)
+	assert.False(t, hasMore)
+	assert.Len(t, posts, 2)
+	assert.Equal(t, "Second", posts[0].Title)
+	assert.Equal(t, "Third", posts[1].Title)
+}
+
+func TestPostRepository_FindBySlug(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPostRepository(db)
+
+	rows := sqlmock.NewRows(postColumns).
+		AddRow(postRow("1", "Test Post", "test-post", "Content", `[{"id":"1","title":"Category 1","slug":"category-1","createdAt":"2024-09-13T20:26:54+00:00"}]`)...)
+
+	mock.ExpectQuery("SELECT posts.id, (.+) FROM posts JOIN users ON posts.user_id = users.id WHERE posts.deleted_at IS NULL AND \\(posts.slug = \\$1 OR EXISTS(.+)\\) ORDER BY (.+) DESC LIMIT 1").
+		WithArgs("test-post", "test-post", "test-post", "en", "test-post", "en").
+		WillReturnRows(rows)
+
+	post, err := repo.FindBySlug("test-post", "en")
 
 	assert.NoError(t, err)
 	assert.NotNil(t, post)
@@ -93,7 +179,7 @@ func TestPostRepository_FindBySlug(t *testing.T) {
 	assert.Len(t, post.Categories, 1)
 }
 
-func TestPostRepository_FindById(t *testing.T) {
+func TestPostRepository_FindBySlug_FormerSlug(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
@@ -102,15 +188,37 @@ func TestPostRepository_FindById(t *testing.T) {
 
 	repo := repository.NewPostRepository(db)
 
-	rows := sqlmock.NewRows([]string{"id", "title", "slug", "content", "created_at", "user_id", "name", "lastname", "email"}).
-		AddRow("1", "Test Post", "test-post", "Content", time.Now(), "1", "John", "Doe", "john@example.com")
+	mock.ExpectQuery("SELECT posts.id, (.+) WHERE posts.deleted_at IS NULL AND \\(posts.slug = \\$1 OR EXISTS(.+)\\)").
+		WithArgs("old-slug", "old-slug", "old-slug", "", "old-slug", "").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery("SELECT post_id FROM post_slug_history WHERE slug = \\$1").
+		WithArgs("old-slug").
+		WillReturnRows(sqlmock.NewRows([]string{"post_id"}).AddRow("1"))
+	mock.ExpectQuery("SELECT posts.id, (.+) WHERE posts.deleted_at IS NULL AND posts.id = \\$1").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows(postColumns).
+			AddRow(postRow("1", "New Title", "new-slug", "Content", "[]")...))
+
+	post, err := repo.FindBySlug("old-slug", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "new-slug", post.Slug)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostRepository_FindById(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
 
-	mock.ExpectQuery("SELECT posts.id, posts.title, posts.slug, posts.content, posts.created_at, users.id, users.name, users.lastname, users.email FROM posts").WithArgs("1").WillReturnRows(rows)
+	repo := repository.NewPostRepository(db)
 
-	categoryRows := sqlmock.NewRows([]string{"id", "title", "slug", "created_at"}).
-		AddRow("1", "Category 1", "category-1", time.Now())
+	rows := sqlmock.NewRows(postColumns).
+		AddRow(postRow("1", "Test Post", "test-post", "Content", `[{"id":"1","title":"Category 1","slug":"category-1","createdAt":"2024-09-13T20:26:54+00:00"}]`)...)
 
-	mock.ExpectQuery("SELECT categories.id, categories.title, categories.slug, categories.created_at FROM categories").WillReturnRows(categoryRows)
+	mock.ExpectQuery("SELECT posts.id, (.+) FROM posts JOIN users ON posts.user_id = users.id WHERE posts.deleted_at IS NULL AND posts.id = \\$1").WithArgs("1").WillReturnRows(rows)
 
 	post, err := repo.FindById("1")
 
@@ -121,6 +229,31 @@ func TestPostRepository_FindById(t *testing.T) {
 	assert.Len(t, post.Categories, 1)
 }
 
+func TestPostRepository_FindByIdWithCoverImage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPostRepository(db)
+
+	row := postRow("1", "Test Post", "test-post", "Content", "[]")
+	row[len(row)-11] = `{"id":"f1","userId":"1","filename":"cover.png","width":1200,"height":630,"alt":"A cover"}`
+
+	mock.ExpectQuery("SELECT posts.id, (.+) FROM posts").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows(postColumns).AddRow(row...))
+
+	post, err := repo.FindById("1")
+
+	assert.NoError(t, err)
+	assert.NotNil(t, post.CoverImage)
+	assert.Equal(t, "/uploads/1/cover.png", post.CoverImage.Url)
+	assert.Equal(t, 1200, post.CoverImage.Width)
+	assert.Equal(t, "A cover", post.CoverImage.Alt)
+}
+
 func TestPostRepository_Create(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -130,17 +263,25 @@ func TestPostRepository_Create(t *testing.T) {
 
 	repo := repository.NewPostRepository(db)
 
-	mock.ExpectQuery("SELECT EXISTS").WithArgs("test-post").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectBegin()
+	expectTakenSlugs(mock, "posts")
 
-	mock.ExpectQuery("INSERT INTO posts").WithArgs("Test Post", "test-post", "Content", "1").
-		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "slug", "content", "created_at"}).
-			AddRow("1", "Test Post", "test-post", "Content", time.Now()))
+	mock.ExpectQuery("INSERT INTO posts").WithArgs("Test Post", "test-post", "en", "published", "Content", "Content", 1, 1, "", "", "", "", false, nil, "", "public", nil, int64(types.ContentFingerprint("Content")), false, "1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "slug", "locale", "status", "content", "excerpt", "word_count", "reading_time_minutes", "meta_title", "meta_description", "canonical_url", "og_image", "noindex", "created_at", "version", "updated_at"}).
+			AddRow("1", "Test Post", "test-post", "en", "published", "Content", "Content", 1, 1, "", "", "", "", false, time.Now(), 1, time.Now()))
+	mock.ExpectExec("INSERT INTO post_authors").WithArgs("1", "1", "author").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
 
 	post := types.Post{
-		Title:   "Test Post",
-		Slug:    "test-post",
-		Content: "Content",
-		Author:  types.User{Id: "1"},
+		Title:              "Test Post",
+		Slug:               "test-post",
+		Locale:             "en",
+		Status:             "published",
+		Content:            "Content",
+		Excerpt:            "Content",
+		WordCount:          1,
+		ReadingTimeMinutes: 1,
+		Author:             types.User{Id: "1"},
 	}
 
 	createdPost, err := repo.Create(post)
@@ -149,6 +290,57 @@ func TestPostRepository_Create(t *testing.T) {
 	assert.NotNil(t, createdPost)
 	assert.Equal(t, "Test Post", createdPost.Title)
 	assert.Equal(t, "test-post", createdPost.Slug)
+	assert.Len(t, createdPost.Authors, 1)
+	assert.Equal(t, "author", createdPost.Authors[0].Role)
+}
+
+// bcryptOf matches the bcrypt hash of the password.
+type bcryptOf string
+
+func (password bcryptOf) Match(value driver.Value) bool {
+	hash, ok := value.(string)
+	return ok && bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+func TestPostRepository_CreatePasswordProtected(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPostRepository(db)
+
+	mock.ExpectBegin()
+	expectTakenSlugs(mock, "posts")
+
+	mock.ExpectQuery("INSERT INTO posts").WithArgs("Secret", "secret", "en", "published", "Content", "Content", 1, 1, "", "", "", "", false, nil, "", "password", bcryptOf("open sesame"), int64(types.ContentFingerprint("Content")), false, "1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "slug", "locale", "status", "content", "excerpt", "word_count", "reading_time_minutes", "meta_title", "meta_description", "canonical_url", "og_image", "noindex", "created_at", "version", "updated_at"}).
+			AddRow("1", "Secret", "secret", "en", "published", "Content", "Content", 1, 1, "", "", "", "", false, time.Now(), 1, time.Now()))
+	mock.ExpectExec("INSERT INTO post_authors").WithArgs("1", "1", "author").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	post := types.Post{
+		Title:              "Secret",
+		Slug:               "secret",
+		Locale:             "en",
+		Status:             "published",
+		Visibility:         types.VisibilityPassword,
+		Password:           "open sesame",
+		Content:            "Content",
+		Excerpt:            "Content",
+		WordCount:          1,
+		ReadingTimeMinutes: 1,
+		Author:             types.User{Id: "1"},
+	}
+
+	createdPost, err := repo.Create(post)
+
+	assert.NoError(t, err)
+	assert.Equal(t, types.VisibilityPassword, createdPost.Visibility)
+	assert.Empty(t, createdPost.Password)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(createdPost.PasswordHash), []byte("open sesame")))
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestPostRepository_Update(t *testing.T) {
@@ -161,31 +353,39 @@ func TestPostRepository_Update(t *testing.T) {
 	repo := repository.NewPostRepository(db)
 
 	// Mock finding the existing post
-	mock.ExpectQuery("SELECT posts.id, posts.title, posts.slug, posts.content, posts.created_at, users.id, users.name, users.lastname, users.email FROM posts").
+	mock.ExpectQuery("SELECT posts.id, (.+) FROM posts").
 		WithArgs("1").
-		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "slug", "content", "created_at", "user_id", "name", "lastname", "email"}).
-			AddRow("1", "Old Title", "old-slug", "Old Content", time.Now(), "1", "John", "Doe", "john@example.com"))
+		WillReturnRows(sqlmock.NewRows(postColumns).
+			AddRow(postRow("1", "Old Title", "old-slug", "Old Content", "[]")...))
 
-	// Mock fetching categories for the existing post
-	mock.ExpectQuery("SELECT categories.id, categories.title, categories.slug, categories.created_at FROM categories").
-		WithArgs("1").
-		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "slug", "created_at"}))
-
-	// Mock checking if the new slug exists
-	mock.ExpectQuery("SELECT EXISTS").
-		WithArgs("new-slug").
-		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	// Mock checking if the new slug is taken
+	mock.ExpectBegin()
+	expectTakenSlugs(mock, "posts")
 
 	// Mock updating the post
 	mock.ExpectQuery("UPDATE posts").
-		WithArgs("New Title", "new-slug", "New Content", "1").
-		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "slug", "content", "created_at"}).
-			AddRow("1", "New Title", "new-slug", "New Content", time.Now()))
+		WithArgs("New Title", "new-slug", "en", "published", "New Content", "New Content", 2, 1, "", "", "", "", false, nil, "", "public", nil, int64(types.ContentFingerprint("New Content")), false, "1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "slug", "locale", "status", "content", "excerpt", "word_count", "reading_time_minutes", "meta_title", "meta_description", "canonical_url", "og_image", "noindex", "created_at", "version", "updated_at"}).
+			AddRow("1", "New Title", "new-slug", "en", "published", "New Content", "New Content", 2, 1, "", "", "", "", false, time.Now(), 1, time.Now()))
+
+	// Mock keeping the old slug as a redirect
+	mock.ExpectExec("INSERT INTO post_slug_history \\(slug,post_id\\) VALUES \\(\\$1,\\$2\\) ON CONFLICT \\(slug\\) DO UPDATE").
+		WithArgs("old-slug", "1").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("DELETE FROM post_slug_history WHERE slug = \\$1").
+		WithArgs("new-slug").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
 
 	post := types.Post{
-		Title:   "New Title",
-		Slug:    "new-slug",
-		Content: "New Content",
+		Title:              "New Title",
+		Slug:               "new-slug",
+		Locale:             "en",
+		Status:             "published",
+		Content:            "New Content",
+		Excerpt:            "New Content",
+		WordCount:          2,
+		ReadingTimeMinutes: 1,
 	}
 
 	updatedPost, err := repo.Update("1", post)
@@ -194,6 +394,116 @@ func TestPostRepository_Update(t *testing.T) {
 	assert.NotNil(t, updatedPost)
 	assert.Equal(t, "New Title", updatedPost.Title)
 	assert.Equal(t, "new-slug", updatedPost.Slug)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostRepository_UpdateKeepingSlug(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPostRepository(db)
+
+	mock.ExpectQuery("SELECT posts.id, (.+) FROM posts").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows(postColumns).
+			AddRow(postRow("1", "Title", "title", "Old Content", "[]")...))
+
+	// No slug check nor history when the slug stays the same
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE posts").
+		WithArgs("Title", "title", "en", "published", "New Content", "", 0, 0, "", "", "", "", false, nil, "", "public", nil, int64(types.ContentFingerprint("New Content")), false, "1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "slug", "locale", "status", "content", "excerpt", "word_count", "reading_time_minutes", "meta_title", "meta_description", "canonical_url", "og_image", "noindex", "created_at", "version", "updated_at"}).
+			AddRow("1", "Title", "title", "en", "published", "New Content", "", 0, 0, "", "", "", "", false, time.Now(), 1, time.Now()))
+	mock.ExpectCommit()
+
+	updatedPost, err := repo.Update("1", types.Post{Title: "Title", Slug: "title", Locale: "en", Status: "published", Content: "New Content"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "title", updatedPost.Slug)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostRepository_Patch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPostRepository(db)
+
+	existing := types.Post{Title: "Title", Slug: "title", Locale: "en", Status: "published", Content: "Content", Excerpt: "Content", WordCount: 1, ReadingTimeMinutes: 1, Version: 1}
+
+	// Only the title is written
+	mock.ExpectQuery("SELECT posts.id, (.+) FROM posts").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows(postColumns).
+			AddRow(postRow("1", "Title", "title", "Content", "[]")...))
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE posts SET title = \\$1, version = version \\+ 1, updated_at = CURRENT_TIMESTAMP WHERE id = \\$2 AND version = \\$3").
+		WithArgs("New Title", "1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "slug", "locale", "status", "content", "excerpt", "word_count", "reading_time_minutes", "meta_title", "meta_description", "canonical_url", "og_image", "noindex", "created_at", "version", "updated_at"}).
+			AddRow("1", "New Title", "title", "en", "published", "Content", "Content", 1, 1, "", "", "", "", false, time.Now(), 2, time.Now()))
+	mock.ExpectCommit()
+
+	post := existing
+	post.Title = "New Title"
+	patchedPost, err := repo.Patch("1", post)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "New Title", patchedPost.Title)
+	assert.Equal(t, 2, patchedPost.Version)
+
+	// Nothing differs, so the post stays at its version
+	mock.ExpectQuery("SELECT posts.id, (.+) FROM posts").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows(postColumns).
+			AddRow(postRow("1", "Title", "title", "Content", "[]")...))
+
+	patchedPost, err = repo.Patch("1", existing)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, patchedPost.Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostRepository_UpdateVersionConflict(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPostRepository(db)
+
+	// The caller read a version that has been replaced since
+	mock.ExpectQuery("SELECT posts.id, (.+) FROM posts").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows(postColumns).
+			AddRow(postRow("1", "Title", "title", "Old Content", "[]")...))
+
+	_, err = repo.Update("1", types.Post{Title: "Title", Slug: "title", Locale: "en", Status: "published", Content: "New Content", Version: 2})
+
+	assert.ErrorIs(t, err, repository.ErrVersionConflict)
+
+	// Someone else saves the post between the read and the update
+	mock.ExpectQuery("SELECT posts.id, (.+) FROM posts").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows(postColumns).
+			AddRow(postRow("1", "Title", "title", "Old Content", "[]")...))
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE posts (.+) WHERE id = \\$20 AND version = \\$21").
+		WithArgs("Title", "title", "en", "published", "New Content", "", 0, 0, "", "", "", "", false, nil, "", "public", nil, int64(types.ContentFingerprint("New Content")), false, "1", 1).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	_, err = repo.Update("1", types.Post{Title: "Title", Slug: "title", Locale: "en", Status: "published", Content: "New Content", Version: 1})
+
+	assert.ErrorIs(t, err, repository.ErrVersionConflict)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 func TestPostRepository_Delete(t *testing.T) {
 	db, mock, err := sqlmock.New()
@@ -204,13 +514,76 @@ func TestPostRepository_Delete(t *testing.T) {
 
 	repo := repository.NewPostRepository(db)
 
-	mock.ExpectExec("DELETE FROM posts").WithArgs("1").WillReturnResult(sqlmock.NewResult(1, 1))
+	// Deleting only moves the post to the trash
+	mock.ExpectExec("UPDATE posts SET deleted_at = CURRENT_TIMESTAMP WHERE id = \\$1 AND deleted_at IS NULL").WithArgs("1").WillReturnResult(sqlmock.NewResult(1, 1))
 
 	err = repo.Delete("1")
 
 	assert.NoError(t, err)
 }
 
+func TestPostRepository_FindDeleted(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPostRepository(db)
+
+	row := postRow("1", "Trashed", "trashed", "Content", "[]")
+	row[len(row)-10] = time.Now()
+
+	mock.ExpectQuery("SELECT posts.id, (.+) FROM posts JOIN users ON posts.user_id = users.id WHERE posts.deleted_at IS NOT NULL AND posts.user_id = \\$1 ORDER BY posts.deleted_at DESC").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows(postColumns).AddRow(row...))
+
+	posts, err := repo.FindDeleted("1")
+
+	assert.NoError(t, err)
+	assert.Len(t, posts, 1)
+	assert.NotNil(t, posts[0].DeletedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostRepository_RestoreAndPurge(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPostRepository(db)
+
+	mock.ExpectExec("UPDATE posts SET deleted_at = \\$1 WHERE id = \\$2 AND deleted_at IS NOT NULL").
+		WithArgs(nil, "1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.Restore("1"))
+
+	mock.ExpectExec("UPDATE posts SET deleted_at = \\$1 WHERE id = \\$2 AND deleted_at IS NOT NULL").
+		WithArgs(nil, "2").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	err = repo.Restore("2")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no deleted post found with id 2 to restore")
+
+	// Only trashed posts can be purged
+	mock.ExpectExec("DELETE FROM posts WHERE id = \\$1 AND deleted_at IS NOT NULL").
+		WithArgs("1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.Purge("1"))
+
+	before := time.Now().Add(-time.Hour)
+	mock.ExpectExec("DELETE FROM posts WHERE deleted_at < \\$1").
+		WithArgs(before).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	purged, err := repo.PurgeDeletedBefore(before)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), purged)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestPostRepository_AssignCategoryToPost(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -221,10 +594,12 @@ func TestPostRepository_AssignCategoryToPost(t *testing.T) {
 	repo := repository.NewPostRepository(db)
 
 	mock.ExpectExec("INSERT INTO post_categories").WithArgs("1", "2").WillReturnResult(sqlmock.NewResult(1, 1))
+	expectVersionBump(mock, "posts", "1")
 
 	err = repo.AssignCategoryToPost("1", "2")
 
 	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestPostRepository_UnassignCategoryFromPost(t *testing.T) {
@@ -239,10 +614,12 @@ func TestPostRepository_UnassignCategoryFromPost(t *testing.T) {
 	mock.ExpectExec("DELETE FROM post_categories").
 		WithArgs("2", "1"). // Swap the order: categoryId first, then postId
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectVersionBump(mock, "posts", "1")
 
 	err = repo.UnassignCategoryFromPost("1", "2")
 
 	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestPostRepository_GetCategoriesForPost(t *testing.T) {
@@ -281,6 +658,7 @@ func TestPostRepository_UpdatePostCategories(t *testing.T) {
 	mock.ExpectExec("DELETE FROM post_categories").WithArgs("1").WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectExec("INSERT INTO post_categories").WithArgs("1", "2").WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectExec("INSERT INTO post_categories").WithArgs("1", "3").WillReturnResult(sqlmock.NewResult(1, 1))
+	expectVersionBump(mock, "posts", "1")
 	mock.ExpectCommit()
 
 	err = repo.UpdatePostCategories("1", []string{"2", "3"})
@@ -296,18 +674,21 @@ func TestPostRepository_CreateWithUniqueSlug(t *testing.T) {
 
 	repo := repository.NewPostRepository(db)
 
-	// First attempt: "test-slug" already exists
-	mock.ExpectQuery("SELECT EXISTS").WithArgs("test-slug").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
-	// Second attempt: "test-slug-1" is available
-	mock.ExpectQuery("SELECT EXISTS").WithArgs("test-slug-1").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	// "test-slug" already exists, "test-slug-1" is available
+	mock.ExpectBegin()
+	expectTakenSlugs(mock, "posts", "test-slug")
 
-	mock.ExpectQuery("INSERT INTO posts").WithArgs("Test Post", "test-slug-1", "Content", "1").
-		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "slug", "content", "created_at"}).
-			AddRow("1", "Test Post", "test-slug-1", "Content", time.Now()))
+	mock.ExpectQuery("INSERT INTO posts").WithArgs("Test Post", "test-slug-1", "en", "published", "Content", "", 0, 0, "", "", "", "", false, nil, "", "public", nil, int64(types.ContentFingerprint("Content")), false, "1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "slug", "locale", "status", "content", "excerpt", "word_count", "reading_time_minutes", "meta_title", "meta_description", "canonical_url", "og_image", "noindex", "created_at", "version", "updated_at"}).
+			AddRow("1", "Test Post", "test-slug-1", "en", "published", "Content", "", 0, 0, "", "", "", "", false, time.Now(), 1, time.Now()))
+	mock.ExpectExec("INSERT INTO post_authors").WithArgs("1", "1", "author").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
 
 	post := types.Post{
 		Title:   "Test Post",
 		Slug:    "test-slug",
+		Locale:  "en",
+		Status:  "published",
 		Content: "Content",
 		Author:  types.User{Id: "1"},
 	}
@@ -328,27 +709,22 @@ func TestPostRepository_CreateWithMultipleSlugAttempts(t *testing.T) {
 
 	repo := repository.NewPostRepository(db)
 
-	// First three attempts return true (slug exists)
-	for i := 0; i < 3; i++ {
-		slug := fmt.Sprintf("test-slug%s", func() string {
-			if i == 0 {
-				return ""
-			}
-			return fmt.Sprintf("-%d", i)
-		}())
-		mock.ExpectQuery("SELECT EXISTS").WithArgs(slug).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
-	}
-
-	// Fourth attempt succeeds
-	mock.ExpectQuery("SELECT EXISTS").WithArgs("test-slug-3").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	// The first three slugs are taken, either currently or formerly, and
+	// "test-slug-10" must not be mistaken for "test-slug-1"
+	mock.ExpectBegin()
+	expectTakenSlugs(mock, "posts", "test-slug", "test-slug-1", "test-slug-2", "test-slug-10")
 
-	mock.ExpectQuery("INSERT INTO posts").WithArgs("Test Post", "test-slug-3", "Content", "1").
-		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "slug", "content", "created_at"}).
-			AddRow("1", "Test Post", "test-slug-3", "Content", time.Now()))
+	mock.ExpectQuery("INSERT INTO posts").WithArgs("Test Post", "test-slug-3", "en", "published", "Content", "", 0, 0, "", "", "", "", false, nil, "", "public", nil, int64(types.ContentFingerprint("Content")), false, "1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "slug", "locale", "status", "content", "excerpt", "word_count", "reading_time_minutes", "meta_title", "meta_description", "canonical_url", "og_image", "noindex", "created_at", "version", "updated_at"}).
+			AddRow("1", "Test Post", "test-slug-3", "en", "published", "Content", "", 0, 0, "", "", "", "", false, time.Now(), 1, time.Now()))
+	mock.ExpectExec("INSERT INTO post_authors").WithArgs("1", "1", "author").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
 
 	post := types.Post{
 		Title:   "Test Post",
 		Slug:    "test-slug",
+		Locale:  "en",
+		Status:  "published",
 		Content: "Content",
 		Author:  types.User{Id: "1"},
 	}
@@ -370,7 +746,7 @@ func TestPostRepository_ErrorHandling(t *testing.T) {
 	repo := repository.NewPostRepository(db)
 
 	t.Run("FindAll Error", func(t *testing.T) {
-		mock.ExpectQuery("SELECT DISTINCT posts.id, posts.title, posts.slug, posts.content, posts.created_at, users.id, users.name, users.lastname, users.email, categories.id, categories.title, categories.slug, categories.created_at FROM posts").
+		mock.ExpectQuery("SELECT posts.id, (.+) FROM posts JOIN users").
 			WillReturnError(sql.ErrConnDone)
 
 		_, err := repo.FindAll()
@@ -387,17 +763,20 @@ func TestPostRepository_ErrorHandling(t *testing.T) {
 	})
 
 	t.Run("FindBySlug Error", func(t *testing.T) {
-		mock.ExpectQuery("SELECT posts.id, posts.title, posts.slug, posts.content, posts.created_at, users.id, users.name, users.lastname, users.email FROM posts").
+		mock.ExpectQuery("SELECT posts.id, (.+) FROM posts").
+			WithArgs("non-existent-slug", "non-existent-slug", "non-existent-slug", "", "non-existent-slug", "").
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectQuery("SELECT post_id FROM post_slug_history").
 			WithArgs("non-existent-slug").
 			WillReturnError(sql.ErrNoRows)
 
-		_, err := repo.FindBySlug("non-existent-slug")
+		_, err := repo.FindBySlug("non-existent-slug", "")
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "error scanning row in FindBySlug")
 	})
 
 	t.Run("FindById Error", func(t *testing.T) {
-		mock.ExpectQuery("SELECT posts.id, posts.title, posts.slug, posts.content, posts.created_at, users.id, users.name, users.lastname, users.email FROM posts").
+		mock.ExpectQuery("SELECT posts.id, (.+) FROM posts").
 			WithArgs("non-existent-id").
 			WillReturnError(sql.ErrNoRows)
 
@@ -407,8 +786,10 @@ func TestPostRepository_ErrorHandling(t *testing.T) {
 	})
 
 	t.Run("Create Error", func(t *testing.T) {
-		mock.ExpectQuery("SELECT EXISTS").WithArgs("test-slug").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectBegin()
+		expectTakenSlugs(mock, "posts")
 		mock.ExpectQuery("INSERT INTO posts").WillReturnError(errors.New("duplicate key value violates unique constraint"))
+		mock.ExpectRollback()
 
 		post := types.Post{
 			Title:   "Test Post",
@@ -423,7 +804,7 @@ func TestPostRepository_ErrorHandling(t *testing.T) {
 	})
 
 	t.Run("Update Error", func(t *testing.T) {
-		mock.ExpectQuery("SELECT posts.id, posts.title, posts.slug, posts.content, posts.created_at, users.id, users.name, users.lastname, users.email FROM posts").
+		mock.ExpectQuery("SELECT posts.id, (.+) FROM posts").
 			WithArgs("1").
 			WillReturnError(sql.ErrNoRows)
 
@@ -439,7 +820,7 @@ func TestPostRepository_ErrorHandling(t *testing.T) {
 	})
 
 	t.Run("Delete Error", func(t *testing.T) {
-		mock.ExpectExec("DELETE FROM posts").WithArgs("non-existent-id").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("UPDATE posts SET deleted_at").WithArgs("non-existent-id").WillReturnResult(sqlmock.NewResult(0, 0))
 
 		err := repo.Delete("non-existent-id")
 		assert.Error(t, err)
@@ -505,3 +886,392 @@ func TestPostRepository_ErrorHandling(t *testing.T) {
 		assert.Contains(t, err.Error(), "error adding new category")
 	})
 }
+
+func TestPostRepository_UpdatePostTags(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPostRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM post_tags").WithArgs("1").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("INSERT INTO tags (.+) ON CONFLICT \\(slug\\)").WithArgs("Go", "go").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "slug", "created_at"}).AddRow("10", "go", "go", time.Now()))
+	mock.ExpectExec("INSERT INTO post_tags").WithArgs("1", "10").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("INSERT INTO tags (.+) ON CONFLICT \\(slug\\)").WithArgs("Fiber", "fiber").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "slug", "created_at"}).AddRow("11", "Fiber", "fiber", time.Now()))
+	mock.ExpectExec("INSERT INTO post_tags").WithArgs("1", "11").WillReturnResult(sqlmock.NewResult(1, 1))
+	expectVersionBump(mock, "posts", "1")
+	mock.ExpectCommit()
+
+	tags, err := repo.UpdatePostTags("1", []types.Tag{{Name: "Go", Slug: "go"}, {Name: "Fiber", Slug: "fiber"}})
+
+	assert.NoError(t, err)
+	assert.Len(t, tags, 2)
+	assert.Equal(t, "10", tags[0].Id)
+	assert.Equal(t, "go", tags[0].Name)
+	assert.Equal(t, "fiber", tags[1].Slug)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostRepository_UpdatePostAuthors(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPostRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM post_authors").WithArgs("1").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO post_authors").WithArgs("1", "2", "author", 0).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO post_authors").WithArgs("1", "1", "editor", 1).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("SELECT u.id, (.+) FROM post_authors pa JOIN users u (.+) ORDER BY pa.position").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "lastname", "email", "role"}).
+			AddRow("2", "Jane", "Roe", "jane@example.com", "author").
+			AddRow("1", "John", "Doe", "john@example.com", "editor"))
+	expectVersionBump(mock, "posts", "1")
+	mock.ExpectCommit()
+
+	authors, err := repo.UpdatePostAuthors("1", []types.PostAuthor{
+		{Id: "2", Role: types.AuthorRoleAuthor},
+		{Id: "1", Role: types.AuthorRoleEditor},
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, authors, 2)
+	assert.Equal(t, "Jane", authors[0].Name)
+	assert.Equal(t, "editor", authors[1].Role)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostRepository_UpdatePostAuthorsUnknownUser(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPostRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM post_authors").WithArgs("1").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO post_authors").WithArgs("1", "404", "author", 0).
+		WillReturnError(errors.New("foreign key constraint violation"))
+	mock.ExpectRollback()
+
+	_, err = repo.UpdatePostAuthors("1", []types.PostAuthor{{Id: "404", Role: types.AuthorRoleAuthor}})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "error adding author 404")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostRepository_FindAllByTag(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPostRepository(db)
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM posts WHERE posts.deleted_at IS NULL AND posts.status = 'published' AND posts.visibility <> 'unlisted' AND EXISTS").WithArgs("go").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	rows := sqlmock.NewRows(postColumns).
+		AddRow(postRow("1", "Test Post", "test-post", "Content", "[]")...)
+
+	mock.ExpectQuery("SELECT posts.id, (.+) FROM posts JOIN users ON posts.user_id = users.id WHERE posts.deleted_at IS NULL AND posts.status = 'published' AND posts.visibility <> 'unlisted' AND EXISTS(.+)t.slug = \\$1\\) ORDER BY").
+		WithArgs("go").
+		WillReturnRows(rows)
+
+	posts, totalCount, err := repo.FindAllByTag("go", 1, 10)
+
+	assert.NoError(t, err)
+	assert.Len(t, posts, 1)
+	assert.Equal(t, 1, totalCount)
+}
+
+func TestPostRepository_FindRelated(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPostRepository(db)
+
+	mock.ExpectQuery("SELECT posts.id, (.+) FROM posts JOIN users ON posts.user_id = users.id JOIN posts src ON src.id = \\$1 WHERE posts.deleted_at IS NULL AND posts.status = 'published' AND posts.visibility <> 'unlisted' AND posts.id <> src.id AND (.+) > 0 ORDER BY (.+) DESC, posts.created_at DESC, posts.id DESC LIMIT 5").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows(postColumns).
+			AddRow(postRow("2", "Related Post", "related-post", "Content", "[]")...))
+
+	posts, err := repo.FindRelated("1", 5)
+
+	assert.NoError(t, err)
+	assert.Len(t, posts, 1)
+	assert.Equal(t, "2", posts[0].Id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostRepository_SavePostTranslation(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPostRepository(db)
+
+	translationColumns := []string{"locale", "title", "slug", "content", "excerpt", "word_count", "reading_time_minutes", "updated_at"}
+
+	// A new translation takes the first slug free among the Turkish posts and translations
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT slug FROM post_translations WHERE post_id = \\$1 AND locale = \\$2 FOR UPDATE").
+		WithArgs("1", "tr").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectExec("SELECT pg_advisory_xact_lock\\(hashtext\\(\\$1\\)\\)").
+		WithArgs("posts.slug").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT slug FROM posts WHERE (.+) AND locale = \\$3 AND id <> \\$4 UNION SELECT slug FROM post_translations WHERE (.+) AND locale = \\$7 AND post_id <> \\$8 UNION SELECT slug FROM post_slug_history").
+		WithArgs("merhaba", "merhaba-%", "tr", "1", "merhaba", "merhaba-%", "tr", "1", "merhaba", "merhaba-%", "1").
+		WillReturnRows(sqlmock.NewRows([]string{"slug"}).AddRow("merhaba"))
+	mock.ExpectQuery("INSERT INTO post_translations (.+) ON CONFLICT \\(post_id, locale\\) DO UPDATE").
+		WithArgs("1", "tr", "Merhaba", "merhaba-1", "İçerik", "İçerik", 1, 1).
+		WillReturnRows(sqlmock.NewRows(translationColumns).
+			AddRow("tr", "Merhaba", "merhaba-1", "İçerik", "İçerik", 1, 1, time.Now()))
+	expectVersionBump(mock, "posts", "1")
+	mock.ExpectCommit()
+
+	translation, err := repo.SavePostTranslation("1", types.PostTranslation{
+		Locale:             "tr",
+		Title:              "Merhaba",
+		Slug:               "merhaba",
+		Content:            "İçerik",
+		Excerpt:            "İçerik",
+		WordCount:          1,
+		ReadingTimeMinutes: 1,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "merhaba-1", translation.Slug)
+
+	// Renaming it keeps the former slug redirecting to the post
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT slug FROM post_translations WHERE post_id = \\$1 AND locale = \\$2 FOR UPDATE").
+		WithArgs("1", "tr").
+		WillReturnRows(sqlmock.NewRows([]string{"slug"}).AddRow("merhaba-1"))
+	expectTakenSlugs(mock, "posts")
+	mock.ExpectQuery("INSERT INTO post_translations").
+		WithArgs("1", "tr", "Selam", "selam", "İçerik", "İçerik", 1, 1).
+		WillReturnRows(sqlmock.NewRows(translationColumns).
+			AddRow("tr", "Selam", "selam", "İçerik", "İçerik", 1, 1, time.Now()))
+	mock.ExpectExec("INSERT INTO post_slug_history").
+		WithArgs("merhaba-1", "1").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("DELETE FROM post_slug_history WHERE slug = \\$1").
+		WithArgs("selam").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	expectVersionBump(mock, "posts", "1")
+	mock.ExpectCommit()
+
+	translation, err = repo.SavePostTranslation("1", types.PostTranslation{
+		Locale:             "tr",
+		Title:              "Selam",
+		Slug:               "selam",
+		Content:            "İçerik",
+		Excerpt:            "İçerik",
+		WordCount:          1,
+		ReadingTimeMinutes: 1,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "selam", translation.Slug)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostRepository_FindByIdWithTranslations(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPostRepository(db)
+
+	row := postRow("1", "Hello", "hello", "Content", "[]")
+	row[len(row)-8] = `[{"locale":"tr","title":"Merhaba","slug":"merhaba"}]`
+
+	mock.ExpectQuery("SELECT posts.id, (.+) FROM posts").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows(postColumns).AddRow(row...))
+
+	post, err := repo.FindById("1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "en", post.Locale)
+	assert.Equal(t, []types.Translation{{Locale: "tr", Title: "Merhaba", Slug: "merhaba", Url: "/api/posts/merhaba?lang=tr"}}, post.Translations)
+	assert.Equal(t, []string{"en", "tr"}, post.Locales())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostRepository_DeletePostTranslation(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPostRepository(db)
+
+	mock.ExpectExec("DELETE FROM post_translations WHERE locale = \\$1 AND post_id = \\$2").
+		WithArgs("tr", "1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repo.DeletePostTranslation("1", "tr")
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no tr translation found for post 1 to delete")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostRepository_FindByFilter(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPostRepository(db)
+
+	// Drafts are found too, unlike in listings
+	mock.ExpectQuery("SELECT posts.id, (.+) WHERE posts.deleted_at IS NULL AND EXISTS\\(SELECT 1 FROM post_categories pc (.+) AND pc.category_id = \\$1\\) AND posts.status = \\$2 ORDER BY posts.created_at DESC, posts.id DESC LIMIT 501").
+		WithArgs("2", "draft").
+		WillReturnRows(sqlmock.NewRows(postColumns).
+			AddRow(postRow("1", "Draft", "draft", "Content", "[]")...))
+
+	posts, err := repo.FindByFilter(types.PostFilter{CategoryId: "2", Status: types.PostStatusDraft}, 501)
+
+	assert.NoError(t, err)
+	assert.Len(t, posts, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostRepository_ApplyBulkAction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPostRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SAVEPOINT bulk_post").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE posts SET status = \\$1, version = version \\+ 1, updated_at = CURRENT_TIMESTAMP WHERE id = \\$2 AND deleted_at IS NULL").
+		WithArgs("published", "1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("RELEASE SAVEPOINT bulk_post").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// The second post is gone by now, only its own change is undone
+	mock.ExpectExec("SAVEPOINT bulk_post").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE posts SET status = \\$1").
+		WithArgs("published", "2").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT bulk_post").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	failed, err := repo.ApplyBulkAction(types.BulkRequest{Action: types.BulkActionPublish}, []string{"1", "2"})
+
+	assert.NoError(t, err)
+	assert.Len(t, failed, 1)
+	assert.Contains(t, failed["2"].Error(), "no post found with id 2")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostRepository_ApplyBulkActionChangeAuthor(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPostRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SAVEPOINT bulk_post").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM post_authors WHERE post_id = \\$1 AND user_id = \\$2").
+		WithArgs("1", "9").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE post_authors SET user_id = \\$1 WHERE post_id = \\$2 AND user_id = \\(SELECT user_id FROM posts WHERE id = \\$2\\)").
+		WithArgs("9", "1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE posts SET user_id = \\$1, version = version \\+ 1, updated_at = CURRENT_TIMESTAMP WHERE id = \\$2 AND deleted_at IS NULL").
+		WithArgs("9", "1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("RELEASE SAVEPOINT bulk_post").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	failed, err := repo.ApplyBulkAction(types.BulkRequest{Action: types.BulkActionChangeAuthor, AuthorId: "9"}, []string{"1"})
+
+	assert.NoError(t, err)
+	assert.Empty(t, failed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostRepository_FindReviewQueue(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPostRepository(db)
+
+	row := postRow("1", "Submitted", "submitted", "Content", "[]")
+	row[len(row)-5] = "in_review"
+	row[len(row)-2] = "u2"
+	mock.ExpectQuery("SELECT posts.id, (.+) WHERE posts.deleted_at IS NULL AND posts.status = \\$1 AND posts.reviewer_id = \\$2 ORDER BY posts.updated_at ASC, posts.id ASC").
+		WithArgs("in_review", "u2").
+		WillReturnRows(sqlmock.NewRows(postColumns).AddRow(row...))
+
+	posts, err := repo.FindReviewQueue("u2")
+
+	assert.NoError(t, err)
+	assert.Len(t, posts, 1)
+	assert.Equal(t, types.PostStatusInReview, posts[0].Status)
+	assert.Equal(t, "u2", posts[0].ReviewerId)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostRepository_AssignReviewer(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPostRepository(db)
+
+	mock.ExpectExec("UPDATE posts SET reviewer_id = \\$1, version = version \\+ 1 WHERE id = \\$2 AND deleted_at IS NULL").
+		WithArgs("u2", "1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// An empty reviewer unassigns the post
+	mock.ExpectExec("UPDATE posts SET reviewer_id = \\$1").
+		WithArgs(nil, "1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE posts SET reviewer_id = \\$1").
+		WithArgs("u2", "2").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, repo.AssignReviewer("1", "u2"))
+	assert.NoError(t, repo.AssignReviewer("1", ""))
+	assert.EqualError(t, repo.AssignReviewer("2", "u2"), "no post found with id 2")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,78 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"go-blog/internal/repository"
+	"go-blog/internal/types"
+)
+
+func TestPreviewLinkRepository_FindActiveByPost(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPreviewLinkRepository(db)
+
+	expiresAt := time.Now().Add(time.Hour)
+	mock.ExpectQuery("SELECT id, post_id, user_id, expires_at, created_at FROM post_preview_links WHERE post_id = \\$1 AND expires_at > CURRENT_TIMESTAMP ORDER BY created_at DESC, id DESC").
+		WithArgs("p1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "post_id", "user_id", "expires_at", "created_at"}).
+			AddRow("l1", "p1", "u1", expiresAt, time.Now()))
+
+	links, err := repo.FindActiveByPost("p1")
+
+	assert.NoError(t, err)
+	assert.Len(t, links, 1)
+	assert.Equal(t, "u1", links[0].CreatedBy)
+	assert.Equal(t, expiresAt, links[0].ExpiresAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPreviewLinkRepository_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPreviewLinkRepository(db)
+
+	expiresAt := time.Now().Add(time.Hour)
+	mock.ExpectQuery("INSERT INTO post_preview_links \\(post_id,user_id,expires_at\\) VALUES \\(\\$1,\\$2,\\$3\\) RETURNING id, created_at").
+		WithArgs("p1", "u1", expiresAt).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow("l1", time.Now()))
+
+	link, err := repo.Create(types.PreviewLink{PostId: "p1", CreatedBy: "u1", ExpiresAt: expiresAt})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "l1", link.Id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPreviewLinkRepository_Delete(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPreviewLinkRepository(db)
+
+	mock.ExpectExec("DELETE FROM post_preview_links WHERE id = \\$1 AND post_id = \\$2").
+		WithArgs("l1", "p1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM post_preview_links").
+		WithArgs("l2", "p1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, repo.Delete("p1", "l1"))
+	assert.EqualError(t, repo.Delete("p1", "l2"), "no preview link found with id l2")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,97 @@
+package repository_test
+
+import (
+	"go-blog/internal/repository"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReactionRepository_Add(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewReactionRepository(db)
+
+	mock.ExpectExec("INSERT INTO post_reactions \\(post_id,user_id,type\\) VALUES \\(\\$1,\\$2,\\$3\\) ON CONFLICT DO NOTHING").
+		WithArgs("1", "2", "like").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.Add("1", "2", "like")
+
+	assert.NoError(t, err)
+}
+
+func TestReactionRepository_Remove(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewReactionRepository(db)
+
+	mock.ExpectExec("DELETE FROM post_reactions").
+		WithArgs("1", "like", "2").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.Remove("1", "2", "like")
+
+	assert.NoError(t, err)
+}
+
+func TestReactionRepository_CountByPost(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewReactionRepository(db)
+
+	rows := sqlmock.NewRows([]string{"type", "count"}).
+		AddRow("heart", 2).
+		AddRow("like", 5)
+
+	mock.ExpectQuery("SELECT type, COUNT\\(\\*\\) FROM post_reactions WHERE post_id = \\$1 GROUP BY type").
+		WithArgs("1").
+		WillReturnRows(rows)
+
+	reactions, err := repo.CountByPost("1")
+
+	assert.NoError(t, err)
+	assert.Len(t, reactions, 2)
+	assert.Equal(t, "like", reactions[1].Type)
+	assert.Equal(t, 5, reactions[1].Count)
+}
+
+func TestReactionRepository_FindUserReactions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewReactionRepository(db)
+
+	rows := sqlmock.NewRows([]string{"post_id", "type"}).
+		AddRow("1", "like").
+		AddRow("1", "clap").
+		AddRow("3", "heart")
+
+	mock.ExpectQuery("SELECT post_id, type FROM post_reactions WHERE").
+		WithArgs("1", "2", "3", "9").
+		WillReturnRows(rows)
+
+	reacted, err := repo.FindUserReactions("9", []string{"1", "2", "3"})
+
+	assert.NoError(t, err)
+	assert.True(t, reacted["1"]["like"])
+	assert.True(t, reacted["1"]["clap"])
+	assert.False(t, reacted["2"]["like"])
+	assert.True(t, reacted["3"]["heart"])
+}
@@ -0,0 +1,107 @@
+package repository_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"go-blog/internal/repository"
+	"go-blog/internal/types"
+)
+
+func TestReviewRepository_FindByPost(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewReviewRepository(db)
+
+	createdAt := time.Now()
+	mock.ExpectQuery("SELECT (.+) FROM post_reviews JOIN users ON post_reviews.user_id = users.id WHERE post_reviews.post_id = \\$1 ORDER BY post_reviews.created_at ASC").
+		WithArgs("p1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "post_id", "status", "comment", "created_at", "user_id", "name", "lastname"}).
+			AddRow("r1", "p1", "in_review", "", createdAt, "u1", "John", "Doe").
+			AddRow("r2", "p1", "changes_requested", "Shorten the intro", createdAt, "u2", "Jane", "Doe"))
+
+	reviews, err := repo.FindByPost("p1")
+
+	assert.NoError(t, err)
+	assert.Len(t, reviews, 2)
+	assert.Equal(t, types.PostStatusChangesRequested, reviews[1].Status)
+	assert.Equal(t, "Shorten the intro", reviews[1].Comment)
+	assert.Equal(t, "Jane", reviews[1].Author.Name)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReviewRepository_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewReviewRepository(db)
+
+	mock.ExpectQuery("INSERT INTO post_reviews \\(post_id,user_id,status,comment\\) VALUES \\(\\$1,\\$2,\\$3,\\$4\\) RETURNING id, created_at").
+		WithArgs("p1", "u2", "approved", "Looks good").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow("r1", time.Now()))
+
+	review, err := repo.Create(types.Review{PostId: "p1", Status: types.PostStatusApproved, Comment: "Looks good", Author: types.User{Id: "u2"}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "r1", review.Id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReviewRepository_CreateWithStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewReviewRepository(db)
+	review := types.Review{PostId: "p1", Status: types.PostStatusApproved, Comment: "Looks good", Author: types.User{Id: "u2"}}
+
+	// The status and the review are saved together
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE posts SET status = \\$1, version = version \\+ 1, updated_at = CURRENT_TIMESTAMP WHERE id = \\$2 AND version = \\$3").
+		WithArgs("approved", "p1", 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO post_reviews \\(post_id,user_id,status,comment\\) VALUES \\(\\$1,\\$2,\\$3,\\$4\\) RETURNING id, created_at").
+		WithArgs("p1", "u2", "approved", "Looks good").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow("r1", time.Now()))
+	mock.ExpectCommit()
+
+	createdReview, err := repo.CreateWithStatus(review, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "r1", createdReview.Id)
+
+	// Neither is saved when the post changed since it was read, or the review fails
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE posts SET status").
+		WithArgs("approved", "p1", 2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	_, err = repo.CreateWithStatus(review, 2)
+	assert.ErrorIs(t, err, repository.ErrVersionConflict)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE posts SET status").
+		WithArgs("approved", "p1", 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO post_reviews").
+		WillReturnError(fmt.Errorf("foreign key violation"))
+	mock.ExpectRollback()
+
+	_, err = repo.CreateWithStatus(review, 3)
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
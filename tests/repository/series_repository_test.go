@@ -0,0 +1,188 @@
+package repository_test
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"go-blog/internal/repository"
+	"go-blog/internal/types"
+)
+
+var seriesColumns = []string{"id", "title", "slug", "description", "user_id", "part_count", "created_at"}
+
+// expectSeriesParts expects the parts of a series listed to readers, its published posts.
+func expectSeriesParts(mock sqlmock.Sqlmock, seriesId string, parts ...[]string) {
+	expectParts(mock, "SELECT posts.id, posts.title, posts.slug FROM series_posts JOIN posts (.+) AND posts.status = 'published' (.+) ORDER BY series_posts.position", seriesId, parts)
+}
+
+// expectAllSeriesParts expects all the parts of a series, drafts included.
+func expectAllSeriesParts(mock sqlmock.Sqlmock, seriesId string, parts ...[]string) {
+	expectParts(mock, "SELECT posts.id, posts.title, posts.slug FROM series_posts JOIN posts ON posts.id = series_posts.post_id WHERE series_posts.series_id = \\$1 AND posts.deleted_at IS NULL ORDER BY series_posts.position", seriesId, parts)
+}
+
+func expectParts(mock sqlmock.Sqlmock, query string, seriesId string, parts [][]string) {
+	rows := sqlmock.NewRows([]string{"id", "title", "slug"})
+	for _, part := range parts {
+		rows.AddRow(part[0], part[1], part[2])
+	}
+	mock.ExpectQuery(query).
+		WithArgs(seriesId).
+		WillReturnRows(rows)
+}
+
+func TestSeriesRepository_FindBySlug(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewSeriesRepository(db)
+
+	mock.ExpectQuery("SELECT series.id, (.+) AND posts.status = 'published' (.+) FROM series WHERE series.slug = \\$1").
+		WithArgs("go-basics").
+		WillReturnRows(sqlmock.NewRows(seriesColumns).AddRow("s1", "Go Basics", "go-basics", "", "1", 2, time.Now()))
+	expectSeriesParts(mock, "s1", []string{"p1", "Setup", "setup"}, []string{"p2", "Types", "types"})
+
+	series, err := repo.FindBySlug("go-basics")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Go Basics", series.Title)
+	assert.Len(t, series.Parts, 2)
+	assert.Equal(t, 1, series.Parts[0].Part)
+	assert.Equal(t, 2, series.Parts[1].Part)
+	assert.Equal(t, "types", series.Parts[1].Slug)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSeriesRepository_FindByPost(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewSeriesRepository(db)
+
+	mock.ExpectQuery("SELECT series_id FROM series_posts WHERE post_id = \\$1").
+		WithArgs("p2").
+		WillReturnRows(sqlmock.NewRows([]string{"series_id"}).AddRow("s1"))
+	mock.ExpectQuery("SELECT series.id, (.+) FROM series WHERE series.id = \\$1").
+		WithArgs("s1").
+		WillReturnRows(sqlmock.NewRows(seriesColumns).AddRow("s1", "Go Basics", "go-basics", "", "1", 3, time.Now()))
+	expectSeriesParts(mock, "s1", []string{"p1", "Setup", "setup"}, []string{"p2", "Types", "types"}, []string{"p3", "Generics", "generics"})
+
+	series, err := repo.FindByPost("p2")
+	assert.NoError(t, err)
+	assert.NotNil(t, series)
+
+	navigation := series.Navigation("p2")
+	assert.Equal(t, "go-basics", navigation.Slug)
+	assert.Equal(t, 2, navigation.Part)
+	assert.Equal(t, 3, navigation.Total)
+	assert.Equal(t, "setup", navigation.Prev.Slug)
+	assert.Equal(t, "generics", navigation.Next.Slug)
+
+	first := series.Navigation("p1")
+	assert.Nil(t, first.Prev)
+	assert.Equal(t, "types", first.Next.Slug)
+
+	assert.Nil(t, series.Navigation("unknown"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSeriesRepository_FindByPostWithoutSeries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewSeriesRepository(db)
+
+	mock.ExpectQuery("SELECT series_id FROM series_posts WHERE post_id = \\$1").
+		WithArgs("p1").
+		WillReturnError(sql.ErrNoRows)
+
+	series, err := repo.FindByPost("p1")
+
+	assert.NoError(t, err)
+	assert.Nil(t, series)
+}
+
+func TestSeriesRepository_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewSeriesRepository(db)
+
+	mock.ExpectBegin()
+	expectTakenSlugs(mock, "series", "go-basics")
+	mock.ExpectQuery("INSERT INTO series").
+		WithArgs("Go Basics", "go-basics-1", "Learn Go", "1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "slug", "description", "user_id", "created_at"}).
+			AddRow("s1", "Go Basics", "go-basics-1", "Learn Go", "1", time.Now()))
+	mock.ExpectCommit()
+
+	series, err := repo.Create(types.Series{Title: "Go Basics", Slug: "go-basics", Description: "Learn Go", OwnerId: "1"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "go-basics-1", series.Slug)
+	assert.Empty(t, series.Parts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSeriesRepository_UpdateSeriesPosts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewSeriesRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM series_posts").WithArgs("s1").WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("INSERT INTO series_posts").WithArgs("s1", "p2", 0).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO series_posts").WithArgs("s1", "p1", 1).WillReturnResult(sqlmock.NewResult(1, 1))
+	expectAllSeriesParts(mock, "s1", []string{"p2", "Types", "types"}, []string{"p1", "Setup", "setup"})
+	mock.ExpectCommit()
+
+	parts, err := repo.UpdateSeriesPosts("s1", []string{"p2", "p1"})
+
+	assert.NoError(t, err)
+	assert.Len(t, parts, 2)
+	assert.Equal(t, "p2", parts[0].Id)
+	assert.Equal(t, 2, parts[1].Part)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSeriesRepository_UpdateSeriesPostsInOtherSeries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewSeriesRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM series_posts").WithArgs("s1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO series_posts").WithArgs("s1", "p9", 0).
+		WillReturnError(errors.New("duplicate key value violates unique constraint \"series_posts_post_id_key\""))
+	mock.ExpectRollback()
+
+	_, err = repo.UpdateSeriesPosts("s1", []string{"p9"})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "error adding post p9 to series")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,87 @@
+package repository_test
+
+import (
+	"go-blog/internal/repository"
+	"go-blog/internal/types"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTagRepository_FindAll(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewTagRepository(db)
+
+	rows := sqlmock.NewRows([]string{"id", "name", "slug", "created_at", "post_count"}).
+		AddRow("1", "Go", "go", time.Now(), 12).
+		AddRow("2", "Fiber", "fiber", time.Now(), 3)
+
+	mock.ExpectQuery("SELECT tags.id, tags.name, tags.slug, tags.created_at, COUNT\\(post_tags.post_id\\) AS post_count FROM tags " +
+		"JOIN post_tags ON tags.id = post_tags.tag_id JOIN posts ON posts.id = post_tags.post_id " +
+		"WHERE posts.deleted_at IS NULL AND posts.status = 'published' AND posts.visibility <> 'unlisted' GROUP BY tags.id").WillReturnRows(rows)
+
+	tags, err := repo.FindAll()
+
+	assert.NoError(t, err)
+	assert.Len(t, tags, 2)
+	assert.Equal(t, "go", tags[0].Slug)
+	assert.Equal(t, 12, tags[0].PostCount)
+	assert.Equal(t, 3, tags[1].PostCount)
+}
+
+func TestTagRepository_Rename(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewTagRepository(db)
+
+	t.Run("Renamed", func(t *testing.T) {
+		mock.ExpectQuery("SELECT EXISTS").WithArgs("golang", "1").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectQuery("UPDATE tags").WithArgs("Golang", "golang", "1").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "slug", "created_at"}).AddRow("1", "Golang", "golang", time.Now()))
+
+		tag, err := repo.Rename("1", types.Tag{Name: "Golang", Slug: "golang"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "golang", tag.Slug)
+	})
+
+	t.Run("Slug taken", func(t *testing.T) {
+		mock.ExpectQuery("SELECT EXISTS").WithArgs("fiber", "1").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+		_, err := repo.Rename("1", types.Tag{Name: "Fiber", Slug: "fiber"})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "merge the tags instead")
+	})
+}
+
+func TestTagRepository_Merge(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewTagRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO post_tags (.+) SELECT post_id, \\$1 FROM post_tags WHERE tag_id = \\$2").WithArgs("2", "1").WillReturnResult(sqlmock.NewResult(0, 4))
+	mock.ExpectExec("DELETE FROM tags").WithArgs("1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = repo.Merge("1", "2")
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
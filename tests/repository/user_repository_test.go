@@ -47,8 +47,8 @@ func TestUserRepository_FindByEmail(t *testing.T) {
 
 	repo := repository.NewUserRepository(db)
 
-	rows := sqlmock.NewRows([]string{"id", "name", "lastname", "email", "password", "created_at"}).
-		AddRow("1", "John", "Doe", "john@example.com", "hashedpassword", time.Now())
+	rows := sqlmock.NewRows([]string{"id", "name", "lastname", "email", "password", "role", "created_at"}).
+		AddRow("1", "John", "Doe", "john@example.com", "hashedpassword", "user", time.Now())
 
 	mock.ExpectQuery("SELECT (.+) FROM users").WithArgs("john@example.com").WillReturnRows(rows)
 
@@ -69,8 +69,8 @@ func TestUserRepository_FindById(t *testing.T) {
 
 	repo := repository.NewUserRepository(db)
 
-	rows := sqlmock.NewRows([]string{"id", "name", "lastname", "email", "created_at"}).
-		AddRow("1", "John", "Doe", "john@example.com", time.Now())
+	rows := sqlmock.NewRows([]string{"id", "name", "lastname", "email", "role", "created_at"}).
+		AddRow("1", "John", "Doe", "john@example.com", "editor", time.Now())
 
 	mock.ExpectQuery("SELECT (.+) FROM users").WithArgs("1").WillReturnRows(rows)
 
@@ -80,6 +80,7 @@ func TestUserRepository_FindById(t *testing.T) {
 	assert.NotNil(t, user)
 	assert.Equal(t, "John", user.Name)
 	assert.Equal(t, "1", user.Id)
+	assert.Equal(t, "editor", user.Role)
 }
 
 func TestUserRepository_Create(t *testing.T) {
@@ -150,6 +151,43 @@ func TestUserRepository_Update(t *testing.T) {
 	}
 }
 
+func TestUserRepository_Patch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewUserRepository(db)
+
+	userID := uuid.New().String()
+	columns := []string{"id", "name", "lastname", "email", "role", "created_at"}
+
+	// Only the lastname changed, so the email isn't checked again
+	mock.ExpectQuery("SELECT id, name, lastname, email, role, created_at FROM users WHERE id = \\$1").
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(userID, "John", "Doe", "john@example.com", "user", time.Now()))
+	mock.ExpectExec("UPDATE users SET lastname = \\$1 WHERE id = \\$2").
+		WithArgs("Roe", userID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	result, err := repo.Patch(userID, types.User{Id: userID, Name: "John", Lastname: "Roe", Email: "john@example.com"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Roe", result.Lastname)
+
+	// Nothing changed, nothing is written
+	mock.ExpectQuery("SELECT id, name, lastname, email, role, created_at FROM users WHERE id = \\$1").
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(userID, "John", "Roe", "john@example.com", "user", time.Now()))
+
+	result, err = repo.Patch(userID, types.User{Id: userID, Name: "John", Lastname: "Roe", Email: "john@example.com"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "user", result.Role)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestUserRepository_Delete(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
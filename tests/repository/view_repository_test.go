@@ -0,0 +1,134 @@
+package repository_test
+
+import (
+	"go-blog/internal/repository"
+	"go-blog/internal/types"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestViewRepository_SaveViews(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewViewRepository(db)
+
+	day := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	views := []types.PostView{
+		{PostId: "1", Day: day, VisitorHash: "abc", ReferrerDomain: "example.com"},
+		{PostId: "2", Day: day, VisitorHash: "def"},
+	}
+
+	mock.ExpectExec("INSERT INTO post_views \\(post_id,day,visitor_hash,referrer_domain\\) VALUES \\(\\$1,\\$2,\\$3,\\$4\\),\\(\\$5,\\$6,\\$7,\\$8\\) ON CONFLICT DO NOTHING").
+		WithArgs("1", "2026-10-18", "abc", "example.com", "2", "2026-10-18", "def", nil).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	err = repo.SaveViews(views)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestViewRepository_SaveViews_Empty(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewViewRepository(db)
+
+	err = repo.SaveViews(nil)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestViewRepository_FindPostStats(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewViewRepository(db)
+
+	from := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+
+	dailyRows := sqlmock.NewRows([]string{"post_id", "day", "count"}).
+		AddRow("1", from, 3).
+		AddRow("1", to, 2)
+
+	mock.ExpectQuery("SELECT post_id, day, COUNT\\(\\*\\) FROM post_views WHERE post_id IN \\(\\$1\\) AND day BETWEEN \\$2 AND \\$3 GROUP BY post_id, day").
+		WithArgs("1", "2026-10-16", "2026-10-18").
+		WillReturnRows(dailyRows)
+
+	referrerRows := sqlmock.NewRows([]string{"domain", "views"}).
+		AddRow("direct", 4).
+		AddRow("example.com", 1)
+
+	mock.ExpectQuery("SELECT COALESCE\\(referrer_domain, 'direct'\\) AS domain, COUNT\\(\\*\\) AS views FROM post_views").
+		WithArgs("1", "2026-10-16", "2026-10-18").
+		WillReturnRows(referrerRows)
+
+	stats, err := repo.FindPostStats("1", from, to)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 5, stats.TotalViews)
+	assert.Equal(t, []types.DailyViews{
+		{Date: "2026-10-16", Views: 3},
+		{Date: "2026-10-17", Views: 0},
+		{Date: "2026-10-18", Views: 2},
+	}, stats.Series)
+	assert.Equal(t, []types.ReferrerViews{
+		{Domain: "direct", Views: 4},
+		{Domain: "example.com", Views: 1},
+	}, stats.Referrers)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestViewRepository_FindTopPosts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewViewRepository(db)
+
+	from := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+
+	topRows := sqlmock.NewRows([]string{"id", "title", "slug", "views"}).
+		AddRow("1", "First Post", "first-post", 7).
+		AddRow("2", "Second Post", "second-post", 1)
+
+	mock.ExpectQuery("SELECT posts.id, posts.title, posts.slug, COUNT\\(\\*\\) AS views FROM post_views JOIN posts ON posts.id = post_views.post_id (.+) LIMIT 5").
+		WithArgs("2026-10-17", "2026-10-18").
+		WillReturnRows(topRows)
+
+	dailyRows := sqlmock.NewRows([]string{"post_id", "day", "count"}).
+		AddRow("1", from, 3).
+		AddRow("1", to, 4).
+		AddRow("2", to, 1)
+
+	mock.ExpectQuery("SELECT post_id, day, COUNT\\(\\*\\) FROM post_views WHERE post_id IN \\(\\$1,\\$2\\)").
+		WithArgs("1", "2", "2026-10-17", "2026-10-18").
+		WillReturnRows(dailyRows)
+
+	topPosts, err := repo.FindTopPosts(from, to, 5)
+
+	assert.NoError(t, err)
+	assert.Len(t, topPosts, 2)
+	assert.Equal(t, 7, topPosts[0].Views)
+	assert.Equal(t, []types.DailyViews{{Date: "2026-10-17", Views: 3}, {Date: "2026-10-18", Views: 4}}, topPosts[0].Series)
+	assert.Equal(t, []types.DailyViews{{Date: "2026-10-17", Views: 0}, {Date: "2026-10-18", Views: 1}}, topPosts[1].Series)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,59 @@
+package repository_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"go-blog/internal/repository"
+	"go-blog/internal/types"
+)
+
+func TestWordPressImportRepository_FindImported(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewWordPressImportRepository(db)
+
+	mock.ExpectQuery("SELECT local_id FROM wordpress_imports WHERE").
+		WithArgs(types.WordPressPost, "example.com", int64(11)).
+		WillReturnRows(sqlmock.NewRows([]string{"local_id"}).AddRow("p1"))
+	mock.ExpectQuery("SELECT local_id FROM wordpress_imports WHERE").
+		WithArgs(types.WordPressPost, "example.com", int64(12)).
+		WillReturnError(sql.ErrNoRows)
+
+	localId, err := repo.FindImported("example.com", types.WordPressPost, 11)
+	assert.NoError(t, err)
+	assert.Equal(t, "p1", localId)
+
+	// Not imported yet isn't an error
+	localId, err = repo.FindImported("example.com", types.WordPressPost, 12)
+	assert.NoError(t, err)
+	assert.Empty(t, localId)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWordPressImportRepository_RecordImport(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewWordPressImportRepository(db)
+
+	mock.ExpectExec("INSERT INTO wordpress_imports (.+) ON CONFLICT \\(site, kind, wp_id\\) DO UPDATE").
+		WithArgs("example.com", types.WordPressCategory, int64(3), "c1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.RecordImport("example.com", types.WordPressCategory, 3, "c1")
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
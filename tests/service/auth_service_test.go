@@ -54,6 +54,14 @@ func (m *MockUserRepository) Update(id string, user types.User) (*types.User, er
 	return args.Get(0).(*types.User), args.Error(1)
 }
 
+func (m *MockUserRepository) Patch(id string, user types.User) (*types.User, error) {
+	args := m.Called(id, user)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.User), args.Error(1)
+}
+
 func (m *MockUserRepository) Delete(id string) error {
 	args := m.Called(id)
 	return args.Error(0)
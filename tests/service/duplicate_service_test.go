@@ -0,0 +1,122 @@
+package service_test
+
+import (
+	"go-blog/internal/service"
+	"go-blog/internal/types"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockDuplicateRepository struct {
+	mock.Mock
+}
+
+func (m *MockDuplicateRepository) FindUnfingerprinted() ([]types.Post, error) {
+	args := m.Called()
+	return args.Get(0).([]types.Post), args.Error(1)
+}
+
+func (m *MockDuplicateRepository) SaveFingerprint(postId string, fingerprint uint64) error {
+	args := m.Called(postId, fingerprint)
+	return args.Error(0)
+}
+
+func (m *MockDuplicateRepository) FindNearDuplicates(postId string, fingerprint uint64, maxDistance int, readerId string) ([]types.Duplicate, error) {
+	args := m.Called(postId, fingerprint, maxDistance, readerId)
+	return args.Get(0).([]types.Duplicate), args.Error(1)
+}
+
+func (m *MockDuplicateRepository) FindFingerprinted() ([]types.Duplicate, error) {
+	args := m.Called()
+	return args.Get(0).([]types.Duplicate), args.Error(1)
+}
+
+const (
+	syndicatedArticle = "The city council voted on Tuesday to expand the network of protected bike lanes across the downtown area. " +
+		"Supporters said the plan would make cycling safer for commuters and reduce traffic, while opponents worried about the loss of parking spaces. " +
+		"Construction of the first lanes is expected to begin next spring and to last about eighteen months, according to the transport department."
+	otherArticle = "Our favourite sourdough recipe needs only flour, water and salt, plus a lot of patience. " +
+		"Feed the starter the night before, mix the dough in the morning and let it rise slowly in a cool kitchen. " +
+		"Bake it in a very hot oven with a tray of water underneath, so that the crust turns crisp and golden."
+)
+
+func TestContentFingerprint(t *testing.T) {
+	fingerprint := types.ContentFingerprint(syndicatedArticle)
+
+	// Markup, case and punctuation aren't part of the content
+	assert.Equal(t, fingerprint, types.ContentFingerprint("**The CITY council** voted on [Tuesday](https://example.com) to expand the network of protected bike lanes across the downtown area! "+
+		syndicatedArticle[len("The city council voted on Tuesday to expand the network of protected bike lanes across the downtown area. "):]))
+
+	// A republished copy with a credit line stays close, another article doesn't
+	assert.GreaterOrEqual(t, types.FingerprintSimilarity(fingerprint, types.ContentFingerprint(syndicatedArticle+" Originally published by the Daily Herald.")), 0.9)
+	assert.Less(t, types.FingerprintSimilarity(fingerprint, types.ContentFingerprint(otherArticle)), 0.9)
+
+	assert.Zero(t, types.ContentFingerprint("![](/files/u1/photo.jpg)"))
+}
+
+func TestDuplicateService_FindNearDuplicates(t *testing.T) {
+	t.Setenv("DUPLICATE_SIMILARITY", "0.95")
+	repo := new(MockDuplicateRepository)
+	duplicateService := service.NewDuplicateService(repo)
+
+	copied := types.ContentFingerprint(syndicatedArticle)
+	// Posts saved before posts had a fingerprint are fingerprinted first, once
+	repo.On("FindUnfingerprinted").Return([]types.Post{{Id: "p1", Content: syndicatedArticle}, {Id: "p2", Content: "![](/files/u1/photo.jpg)"}}, nil).Once()
+	repo.On("SaveFingerprint", "p1", copied).Return(nil).Once()
+	repo.On("FindNearDuplicates", "p3", copied, 3, "u1").Return([]types.Duplicate{{Id: "p1", Fingerprint: copied ^ 1}}, nil).Twice()
+
+	duplicates, err := duplicateService.FindNearDuplicates("p3", syndicatedArticle, "u1")
+	require.NoError(t, err)
+	assert.Len(t, duplicates, 1)
+	assert.InDelta(t, 1-1.0/64, duplicates[0].Similarity, 1e-9)
+
+	_, err = duplicateService.FindNearDuplicates("p3", syndicatedArticle, "u1")
+	require.NoError(t, err)
+
+	// Content without words duplicates nothing
+	duplicates, err = duplicateService.FindNearDuplicates("p4", "", "u1")
+	require.NoError(t, err)
+	assert.Empty(t, duplicates)
+
+	repo.AssertExpectations(t)
+}
+
+func TestDuplicateService_FindClusters(t *testing.T) {
+	repo := new(MockDuplicateRepository)
+	duplicateService := service.NewDuplicateService(repo)
+
+	day := func(d int) time.Time { return time.Date(2026, 10, d, 0, 0, 0, 0, time.UTC) }
+	article, other := types.ContentFingerprint(syndicatedArticle), types.ContentFingerprint(otherArticle)
+	repo.On("FindUnfingerprinted").Return([]types.Post{}, nil).Once()
+	repo.On("FindFingerprinted").Return([]types.Duplicate{
+		{Id: "p1", CreatedAt: day(1), Fingerprint: article},
+		{Id: "p2", CreatedAt: day(2), Fingerprint: other},
+		{Id: "p3", CreatedAt: day(3), Fingerprint: article ^ 0b11},
+		{Id: "p4", CreatedAt: day(4), Fingerprint: other ^ 0b1},
+		{Id: "p5", CreatedAt: day(5), Fingerprint: ^article},
+		// Only near p3, so in its cluster
+		{Id: "p6", CreatedAt: day(6), Fingerprint: article ^ 0b11 ^ 0b11111100},
+	}, nil).Once()
+
+	clusters, err := duplicateService.FindClusters()
+	require.NoError(t, err)
+
+	require.Len(t, clusters, 2)
+	ids := func(cluster types.DuplicateCluster) []string {
+		var ids []string
+		for _, post := range cluster.Posts {
+			ids = append(ids, post.Id)
+		}
+		return ids
+	}
+	// The cluster with the latest duplicate comes first, each from its oldest post
+	assert.Equal(t, []string{"p1", "p3", "p6"}, ids(clusters[0]))
+	assert.Equal(t, []string{"p2", "p4"}, ids(clusters[1]))
+	assert.Equal(t, 1.0, clusters[0].Posts[0].Similarity)
+	assert.InDelta(t, 1-8.0/64, clusters[0].Posts[2].Similarity, 1e-9)
+	repo.AssertExpectations(t)
+}
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"go-blog/internal/service"
 	"go-blog/internal/types"
+	"image"
+	"image/png"
 	"mime/multipart"
 	"net/http"
 	"os"
@@ -104,3 +106,41 @@ func TestDeleteFile(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "file not found")
 }
+
+// formFile builds the multipart header of an upload named filename holding content.
+func formFile(t *testing.T, filename string, content []byte) *multipart.FileHeader {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	part, err := writer.CreateFormFile("file", filename)
+	require.NoError(t, err)
+
+	_, err = part.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req, err := http.NewRequest("POST", "/upload", body)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	require.NoError(t, req.ParseMultipartForm(32<<20))
+
+	_, header, err := req.FormFile("file")
+	require.NoError(t, err)
+
+	return header
+}
+
+func TestImageSize(t *testing.T) {
+	fs := service.NewFileService()
+
+	img := &bytes.Buffer{}
+	require.NoError(t, png.Encode(img, image.NewRGBA(image.Rect(0, 0, 40, 20))))
+
+	width, height := fs.ImageSize(formFile(t, "cover.png", img.Bytes()))
+	assert.Equal(t, 40, width)
+	assert.Equal(t, 20, height)
+
+	width, height = fs.ImageSize(formFile(t, "notes.txt", []byte("not an image")))
+	assert.Zero(t, width)
+	assert.Zero(t, height)
+}
@@ -0,0 +1,168 @@
+package service_test
+
+import (
+	"database/sql"
+	"errors"
+	"go-blog/internal/service"
+	"go-blog/internal/types"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockGitSyncRepository struct {
+	mock.Mock
+}
+
+func (m *MockGitSyncRepository) FindAll() ([]types.GitSyncedPost, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.GitSyncedPost), args.Error(1)
+}
+
+func (m *MockGitSyncRepository) Save(syncedPost types.GitSyncedPost) error {
+	args := m.Called(syncedPost)
+	return args.Error(0)
+}
+
+func (m *MockGitSyncRepository) Delete(path string) error {
+	args := m.Called(path)
+	return args.Error(0)
+}
+
+// gitCommand runs git in dir and returns its trimmed output.
+func gitCommand(t *testing.T, dir string, args ...string) string {
+	cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...)
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+	return strings.TrimSpace(string(output))
+}
+
+// newGitRepository commits the given files, by path, to the main branch of a new
+// repository.
+func newGitRepository(t *testing.T, files map[string]string) string {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	gitCommand(t, dir, "init", "--quiet", "--initial-branch=main")
+	for name, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	gitCommand(t, dir, "add", "--all")
+	gitCommand(t, dir, "commit", "--quiet", "-m", "Add posts")
+
+	return dir
+}
+
+func TestGitSyncService_Sync(t *testing.T) {
+	repoPath := newGitRepository(t, map[string]string{
+		"posts/fresh.md":   "---\ntitle: New post\n---\n\nNew content\n",
+		"posts/changed.md": "---\ntitle: Changed post\n---\n\nChanged content\n",
+		"posts/same.md":    "---\ntitle: Same post\n---\n\nSame content\n",
+		"README.md":        "Not a post, it has no front matter",
+	})
+	commit := gitCommand(t, repoPath, "rev-parse", "main")
+	sameBlob := gitCommand(t, repoPath, "rev-parse", "main:posts/same.md")
+
+	gitSyncRepository := new(MockGitSyncRepository)
+	postRepository := new(MockPostRepository)
+	categoryRepository := new(MockCategoryRepository)
+	syncService := service.NewGitSyncService(gitSyncRepository, postRepository, categoryRepository, new(MockUserRepository), service.NewSlugService())
+	author := &types.User{Id: "u1"}
+
+	gitSyncRepository.On("FindAll").Return([]types.GitSyncedPost{
+		{Path: "posts/changed.md", PostId: "p2", BlobSha: "0000000000000000000000000000000000000000"},
+		{Path: "posts/removed.md", PostId: "p4", BlobSha: "1111111111111111111111111111111111111111"},
+		{Path: "posts/same.md", PostId: "p3", BlobSha: sameBlob},
+	}, nil)
+	categoryRepository.On("FindAll").Return([]types.Category{}, nil)
+
+	// A new file creates a post
+	postRepository.On("FindBySlug", "fresh", types.DefaultLocale()).Return(nil, sql.ErrNoRows)
+	postRepository.On("Create", mock.MatchedBy(func(post types.Post) bool {
+		return post.Title == "New post" && post.Slug == "fresh" && post.Author.Id == "u1"
+	})).Return(&types.Post{Id: "p1"}, nil).Once()
+	gitSyncRepository.On("Save", mock.MatchedBy(func(syncedPost types.GitSyncedPost) bool {
+		return syncedPost.Path == "posts/fresh.md" && syncedPost.PostId == "p1" && len(syncedPost.BlobSha) == 40 && syncedPost.CommitSha == commit
+	})).Return(nil).Once()
+
+	// A changed file updates the post it was synced as, whatever its slug
+	changedPost := &types.Post{Id: "p2", Title: "Old title", Slug: "renamed", Locale: types.DefaultLocale(), Content: "Old content", Version: 1}
+	postRepository.On("FindById", "p2").Return(changedPost, nil)
+	postRepository.On("Patch", "p2", mock.MatchedBy(func(post types.Post) bool {
+		return post.Title == "Changed post" && post.Slug == "renamed" && post.Content == "Changed content"
+	})).Return(&types.Post{Id: "p2", Version: 2}, nil).Once()
+	gitSyncRepository.On("Save", mock.MatchedBy(func(syncedPost types.GitSyncedPost) bool {
+		return syncedPost.Path == "posts/changed.md" && syncedPost.PostId == "p2" && syncedPost.CommitSha == commit
+	})).Return(nil).Once()
+
+	// A removed file archives its post
+	removedPost := &types.Post{Id: "p4", Title: "Removed", Status: types.PostStatusPublished, Version: 5}
+	postRepository.On("FindById", "p4").Return(removedPost, nil)
+	postRepository.On("Patch", "p4", mock.MatchedBy(func(post types.Post) bool {
+		return post.Status == types.PostStatusArchived && post.Version == 5
+	})).Return(&types.Post{Id: "p4", Version: 6}, nil).Once()
+	gitSyncRepository.On("Delete", "posts/removed.md").Return(nil).Once()
+
+	report, err := syncService.Sync(repoPath, "main", author)
+
+	require.NoError(t, err)
+	assert.Equal(t, commit, report.Commit)
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, 1, report.Updated)
+	assert.Equal(t, 1, report.Unchanged)
+	assert.Equal(t, 1, report.Archived)
+	assert.Empty(t, report.Errors)
+	gitSyncRepository.AssertExpectations(t)
+	postRepository.AssertExpectations(t)
+}
+
+func TestGitSyncService_SyncWithoutAuthor(t *testing.T) {
+	repoPath := newGitRepository(t, map[string]string{
+		"posts/fresh.md": "---\ntitle: New post\nauthor_email: nobody@example.com\n---\n\nNew content\n",
+	})
+
+	gitSyncRepository := new(MockGitSyncRepository)
+	postRepository := new(MockPostRepository)
+	categoryRepository := new(MockCategoryRepository)
+	userRepository := new(MockUserRepository)
+	syncService := service.NewGitSyncService(gitSyncRepository, postRepository, categoryRepository, userRepository, service.NewSlugService())
+
+	gitSyncRepository.On("FindAll").Return([]types.GitSyncedPost{}, nil)
+	categoryRepository.On("FindAll").Return([]types.Category{}, nil)
+	userRepository.On("FindByEmail", "nobody@example.com").Return(nil, sql.ErrNoRows)
+	postRepository.On("FindBySlug", "fresh", types.DefaultLocale()).Return(nil, sql.ErrNoRows)
+
+	// Nothing is created, and the file is retried on the next sync
+	report, err := syncService.Sync(repoPath, "main", nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, report.Created)
+	assert.Equal(t, []string{`posts/fresh.md: unknown author "nobody@example.com"`}, report.Errors)
+	gitSyncRepository.AssertNotCalled(t, "Save", mock.Anything)
+}
+
+func TestGitSyncService_SyncInvalidRepository(t *testing.T) {
+	repoPath := newGitRepository(t, map[string]string{"README.md": "Readme"})
+	syncService := service.NewGitSyncService(new(MockGitSyncRepository), new(MockPostRepository), new(MockCategoryRepository), new(MockUserRepository), service.NewSlugService())
+
+	_, err := syncService.Sync(repoPath, "missing", nil)
+	assert.True(t, errors.Is(err, service.ErrInvalidRepository))
+
+	_, err = syncService.Sync(t.TempDir(), "main", nil)
+	assert.True(t, errors.Is(err, service.ErrInvalidRepository))
+
+	_, err = syncService.Sync(repoPath, "--output=/tmp/x", nil)
+	assert.True(t, errors.Is(err, service.ErrInvalidRepository))
+}
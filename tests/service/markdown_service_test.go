@@ -0,0 +1,272 @@
+package service_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"database/sql"
+	"errors"
+	"go-blog/internal/service"
+	"go-blog/internal/types"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type markdownMocks struct {
+	posts      *MockPostRepository
+	categories *MockCategoryRepository
+	users      *MockUserRepository
+}
+
+func newMarkdownService() (service.MarkdownService, markdownMocks) {
+	mocks := markdownMocks{
+		posts:      new(MockPostRepository),
+		categories: new(MockCategoryRepository),
+		users:      new(MockUserRepository),
+	}
+
+	return service.NewMarkdownService(mocks.posts, mocks.categories, mocks.users, service.NewSlugService()), mocks
+}
+
+// zipFiles returns a zip archive of the given files, by name.
+func zipFiles(t *testing.T, files map[string]string) []byte {
+	var archive bytes.Buffer
+	writer := zip.NewWriter(&archive)
+	for name, content := range files {
+		file, err := writer.Create(name)
+		require.NoError(t, err)
+		_, err = file.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	return archive.Bytes()
+}
+
+func TestMarkdownService_Export(t *testing.T) {
+	markdownService, mocks := newMarkdownService()
+
+	date := time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC)
+	mocks.posts.On("FindByFilter", types.PostFilter{}, 0).Return([]types.Post{
+		{
+			Id: "p1", Title: "Hello world", Slug: "hello-world", Locale: types.DefaultLocale(), Status: types.PostStatusPublished,
+			Content: "Some **content**", Excerpt: "Some content", CreatedAt: date, UpdatedAt: date,
+			Author:     types.User{Name: "Jane", Lastname: "Doe", Email: "jane@example.com"},
+			Categories: []types.Category{{Id: "c1", Title: "News", Slug: "news"}},
+			Tags:       []types.Tag{{Name: "Go", Slug: "go"}},
+		},
+		{Id: "p2", Title: "Bonjour", Slug: "bonjour", Locale: "fr", Status: types.PostStatusDraft, Content: "Du contenu", CreatedAt: date, UpdatedAt: date},
+	}, nil)
+
+	var archive bytes.Buffer
+	require.NoError(t, markdownService.Export(&archive))
+
+	reader, err := zip.NewReader(bytes.NewReader(archive.Bytes()), int64(archive.Len()))
+	require.NoError(t, err)
+	require.Len(t, reader.File, 2)
+	assert.Equal(t, "posts/hello-world.md", reader.File[0].Name)
+	assert.Equal(t, "posts/bonjour.fr.md", reader.File[1].Name)
+
+	file, err := reader.File[0].Open()
+	require.NoError(t, err)
+	content, err := io.ReadAll(file)
+	require.NoError(t, err)
+
+	assert.Equal(t, `---
+title: Hello world
+slug: hello-world
+date: 2024-01-31T10:00:00Z
+lastmod: 2024-01-31T10:00:00Z
+author: Jane Doe
+author_email: jane@example.com
+description: Some content
+categories:
+    - News
+tags:
+    - Go
+---
+
+Some **content**
+`, string(content))
+
+	file, err = reader.File[1].Open()
+	require.NoError(t, err)
+	content, err = io.ReadAll(file)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "\ndraft: true\n")
+}
+
+func TestMarkdownService_Import(t *testing.T) {
+	markdownService, mocks := newMarkdownService()
+	importer := types.User{Id: "u1", Email: "admin@example.com"}
+
+	mocks.categories.On("FindAll").Return([]types.Category{{Id: "c1", Title: "News", Slug: "news"}}, nil)
+	mocks.categories.On("Create", types.Category{Title: "Travel", Slug: "travel"}).
+		Return(&types.Category{Id: "c2", Title: "Travel", Slug: "travel"}, nil).Once()
+	mocks.users.On("FindByEmail", "jane@example.com").Return(&types.User{Id: "u2", Email: "jane@example.com"}, nil)
+
+	// A new Jekyll post, named after its date and with space separated categories
+	mocks.posts.On("FindBySlug", "new-post", types.DefaultLocale()).Return(nil, sql.ErrNoRows)
+	mocks.posts.On("Create", mock.MatchedBy(func(post types.Post) bool {
+		return post.Title == "New post" && post.Slug == "new-post" && post.Status == types.PostStatusDraft &&
+			post.Content == "Some *new* content" && post.Author.Id == "u2" &&
+			post.CreatedAt.Equal(time.Date(2024, 2, 1, 8, 30, 0, 0, time.UTC))
+	})).Return(&types.Post{Id: "p2", Slug: "new-post"}, nil).Once()
+	mocks.posts.On("UpdatePostCategories", "p2", []string{"c1", "c2"}).Return(nil).Once()
+	mocks.posts.On("UpdatePostTags", "p2", []types.Tag{{Name: "Go", Slug: "go"}, {Name: "fiber", Slug: "fiber"}}).Return([]types.Tag{}, nil).Once()
+
+	// An existing post whose content changes, but not its categories and tags
+	existingPost := &types.Post{
+		Id: "p1", Title: "Hello world", Slug: "hello-world", Locale: types.DefaultLocale(), Status: types.PostStatusPublished,
+		Content: "Old content", Version: 3, Author: types.User{Id: "u3"},
+		Categories: []types.Category{{Id: "c1", Title: "News", Slug: "news"}},
+	}
+	mocks.posts.On("FindBySlug", "hello-world", types.DefaultLocale()).Return(existingPost, nil)
+	mocks.posts.On("Patch", "p1", mock.MatchedBy(func(post types.Post) bool {
+		return post.Content == "New content" && post.Version == 3 && post.Author.Id == "u3" && post.Excerpt == "New content"
+	})).Return(&types.Post{Id: "p1", Version: 4}, nil).Once()
+
+	archive := zipFiles(t, map[string]string{
+		"_posts/2024-02-01-new-post.md": "---\r\ntitle: New post\r\ndate: 2024-02-01 08:30:00\r\ndraft: true\r\nauthor_email: jane@example.com\r\ncategories: news Travel\r\ntags: [Go, fiber, go]\r\n---\r\n\r\nSome *new* content\r\n",
+		"posts/hello-world.md":          "---\ntitle: Hello world\nslug: hello-world\ndate: 2024-01-31\ncategories:\n  - News\n---\n\nNew content\n",
+		"posts/broken.md":               "No front matter",
+		"posts/notes.txt":               "Not a post",
+		"__MACOSX/posts/._broken.md":    "Resource fork",
+	})
+
+	report, err := markdownService.Import(bytes.NewReader(archive), int64(len(archive)), importer)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, 1, report.Updated)
+	assert.Equal(t, 0, report.Unchanged)
+	assert.Equal(t, []string{"posts/broken.md: no YAML front matter"}, report.Errors)
+	mocks.posts.AssertExpectations(t)
+	mocks.categories.AssertExpectations(t)
+}
+
+func TestMarkdownService_ImportUnchanged(t *testing.T) {
+	markdownService, mocks := newMarkdownService()
+
+	date := time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC)
+	post := types.Post{
+		Id: "p1", Title: "Bonjour", Slug: "bonjour", Locale: "fr", Status: types.PostStatusPublished,
+		Content: "Du contenu", Excerpt: "Du contenu", WordCount: 2, ReadingTimeMinutes: 1, CreatedAt: date, UpdatedAt: date, Version: 2,
+		Tags: []types.Tag{{Name: "Go", Slug: "go"}},
+	}
+	mocks.posts.On("FindByFilter", types.PostFilter{}, 0).Return([]types.Post{post}, nil)
+	mocks.categories.On("FindAll").Return([]types.Category{}, nil)
+	mocks.posts.On("FindBySlug", "bonjour", "fr").Return(&post, nil)
+	mocks.posts.On("Patch", "p1", post).Return(&post, nil).Once()
+
+	// Importing an export changes nothing
+	var archive bytes.Buffer
+	require.NoError(t, markdownService.Export(&archive))
+	report, err := markdownService.Import(bytes.NewReader(archive.Bytes()), int64(archive.Len()), types.User{Id: "u1"})
+
+	require.NoError(t, err)
+	assert.Equal(t, types.MarkdownImportReport{Unchanged: 1, Errors: []string{}}, *report)
+	mocks.posts.AssertExpectations(t)
+}
+
+// translatedPost returns a post with generated excerpts, translated in French.
+func translatedPost() (types.Post, types.PostTranslation) {
+	date := time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC)
+	post := types.Post{
+		Id: "p1", Title: "Hello world", Slug: "hello-world", Locale: types.DefaultLocale(), Status: types.PostStatusPublished,
+		Content: "Some content", CreatedAt: date, UpdatedAt: date, Version: 2,
+		Translations: []types.Translation{{Locale: "fr", Title: "Bonjour", Slug: "bonjour"}},
+	}
+	post.Summarize()
+
+	translation := types.PostTranslation{Locale: "fr", Title: "Bonjour", Slug: "bonjour", Content: "Du contenu", UpdatedAt: date}
+	translation.Summarize()
+	return post, translation
+}
+
+func TestMarkdownService_ExportTranslations(t *testing.T) {
+	markdownService, mocks := newMarkdownService()
+
+	post, translation := translatedPost()
+	mocks.posts.On("FindByFilter", types.PostFilter{}, 0).Return([]types.Post{post}, nil)
+	mocks.posts.On("FindPostTranslation", "p1", "fr").Return(&translation, nil)
+
+	var archive bytes.Buffer
+	require.NoError(t, markdownService.Export(&archive))
+
+	reader, err := zip.NewReader(bytes.NewReader(archive.Bytes()), int64(archive.Len()))
+	require.NoError(t, err)
+	require.Len(t, reader.File, 2)
+	assert.Equal(t, "posts/hello-world.md", reader.File[0].Name)
+	// Translations are named after the post, as Hugo links them
+	assert.Equal(t, "posts/hello-world.fr.md", reader.File[1].Name)
+
+	for _, file := range reader.File {
+		src, err := file.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(src)
+		require.NoError(t, err)
+
+		// Generated excerpts are generated again on import
+		assert.NotContains(t, string(content), "description:")
+		if file.Name == "posts/hello-world.fr.md" {
+			assert.Contains(t, string(content), "\nslug: bonjour\n")
+			assert.Contains(t, string(content), "\nDu contenu\n")
+		}
+	}
+}
+
+func TestMarkdownService_ImportTranslations(t *testing.T) {
+	markdownService, mocks := newMarkdownService()
+
+	post, translation := translatedPost()
+	mocks.posts.On("FindByFilter", types.PostFilter{}, 0).Return([]types.Post{post}, nil)
+	mocks.categories.On("FindAll").Return([]types.Category{}, nil)
+	mocks.posts.On("FindBySlug", "hello-world", mock.Anything).Return(&post, nil)
+	mocks.posts.On("FindPostTranslation", "p1", "fr").Return(&translation, nil)
+	mocks.posts.On("FindPostTranslation", "p1", "de").Return(nil, sql.ErrNoRows)
+
+	// Importing an export changes neither the post nor its translation
+	mocks.posts.On("Patch", "p1", mock.MatchedBy(func(patched types.Post) bool {
+		return patched.Excerpt == post.Excerpt && patched.ExcerptGenerated
+	})).Return(&post, nil).Once()
+
+	var archive bytes.Buffer
+	require.NoError(t, markdownService.Export(&archive))
+	report, err := markdownService.Import(bytes.NewReader(archive.Bytes()), int64(archive.Len()), types.User{Id: "u1"})
+
+	require.NoError(t, err)
+	assert.Equal(t, types.MarkdownImportReport{Unchanged: 2, Errors: []string{}}, *report)
+
+	// Exports which kept the generated excerpt generate it again from the new content, and
+	// translations in other languages are created
+	mocks.posts.On("Patch", "p1", mock.MatchedBy(func(patched types.Post) bool {
+		return patched.Excerpt == "New content" && patched.ExcerptGenerated
+	})).Return(&types.Post{Id: "p1", Version: 3}, nil).Once()
+	mocks.posts.On("SavePostTranslation", "p1", mock.MatchedBy(func(saved types.PostTranslation) bool {
+		return saved.Locale == "de" && saved.Slug == "hallo-welt" && saved.Content == "Inhalt" && saved.Excerpt == "Inhalt"
+	})).Return(&types.PostTranslation{}, nil).Once()
+
+	stale := zipFiles(t, map[string]string{
+		"posts/hello-world.de.md": "---\ntitle: Hallo Welt\n---\n\nInhalt\n",
+		"posts/hello-world.md":    "---\ntitle: Hello world\nslug: hello-world\ndate: 2024-01-31\ndescription: Some content\n---\n\nNew content\n",
+	})
+	report, err = markdownService.Import(bytes.NewReader(stale), int64(len(stale)), types.User{Id: "u1"})
+
+	require.NoError(t, err)
+	assert.Equal(t, types.MarkdownImportReport{Created: 1, Updated: 1, Errors: []string{}}, *report)
+	mocks.posts.AssertExpectations(t)
+}
+
+func TestMarkdownService_ImportInvalidArchive(t *testing.T) {
+	markdownService, _ := newMarkdownService()
+
+	archive := strings.NewReader("not a zip")
+	_, err := markdownService.Import(archive, archive.Size(), types.User{Id: "u1"})
+
+	assert.True(t, errors.Is(err, service.ErrInvalidArchive))
+}
@@ -0,0 +1,81 @@
+package service_test
+
+import (
+	"errors"
+	"go-blog/internal/service"
+	"go-blog/internal/types"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// isUnlocked reports whether a request with the cookie unlocks the post.
+func isUnlocked(t *testing.T, postUnlockService service.PostUnlockService, post types.Post, cookie *fiber.Cookie) bool {
+	unlocked := false
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		unlocked = postUnlockService.IsUnlocked(c, post)
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	request := httptest.NewRequest("GET", "/", nil)
+	if cookie != nil {
+		request.Header.Set("Cookie", cookie.Name+"="+cookie.Value)
+	}
+	_, err := app.Test(request)
+	require.NoError(t, err)
+
+	return unlocked
+}
+
+func TestPostUnlockService_Unlock(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+	postUnlockService := service.NewPostUnlockService()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("open sesame"), bcrypt.MinCost)
+	require.NoError(t, err)
+	post := types.Post{Id: "p1", Visibility: types.VisibilityPassword, PasswordHash: string(hash)}
+
+	_, err = postUnlockService.Unlock(post, "wrong")
+	assert.True(t, errors.Is(err, service.ErrWrongPassword))
+
+	cookie, err := postUnlockService.Unlock(post, "open sesame")
+	require.NoError(t, err)
+	assert.Equal(t, "post_unlock_p1", cookie.Name)
+	assert.True(t, cookie.HTTPOnly)
+
+	assert.True(t, isUnlocked(t, postUnlockService, post, cookie))
+	assert.False(t, isUnlocked(t, postUnlockService, post, nil))
+
+	// The cookie neither unlocks other posts nor the post once its password changes
+	assert.False(t, isUnlocked(t, postUnlockService, types.Post{Id: "p2", PasswordHash: post.PasswordHash}, &fiber.Cookie{Name: "post_unlock_p2", Value: cookie.Value}))
+	assert.False(t, isUnlocked(t, postUnlockService, types.Post{Id: "p1", PasswordHash: "changed"}, cookie))
+}
+
+func TestPostUnlockService_TooManyAttempts(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+	t.Setenv("POST_UNLOCK_ATTEMPTS", "1")
+	postUnlockService := service.NewPostUnlockService()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("open sesame"), bcrypt.MinCost)
+	require.NoError(t, err)
+	post := types.Post{Id: "p1", Visibility: types.VisibilityPassword, PasswordHash: string(hash)}
+
+	// Each post takes ten times as many wrong passwords as each IP
+	for i := 0; i < 10; i++ {
+		_, err = postUnlockService.Unlock(post, "wrong")
+		assert.True(t, errors.Is(err, service.ErrWrongPassword))
+	}
+
+	// Then even the right password is refused, whatever slug or id the post was found by
+	_, err = postUnlockService.Unlock(post, "open sesame")
+	assert.True(t, errors.Is(err, service.ErrTooManyAttempts))
+
+	// Other posts are still unlocked
+	_, err = postUnlockService.Unlock(types.Post{Id: "p2", Visibility: types.VisibilityPassword, PasswordHash: string(hash)}, "open sesame")
+	assert.NoError(t, err)
+}
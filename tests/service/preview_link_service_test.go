@@ -0,0 +1,97 @@
+package service_test
+
+import (
+	"database/sql"
+	"errors"
+	"go-blog/internal/service"
+	"go-blog/internal/types"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockPreviewLinkRepository struct {
+	mock.Mock
+}
+
+func (m *MockPreviewLinkRepository) FindById(id string) (*types.PreviewLink, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.PreviewLink), args.Error(1)
+}
+
+func (m *MockPreviewLinkRepository) FindActiveByPost(postId string) ([]types.PreviewLink, error) {
+	args := m.Called(postId)
+	return args.Get(0).([]types.PreviewLink), args.Error(1)
+}
+
+func (m *MockPreviewLinkRepository) Create(link types.PreviewLink) (*types.PreviewLink, error) {
+	args := m.Called(link)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.PreviewLink), args.Error(1)
+}
+
+func (m *MockPreviewLinkRepository) Delete(postId string, id string) error {
+	args := m.Called(postId, id)
+	return args.Error(0)
+}
+
+func TestPreviewLinkService_CreateAndResolve(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+	mockRepo := new(MockPreviewLinkRepository)
+	previewLinkService := service.NewPreviewLinkService(mockRepo)
+
+	link := &types.PreviewLink{}
+	mockRepo.On("Create", mock.MatchedBy(func(l types.PreviewLink) bool {
+		// Links last 30 days at most
+		return l.PostId == "p1" && l.CreatedBy == "u1" && l.ExpiresAt.Before(time.Now().Add(31*24*time.Hour))
+	})).Run(func(args mock.Arguments) {
+		*link = args.Get(0).(types.PreviewLink)
+		link.Id = "l1"
+	}).Return(link, nil).Once()
+
+	created, err := previewLinkService.Create("p1", "u1", 365*24*time.Hour)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(created.Token, "l1."))
+
+	mockRepo.On("FindById", "l1").Return(link, nil)
+
+	resolved, err := previewLinkService.Resolve(created.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, "p1", resolved.PostId)
+
+	// Tokens can't be forged for another link or changed
+	_, err = previewLinkService.Resolve("l1." + strings.Repeat("0", 64))
+	assert.True(t, errors.Is(err, service.ErrInvalidPreviewToken))
+	_, err = previewLinkService.Resolve("l1")
+	assert.True(t, errors.Is(err, service.ErrInvalidPreviewToken))
+}
+
+func TestPreviewLinkService_ResolveExpiredOrRevoked(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secret")
+	mockRepo := new(MockPreviewLinkRepository)
+	previewLinkService := service.NewPreviewLinkService(mockRepo)
+
+	expired := types.PreviewLink{Id: "l1", PostId: "p1", ExpiresAt: time.Now().Add(-time.Minute).Truncate(time.Second)}
+	mockRepo.On("FindActiveByPost", "p1").Return([]types.PreviewLink{expired}, nil)
+	mockRepo.On("FindById", "l1").Return(&expired, nil)
+
+	links, err := previewLinkService.FindActiveByPost("p1")
+	require.NoError(t, err)
+
+	_, err = previewLinkService.Resolve(links[0].Token)
+	assert.True(t, errors.Is(err, service.ErrInvalidPreviewToken))
+
+	// Revoked links are gone
+	mockRepo.On("FindById", "l2").Return(nil, sql.ErrNoRows)
+	_, err = previewLinkService.Resolve("l2." + strings.Repeat("0", 64))
+	assert.True(t, errors.Is(err, service.ErrInvalidPreviewToken))
+}
@@ -0,0 +1,136 @@
+package service_test
+
+import (
+	"go-blog/internal/repository"
+	"go-blog/internal/service"
+	"go-blog/internal/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockPostRepository only implements the methods the services use, the others panic.
+type MockPostRepository struct {
+	repository.PostRepository
+	mock.Mock
+}
+
+func (m *MockPostRepository) FindRelated(postId string, limit int) ([]types.Post, error) {
+	args := m.Called(postId, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.Post), args.Error(1)
+}
+
+func (m *MockPostRepository) FindByFilter(filter types.PostFilter, limit int) ([]types.Post, error) {
+	args := m.Called(filter, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.Post), args.Error(1)
+}
+
+func (m *MockPostRepository) FindById(id string) (*types.Post, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.Post), args.Error(1)
+}
+
+func (m *MockPostRepository) FindBySlug(slug string, locale string) (*types.Post, error) {
+	args := m.Called(slug, locale)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.Post), args.Error(1)
+}
+
+func (m *MockPostRepository) Create(post types.Post) (*types.Post, error) {
+	args := m.Called(post)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.Post), args.Error(1)
+}
+
+func (m *MockPostRepository) Patch(id string, post types.Post) (*types.Post, error) {
+	args := m.Called(id, post)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.Post), args.Error(1)
+}
+
+func (m *MockPostRepository) UpdatePostCategories(postId string, categoryIds []string) error {
+	args := m.Called(postId, categoryIds)
+	return args.Error(0)
+}
+
+func (m *MockPostRepository) UpdatePostTags(postId string, tags []types.Tag) ([]types.Tag, error) {
+	args := m.Called(postId, tags)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.Tag), args.Error(1)
+}
+
+func (m *MockPostRepository) FindPostTranslation(postId string, locale string) (*types.PostTranslation, error) {
+	args := m.Called(postId, locale)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.PostTranslation), args.Error(1)
+}
+
+func (m *MockPostRepository) SavePostTranslation(postId string, translation types.PostTranslation) (*types.PostTranslation, error) {
+	args := m.Called(postId, translation)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.PostTranslation), args.Error(1)
+}
+
+func TestRelatedPostService_CachesUntilInvalidated(t *testing.T) {
+	mockRepo := new(MockPostRepository)
+	relatedPostService := service.NewRelatedPostService(mockRepo)
+
+	mockRepo.On("FindRelated", "1", 5).Return([]types.Post{{Id: "2", Content: "Content"}}, nil).Twice()
+
+	posts, err := relatedPostService.FindRelated("1", 5)
+	assert.NoError(t, err)
+	assert.Len(t, posts, 1)
+
+	// Changing the returned posts doesn't change the cached ones
+	posts[0].Content = ""
+
+	posts, err = relatedPostService.FindRelated("1", 5)
+	assert.NoError(t, err)
+	assert.Equal(t, "Content", posts[0].Content)
+	mockRepo.AssertNumberOfCalls(t, "FindRelated", 1)
+
+	relatedPostService.Invalidate()
+
+	_, err = relatedPostService.FindRelated("1", 5)
+	assert.NoError(t, err)
+	mockRepo.AssertNumberOfCalls(t, "FindRelated", 2)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRelatedPostService_DoesNotCacheErrors(t *testing.T) {
+	mockRepo := new(MockPostRepository)
+	relatedPostService := service.NewRelatedPostService(mockRepo)
+
+	mockRepo.On("FindRelated", "1", 5).Return(nil, assert.AnError).Once()
+	mockRepo.On("FindRelated", "1", 5).Return([]types.Post{}, nil).Once()
+
+	_, err := relatedPostService.FindRelated("1", 5)
+	assert.Error(t, err)
+
+	posts, err := relatedPostService.FindRelated("1", 5)
+	assert.NoError(t, err)
+	assert.Empty(t, posts)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,67 @@
+package service_test
+
+import (
+	"go-blog/internal/service"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlugService_Generate(t *testing.T) {
+	slugService := service.NewSlugService()
+
+	tests := []struct {
+		name     string
+		text     string
+		language string
+		expected string
+	}{
+		{"Plain", "Hello, World!", "", "hello-world"},
+		{"Turkish", "Güzel Şehir İstanbul", "", "guzel-sehir-istanbul"},
+		{"Turkish dotless i", "Işık ılık", "", "isik-ilik"},
+		{"German", "Schöne Grüße aus Köln", "", "schoene-gruesse-aus-koeln"},
+		{"German by language", "Über uns", "de", "ueber-uns"},
+		{"Diacritics", "Crème brûlée à la française", "", "creme-brulee-a-la-francaise"},
+		{"Cyrillic", "Привет, мир", "", "privet-mir"},
+		{"Reserved", "Admin", "", "admin-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, slugService.Generate(tt.text, tt.language))
+		})
+	}
+}
+
+func TestSlugService_GenerateWithoutLatinLetters(t *testing.T) {
+	slugService := service.NewSlugService()
+
+	slug := slugService.Generate("你好世界", "")
+
+	assert.Regexp(t, "^n-[0-9a-f]{8}$", slug)
+	assert.Equal(t, slug, slugService.Generate("你好世界", ""))
+	assert.NotEqual(t, slug, slugService.Generate("こんにちは", ""))
+}
+
+func TestSlugService_GenerateTruncatesOnWordBoundary(t *testing.T) {
+	slugService := service.NewSlugService()
+
+	slug := slugService.Generate(strings.Repeat("lorem ipsum ", 20), "")
+
+	assert.LessOrEqual(t, len(slug), service.MaxSlugLength)
+	assert.False(t, strings.HasSuffix(slug, "-"))
+	assert.True(t, strings.HasSuffix(slug, "lorem") || strings.HasSuffix(slug, "ipsum"))
+}
+
+func TestSlugService_ValidateCustom(t *testing.T) {
+	slugService := service.NewSlugService()
+
+	assert.NoError(t, slugService.ValidateCustom("my-custom-slug-2"))
+	assert.Error(t, slugService.ValidateCustom("Upper-Case"))
+	assert.Error(t, slugService.ValidateCustom("double--hyphen"))
+	assert.Error(t, slugService.ValidateCustom("-leading"))
+	assert.Error(t, slugService.ValidateCustom("güzel"))
+	assert.Error(t, slugService.ValidateCustom("admin"))
+	assert.Error(t, slugService.ValidateCustom(strings.Repeat("a", service.MaxSlugLength+1)))
+}
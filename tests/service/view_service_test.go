@@ -0,0 +1,88 @@
+package service_test
+
+import (
+	"go-blog/internal/service"
+	"go-blog/internal/types"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockViewRepository struct {
+	mock.Mock
+}
+
+func (m *MockViewRepository) SaveViews(views []types.PostView) error {
+	args := m.Called(views)
+	return args.Error(0)
+}
+
+func (m *MockViewRepository) FindPostStats(postId string, from, to time.Time) (*types.PostStats, error) {
+	args := m.Called(postId, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.PostStats), args.Error(1)
+}
+
+func (m *MockViewRepository) FindTopPosts(from, to time.Time, limit int) ([]types.TopPost, error) {
+	args := m.Called(from, to, limit)
+	return args.Get(0).([]types.TopPost), args.Error(1)
+}
+
+func TestViewService_StopFlushesBufferedViews(t *testing.T) {
+	mockRepo := new(MockViewRepository)
+	viewService := service.NewViewService(mockRepo)
+
+	var saved []types.PostView
+	mockRepo.On("SaveViews", mock.Anything).Run(func(args mock.Arguments) {
+		saved = append(saved, args.Get(0).([]types.PostView)...)
+	}).Return(nil)
+
+	viewService.Record("1", "127.0.0.1", "Mozilla/5.0", "https://www.Example.com/some/page")
+	viewService.Record("1", "127.0.0.1", "Mozilla/5.0", "")
+	viewService.Record("1", "127.0.0.2", "Googlebot/2.1", "")
+
+	assert.NoError(t, viewService.Stop())
+
+	assert.Len(t, saved, 2)
+	assert.Equal(t, "example.com", saved[0].ReferrerDomain)
+	assert.Equal(t, "", saved[1].ReferrerDomain)
+	assert.Equal(t, saved[0].VisitorHash, saved[1].VisitorHash)
+	assert.NotContains(t, saved[0].VisitorHash, "127.0.0.1")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestViewService_DifferentVisitorsGetDifferentHashes(t *testing.T) {
+	mockRepo := new(MockViewRepository)
+	viewService := service.NewViewService(mockRepo)
+
+	var saved []types.PostView
+	mockRepo.On("SaveViews", mock.Anything).Run(func(args mock.Arguments) {
+		saved = append(saved, args.Get(0).([]types.PostView)...)
+	}).Return(nil)
+
+	viewService.Record("1", "127.0.0.1", "Mozilla/5.0", "")
+	viewService.Record("1", "127.0.0.2", "Mozilla/5.0", "")
+	viewService.Record("2", "127.0.0.1", "Mozilla/5.0", "")
+
+	assert.NoError(t, viewService.Stop())
+
+	assert.Len(t, saved, 3)
+	assert.NotEqual(t, saved[0].VisitorHash, saved[1].VisitorHash)
+	assert.NotEqual(t, saved[0].VisitorHash, saved[2].VisitorHash)
+}
+
+func TestViewService_RecordAfterStopIsIgnored(t *testing.T) {
+	mockRepo := new(MockViewRepository)
+	viewService := service.NewViewService(mockRepo)
+
+	assert.NoError(t, viewService.Stop())
+
+	viewService.Record("1", "127.0.0.1", "Mozilla/5.0", "")
+	assert.NoError(t, viewService.Stop())
+
+	mockRepo.AssertNotCalled(t, "SaveViews", mock.Anything)
+}
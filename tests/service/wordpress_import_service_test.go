@@ -0,0 +1,350 @@
+package service_test
+
+import (
+	"database/sql"
+	"go-blog/internal/repository"
+	"go-blog/internal/service"
+	"go-blog/internal/types"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockWordPressImportRepository struct {
+	mock.Mock
+}
+
+func (m *MockWordPressImportRepository) FindImported(site string, kind string, wpId int64) (string, error) {
+	args := m.Called(site, kind, wpId)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockWordPressImportRepository) RecordImport(site string, kind string, wpId int64, localId string) error {
+	args := m.Called(site, kind, wpId, localId)
+	return args.Error(0)
+}
+
+// MockCategoryRepository only implements the methods the imports use, the others panic.
+type MockCategoryRepository struct {
+	repository.CategoryRepository
+	mock.Mock
+}
+
+func (m *MockCategoryRepository) FindAll() ([]types.Category, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]types.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) FindById(id string) (*types.Category, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) Create(category types.Category) (*types.Category, error) {
+	args := m.Called(category)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.Category), args.Error(1)
+}
+
+// MockFileRepository only implements the methods the import uses, the others panic.
+type MockFileRepository struct {
+	repository.FileRepository
+	mock.Mock
+}
+
+func (m *MockFileRepository) FindById(id string) (*types.File, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.File), args.Error(1)
+}
+
+func (m *MockFileRepository) Create(file types.File) (*types.File, error) {
+	args := m.Called(file)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*types.File), args.Error(1)
+}
+
+const testWXR = `<?xml version="1.0" encoding="UTF-8" ?>
+<rss version="2.0"
+	xmlns:excerpt="http://wordpress.org/export/1.2/excerpt/"
+	xmlns:content="http://purl.org/rss/1.0/modules/content/"
+	xmlns:dc="http://purl.org/dc/elements/1.1/"
+	xmlns:wp="http://wordpress.org/export/1.2/">
+<channel>
+	<title>My Blog</title>
+	<link>http://example.com</link>
+	<wp:base_site_url>http://example.com</wp:base_site_url>
+	<wp:base_blog_url>https://example.com/</wp:base_blog_url>
+	<wp:author>
+		<wp:author_id>2</wp:author_id>
+		<wp:author_login><![CDATA[jdoe]]></wp:author_login>
+		<wp:author_email><![CDATA[jane@example.com]]></wp:author_email>
+		<wp:author_display_name><![CDATA[Jane D]]></wp:author_display_name>
+		<wp:author_first_name><![CDATA[Jane]]></wp:author_first_name>
+		<wp:author_last_name><![CDATA[Doe]]></wp:author_last_name>
+	</wp:author>
+	<wp:category>
+		<wp:term_id>4</wp:term_id>
+		<wp:category_nicename><![CDATA[local-news]]></wp:category_nicename>
+		<wp:category_parent><![CDATA[news]]></wp:category_parent>
+		<wp:cat_name><![CDATA[Local News]]></wp:cat_name>
+	</wp:category>
+	<wp:category>
+		<wp:term_id>3</wp:term_id>
+		<wp:category_nicename><![CDATA[news]]></wp:category_nicename>
+		<wp:category_parent><![CDATA[]]></wp:category_parent>
+		<wp:cat_name><![CDATA[News]]></wp:cat_name>
+	</wp:category>
+	<item>
+		<title>Hello &amp; welcome</title>
+		<dc:creator><![CDATA[jdoe]]></dc:creator>
+		<content:encoded><![CDATA[<!-- wp:paragraph --><p>Intro with <strong>bold</strong>.</p><!-- /wp:paragraph -->
+<img src="http://example.com/wp-content/uploads/2020/01/photo-300x200.png" alt="Photo" />]]></content:encoded>
+		<excerpt:encoded><![CDATA[A <em>short</em> intro]]></excerpt:encoded>
+		<wp:post_id>11</wp:post_id>
+		<wp:post_date><![CDATA[2020-01-02 04:04:05]]></wp:post_date>
+		<wp:post_date_gmt><![CDATA[2020-01-02 03:04:05]]></wp:post_date_gmt>
+		<wp:post_name><![CDATA[hello-welcome]]></wp:post_name>
+		<wp:status><![CDATA[publish]]></wp:status>
+		<wp:post_type><![CDATA[post]]></wp:post_type>
+		<category domain="category" nicename="local-news"><![CDATA[Local News]]></category>
+		<category domain="post_tag" nicename="go"><![CDATA[Go]]></category>
+		<category domain="post_tag" nicename="fiber"><![CDATA[Fiber]]></category>
+		<wp:postmeta>
+			<wp:meta_key><![CDATA[_thumbnail_id]]></wp:meta_key>
+			<wp:meta_value><![CDATA[10]]></wp:meta_value>
+		</wp:postmeta>
+	</item>
+	<item>
+		<title>photo</title>
+		<dc:creator><![CDATA[jdoe]]></dc:creator>
+		<wp:post_id>10</wp:post_id>
+		<wp:status><![CDATA[inherit]]></wp:status>
+		<wp:post_type><![CDATA[attachment]]></wp:post_type>
+		<wp:attachment_url><![CDATA[http://example.com/wp-content/uploads/2020/01/photo.png]]></wp:attachment_url>
+		<wp:postmeta>
+			<wp:meta_key><![CDATA[_wp_attached_file]]></wp:meta_key>
+			<wp:meta_value><![CDATA[2020/01/photo.png]]></wp:meta_value>
+		</wp:postmeta>
+	</item>
+	<item>
+		<title>Trashed</title>
+		<dc:creator><![CDATA[jdoe]]></dc:creator>
+		<wp:post_id>12</wp:post_id>
+		<wp:status><![CDATA[trash]]></wp:status>
+		<wp:post_type><![CDATA[post]]></wp:post_type>
+	</item>
+	<item>
+		<title>About</title>
+		<dc:creator><![CDATA[jdoe]]></dc:creator>
+		<wp:post_id>13</wp:post_id>
+		<wp:status><![CDATA[publish]]></wp:status>
+		<wp:post_type><![CDATA[page]]></wp:post_type>
+	</item>
+	<item>
+		<title>By a stranger</title>
+		<dc:creator><![CDATA[ghost]]></dc:creator>
+		<content:encoded><![CDATA[Some content]]></content:encoded>
+		<wp:post_id>14</wp:post_id>
+		<wp:status><![CDATA[draft]]></wp:status>
+		<wp:post_type><![CDATA[post]]></wp:post_type>
+	</item>
+</channel>
+</rss>`
+
+type wordPressImportMocks struct {
+	imports    *MockWordPressImportRepository
+	users      *MockUserRepository
+	categories *MockCategoryRepository
+	posts      *MockPostRepository
+	files      *MockFileRepository
+}
+
+func newWordPressImportService() (service.WordPressImportService, wordPressImportMocks) {
+	mocks := wordPressImportMocks{
+		imports:    new(MockWordPressImportRepository),
+		users:      new(MockUserRepository),
+		categories: new(MockCategoryRepository),
+		posts:      new(MockPostRepository),
+		files:      new(MockFileRepository),
+	}
+
+	importService := service.NewWordPressImportService(mocks.imports, mocks.users, mocks.categories, mocks.posts, mocks.files, service.NewFileService(), service.NewSlugService())
+	return importService, mocks
+}
+
+// writeTestUploads writes the uploads directory of the test export, with its one image.
+func writeTestUploads(t *testing.T) string {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "2020", "01"), 0755))
+
+	file, err := os.Create(filepath.Join(dir, "2020", "01", "photo.png"))
+	require.NoError(t, err)
+	defer file.Close()
+	require.NoError(t, png.Encode(file, image.NewRGBA(image.Rect(0, 0, 3, 2))))
+
+	return dir
+}
+
+func TestWordPressImportService_Import(t *testing.T) {
+	importService, mocks := newWordPressImportService()
+	uploadsDir := writeTestUploads(t)
+
+	author := &types.User{Id: "u1", Name: "Jane", Lastname: "Doe", Email: "jane@example.com"}
+	mocks.imports.On("FindImported", "example.com", mock.Anything, mock.Anything).Return("", nil)
+	mocks.imports.On("RecordImport", "example.com", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	mocks.users.On("FindByEmail", "jane@example.com").Return(nil, sql.ErrNoRows)
+	mocks.users.On("Create", mock.MatchedBy(func(user types.User) bool {
+		return user.Name == "Jane" && user.Lastname == "Doe" && user.Email == "jane@example.com" && len(user.Password) >= 32
+	})).Return(author, nil)
+
+	// The parent is created first, although it comes second in the export
+	parentId := "8a6e0804-2bd0-4672-b79d-d97027f9071a"
+	mocks.categories.On("Create", types.Category{Title: "News", Slug: "news"}).
+		Return(&types.Category{Id: parentId, Title: "News", Slug: "news"}, nil).Once()
+	mocks.categories.On("Create", types.Category{Title: "Local News", Slug: "local-news", ParentId: &parentId}).
+		Return(&types.Category{Id: "c2", Title: "Local News", Slug: "local-news", ParentId: &parentId}, nil).Once()
+
+	mocks.files.On("Create", mock.MatchedBy(func(file types.File) bool {
+		return file.UserId == "u1" && strings.HasPrefix(file.Filename, "photo_") && file.ContentType == "image/png" && file.Width == 3 && file.Height == 2
+	})).Return(&types.File{Id: "f1", UserId: "u1", Filename: "photo.png", Url: "/uploads/u1/photo.png", Width: 3, Height: 2}, nil)
+
+	var createdPost types.Post
+	mocks.posts.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+		createdPost = args.Get(0).(types.Post)
+	}).Return(&types.Post{Id: "p1"}, nil).Once()
+	mocks.posts.On("UpdatePostCategories", "p1", []string{"c2"}).Return(nil)
+	mocks.posts.On("UpdatePostTags", "p1", []types.Tag{{Name: "Go", Slug: "go"}, {Name: "Fiber", Slug: "fiber"}}).Return([]types.Tag{}, nil)
+
+	report, err := importService.Import(strings.NewReader(testWXR), uploadsDir, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, types.ImportCount{Created: 1}, report.Users)
+	assert.Equal(t, types.ImportCount{Created: 2}, report.Categories)
+	assert.Equal(t, types.ImportCount{Created: 1}, report.Attachments)
+	assert.Equal(t, types.ImportCount{Created: 1}, report.Posts)
+	assert.Equal(t, 2, report.Tags)
+	assert.Equal(t, 2, report.Skipped)
+	assert.Equal(t, []string{"post 14: unknown author ghost"}, report.Errors)
+
+	assert.Equal(t, "Hello & welcome", createdPost.Title)
+	assert.Equal(t, "hello-welcome", createdPost.Slug)
+	assert.Equal(t, types.PostStatusPublished, createdPost.Status)
+	assert.Equal(t, "Intro with **bold**.\n\n![Photo](/uploads/u1/photo.png)", createdPost.Content)
+	assert.Equal(t, "A short intro", createdPost.Excerpt)
+	assert.Equal(t, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), createdPost.CreatedAt)
+	assert.Equal(t, "u1", createdPost.Author.Id)
+	assert.Equal(t, &types.CoverImage{Id: "f1"}, createdPost.CoverImage)
+
+	mocks.imports.AssertCalled(t, "RecordImport", "example.com", types.WordPressUser, int64(2), "u1")
+	mocks.imports.AssertCalled(t, "RecordImport", "example.com", types.WordPressCategory, int64(3), parentId)
+	mocks.imports.AssertCalled(t, "RecordImport", "example.com", types.WordPressCategory, int64(4), "c2")
+	mocks.imports.AssertCalled(t, "RecordImport", "example.com", types.WordPressAttachment, int64(10), "f1")
+	mocks.imports.AssertCalled(t, "RecordImport", "example.com", types.WordPressPost, int64(11), "p1")
+	mocks.posts.AssertExpectations(t)
+	mocks.categories.AssertExpectations(t)
+}
+
+func TestWordPressImportService_ImportAgain(t *testing.T) {
+	importService, mocks := newWordPressImportService()
+
+	mocks.imports.On("FindImported", "example.com", types.WordPressUser, int64(2)).Return("u1", nil)
+	mocks.imports.On("FindImported", "example.com", types.WordPressCategory, int64(3)).Return("c1", nil)
+	mocks.imports.On("FindImported", "example.com", types.WordPressCategory, int64(4)).Return("c2", nil)
+	mocks.imports.On("FindImported", "example.com", types.WordPressAttachment, int64(10)).Return("f1", nil)
+	mocks.imports.On("FindImported", "example.com", types.WordPressPost, int64(11)).Return("p1", nil)
+	mocks.imports.On("FindImported", "example.com", types.WordPressPost, int64(14)).Return("", nil)
+
+	mocks.users.On("FindById", "u1").Return(&types.User{Id: "u1", Name: "Jane"}, nil)
+	mocks.categories.On("FindById", "c1").Return(&types.Category{Id: "c1"}, nil)
+	mocks.categories.On("FindById", "c2").Return(&types.Category{Id: "c2"}, nil)
+	mocks.files.On("FindById", "f1").Return(&types.File{Id: "f1", Url: "/uploads/u1/photo.png"}, nil)
+
+	// Posts without a known author are credited to the fallback author
+	fallbackAuthor := &types.User{Id: "admin"}
+	mocks.posts.On("Create", mock.MatchedBy(func(post types.Post) bool {
+		return post.Title == "By a stranger" && post.Author.Id == "admin" && post.Status == types.PostStatusDraft
+	})).Return(&types.Post{Id: "p2"}, nil).Once()
+	mocks.imports.On("RecordImport", "example.com", types.WordPressPost, int64(14), "p2").Return(nil)
+
+	// The attachments directory isn't even looked at, nothing new needs it
+	report, err := importService.Import(strings.NewReader(testWXR), t.TempDir(), fallbackAuthor)
+
+	require.NoError(t, err)
+	assert.Equal(t, types.ImportCount{Existing: 1}, report.Users)
+	assert.Equal(t, types.ImportCount{Existing: 2}, report.Categories)
+	assert.Equal(t, types.ImportCount{Existing: 1}, report.Attachments)
+	assert.Equal(t, types.ImportCount{Created: 1, Existing: 1}, report.Posts)
+	assert.Empty(t, report.Errors)
+	mocks.users.AssertNotCalled(t, "Create", mock.Anything)
+	mocks.posts.AssertExpectations(t)
+	mocks.imports.AssertExpectations(t)
+}
+
+func TestWordPressImportService_ImportInvalidExport(t *testing.T) {
+	importService, _ := newWordPressImportService()
+
+	_, err := importService.Import(strings.NewReader("not xml"), t.TempDir(), nil)
+
+	assert.ErrorIs(t, err, service.ErrInvalidExport)
+}
+
+func TestWordPressImportService_ImportOtherSite(t *testing.T) {
+	importService, mocks := newWordPressImportService()
+	uploadsDir := writeTestUploads(t)
+
+	// The first site was imported already, the second numbers its objects the same way
+	otherWXR := strings.NewReplacer("http://example.com", "http://other.example.org", "https://example.com/", "https://other.example.org/").Replace(testWXR)
+	mocks.imports.On("FindImported", "example.com", mock.Anything, mock.Anything).Return("imported", nil)
+	mocks.imports.On("FindImported", "other.example.org", mock.Anything, mock.Anything).Return("", nil)
+	mocks.imports.On("RecordImport", "other.example.org", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	// The same author writing on both sites keeps their account
+	mocks.users.On("FindByEmail", "jane@example.com").Return(&types.User{Id: "u1", Name: "Jane"}, nil)
+
+	parentId := "8a6e0804-2bd0-4672-b79d-d97027f9071a"
+	mocks.categories.On("Create", types.Category{Title: "News", Slug: "news"}).
+		Return(&types.Category{Id: parentId, Title: "News", Slug: "news-1"}, nil).Once()
+	mocks.categories.On("Create", types.Category{Title: "Local News", Slug: "local-news", ParentId: &parentId}).
+		Return(&types.Category{Id: "c4", Title: "Local News", Slug: "local-news-1", ParentId: &parentId}, nil).Once()
+	mocks.files.On("Create", mock.Anything).Return(&types.File{Id: "f2", UserId: "u1", Url: "/uploads/u1/photo.png"}, nil).Once()
+	mocks.posts.On("Create", mock.MatchedBy(func(post types.Post) bool {
+		return post.Title == "Hello & welcome" && post.Author.Id == "u1"
+	})).Return(&types.Post{Id: "p3"}, nil).Once()
+	mocks.posts.On("UpdatePostCategories", "p3", []string{"c4"}).Return(nil)
+	mocks.posts.On("UpdatePostTags", "p3", mock.Anything).Return([]types.Tag{}, nil)
+
+	report, err := importService.Import(strings.NewReader(otherWXR), uploadsDir, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, types.ImportCount{Existing: 1}, report.Users)
+	assert.Equal(t, types.ImportCount{Created: 2}, report.Categories)
+	assert.Equal(t, types.ImportCount{Created: 1}, report.Attachments)
+	assert.Equal(t, types.ImportCount{Created: 1}, report.Posts)
+	mocks.imports.AssertNotCalled(t, "FindImported", "example.com", mock.Anything, mock.Anything)
+	mocks.imports.AssertCalled(t, "RecordImport", "other.example.org", types.WordPressPost, int64(11), "p3")
+	mocks.users.AssertNotCalled(t, "FindById", mock.Anything)
+	mocks.categories.AssertExpectations(t)
+	mocks.posts.AssertExpectations(t)
+}
@@ -0,0 +1,119 @@
+package types_test
+
+import (
+	"go-blog/internal/types"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPost_ResolvedSeo(t *testing.T) {
+	longTitle := strings.TrimSpace(strings.Repeat("Title ", 20))
+	longExcerpt := strings.TrimSpace(strings.Repeat("excerpt ", 30))
+	given := types.PostSeo{MetaTitle: "Given title", MetaDescription: "Given description", CanonicalUrl: "https://example.org/given", OgImage: "https://example.org/given.png"}
+
+	tests := []struct {
+		name      string
+		clientUrl string
+		post      types.Post
+		expected  types.PostSeo
+	}{
+		{
+			"Falls back to the post",
+			"https://blog.example.com/",
+			types.Post{Title: "Hello", Slug: "hello", Excerpt: "An excerpt", CoverImage: &types.CoverImage{Id: "f1", Url: "https://blog.example.com/cover.png"}},
+			types.PostSeo{MetaTitle: "Hello", MetaDescription: "An excerpt", CanonicalUrl: "https://blog.example.com/posts/hello", OgImage: "https://blog.example.com/cover.png"},
+		},
+		{
+			"Given fields are kept",
+			"https://blog.example.com",
+			types.Post{Title: "Hello", Slug: "hello", Excerpt: "An excerpt", Seo: given, CoverImage: &types.CoverImage{Id: "f1", Url: "https://blog.example.com/cover.png"}},
+			given,
+		},
+		{
+			"No canonical URL without a client URL",
+			"",
+			types.Post{Title: "Hello", Slug: "hello"},
+			types.PostSeo{MetaTitle: "Hello"},
+		},
+		{
+			"No canonical URL without a slug",
+			"https://blog.example.com",
+			types.Post{Title: "Hello"},
+			types.PostSeo{MetaTitle: "Hello"},
+		},
+		{
+			"Noindex is kept",
+			"",
+			types.Post{Title: "Hello", Seo: types.PostSeo{Noindex: true}},
+			types.PostSeo{MetaTitle: "Hello", Noindex: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CLIENT_URL", tt.clientUrl)
+
+			assert.Equal(t, tt.expected, tt.post.ResolvedSeo())
+		})
+	}
+
+	t.Run("Long fallbacks are truncated", func(t *testing.T) {
+		t.Setenv("CLIENT_URL", "")
+		seo := types.Post{Title: longTitle, Excerpt: longExcerpt}.ResolvedSeo()
+
+		assert.LessOrEqual(t, len([]rune(seo.MetaTitle)), types.MetaTitleLength)
+		assert.True(t, strings.HasSuffix(seo.MetaTitle, "Title…"))
+		assert.LessOrEqual(t, len([]rune(seo.MetaDescription)), types.MetaDescriptionLength)
+		assert.True(t, strings.HasSuffix(seo.MetaDescription, "excerpt…"))
+	})
+}
+
+func TestPost_BlogPosting(t *testing.T) {
+	t.Setenv("CLIENT_URL", "https://blog.example.com")
+	publishedAt := time.Date(2026, 10, 1, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		post     types.Post
+		expected types.BlogPosting
+	}{
+		{
+			"Full post",
+			types.Post{
+				Title: "Hello", Slug: "hello", Excerpt: "An excerpt", WordCount: 420, CreatedAt: publishedAt,
+				Author:     types.User{Name: "Jane", Lastname: "Doe"},
+				CoverImage: &types.CoverImage{Id: "f1", Url: "https://blog.example.com/cover.png"},
+				Categories: []types.Category{{Title: "Go"}, {Title: "Web"}},
+				Tags:       []types.Tag{{Name: "fiber"}, {Name: "http"}},
+			},
+			types.BlogPosting{
+				Context: "https://schema.org", Type: "BlogPosting", Headline: "Hello", Description: "An excerpt",
+				Url: "https://blog.example.com/posts/hello", MainEntityOfPage: "https://blog.example.com/posts/hello",
+				Image: "https://blog.example.com/cover.png", DatePublished: "2026-10-01T09:30:00Z", WordCount: 420,
+				Author: types.JsonLdPerson{Type: "Person", Name: "Jane Doe"}, ArticleSection: []string{"Go", "Web"}, Keywords: "fiber, http",
+			},
+		},
+		{
+			"Given SEO fields win",
+			types.Post{
+				Title: "Hello", Slug: "hello", Excerpt: "An excerpt", CreatedAt: publishedAt,
+				Author: types.User{Name: "Jane"},
+				Seo:    types.PostSeo{MetaDescription: "Given description", CanonicalUrl: "https://example.org/hello"},
+			},
+			types.BlogPosting{
+				Context: "https://schema.org", Type: "BlogPosting", Headline: "Hello", Description: "Given description",
+				Url: "https://example.org/hello", MainEntityOfPage: "https://example.org/hello", DatePublished: "2026-10-01T09:30:00Z",
+				Author: types.JsonLdPerson{Type: "Person", Name: "Jane"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.post.BlogPosting())
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package types_test
+
+import (
+	"go-blog/internal/types"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStripMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		expected string
+	}{
+		{"Plain", "Just text", "Just text"},
+		{"Emphasis", "Some **bold**, _italic_ and ~~struck~~ `code`", "Some bold, italic and struck code"},
+		{"Headings", "# Title\n\n## Subtitle\nText", "Title\n\nSubtitle\nText"},
+		{"Links keep their text", "Read [the docs](https://example.com) first", "Read the docs first"},
+		{"Images are dropped", "Look ![a cat](cat.png) here", "Look  here"},
+		{"Code blocks are dropped", "Before\n```go\nfmt.Println(\"hi\")\n```\nAfter", "Before\n\nAfter"},
+		{"Quotes and lists", "> Quoted\n- One\n* Two\n1. Three", "Quoted\nOne\nTwo\nThree"},
+		{"Rules", "Above\n\n---\n\nBelow", "Above\n\nBelow"},
+		{"Windows line endings", "# Title\r\nText", "Title\nText"},
+		{"Nothing but markup", "```\ncode\n```", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, types.StripMarkdown(tt.markdown))
+		})
+	}
+}
+
+func TestPost_SummarizeExcerpt(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		excerpt       string
+		expected      string
+		wantGenerated bool
+	}{
+		{"First paragraph", "First *paragraph*.\n\nSecond paragraph.", "", "First paragraph.", true},
+		{"Headings are skipped", "# Title\n\n## Intro\n\nThe [first](/a) paragraph.", "", "The first paragraph.", true},
+		{"Headings only", "# Title\n\n## Subtitle", "", "Title Subtitle", true},
+		{"Lines are joined", "One\ntwo\n  three", "", "One two three", true},
+		{"Given excerpts are kept", "First paragraph.", "  Given excerpt ", "Given excerpt", false},
+		{"No content", "", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			post := types.Post{Content: tt.content, Excerpt: tt.excerpt}
+			post.Summarize()
+
+			assert.Equal(t, tt.expected, post.Excerpt)
+			assert.Equal(t, tt.wantGenerated, post.ExcerptGenerated)
+		})
+	}
+}
+
+func TestPost_SummarizeTruncatesAtWords(t *testing.T) {
+	tests := []struct {
+		name      string
+		paragraph string
+	}{
+		{"Short words", strings.Repeat("word ", 60)},
+		{"Long words", strings.Repeat("extraordinarily ", 20)},
+		{"Punctuation", strings.Repeat("one, two; three. ", 20)},
+		{"Multibyte", strings.Repeat("çğıöşü ", 40)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paragraph := strings.TrimSpace(tt.paragraph)
+			post := types.Post{Content: paragraph}
+			post.Summarize()
+
+			assert.LessOrEqual(t, utf8.RuneCountInString(post.Excerpt), types.ExcerptLength)
+			assert.True(t, strings.HasSuffix(post.Excerpt, "…"))
+
+			// The excerpt ends with a whole word of the paragraph, without its punctuation
+			text := strings.TrimSuffix(post.Excerpt, "…")
+			assert.True(t, strings.HasPrefix(paragraph, text))
+			rest := strings.TrimLeft(paragraph[len(text):], ",.;:")
+			assert.True(t, strings.HasPrefix(rest, " "), "cut inside a word: %q", post.Excerpt)
+		})
+	}
+
+	t.Run("Short enough", func(t *testing.T) {
+		paragraph := strings.TrimSpace(strings.Repeat("word ", 40))
+		post := types.Post{Content: paragraph}
+		post.Summarize()
+
+		assert.Equal(t, paragraph, post.Excerpt)
+	})
+}
+
+func TestPost_SummarizeReadingTime(t *testing.T) {
+	tests := []struct {
+		name          string
+		words         int
+		expectedWords int
+		expected      int
+	}{
+		{"Empty", 0, 0, 0},
+		{"A word", 1, 1, 1},
+		{"A minute", types.WordsPerMinute, types.WordsPerMinute, 1},
+		{"Rounded up", types.WordsPerMinute + 1, types.WordsPerMinute + 1, 2},
+		{"Two minutes", 2 * types.WordsPerMinute, 2 * types.WordsPerMinute, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			post := types.Post{Content: strings.Repeat("word ", tt.words)}
+			post.Summarize()
+
+			assert.Equal(t, tt.expectedWords, post.WordCount)
+			assert.Equal(t, tt.expected, post.ReadingTimeMinutes)
+		})
+	}
+
+	t.Run("Markup isn't counted", func(t *testing.T) {
+		post := types.Post{Content: "# Title\n\n![cover](cover.png)\n\n```\nlots of code here\n```\n\nTwo **words**"}
+		post.Summarize()
+
+		assert.Equal(t, 3, post.WordCount)
+	})
+}